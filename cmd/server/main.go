@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/signal"
 
@@ -44,10 +45,25 @@ func run(
 	}
 
 	// ロガーの初期化 - 出力先を注入可能にすることでテスト時はログを制御可能
-	log := logger.New(stdout, cfg.LogLevel)
+	// 標準出力への書き込みに失敗した場合は標準エラー出力へフォールバック
+	log := logger.New(stdout, cfg.LogLevel,
+		logger.WithFallback(stderr),
+		logger.WithSource(cfg.LogSource),
+	)
 
-	// ストレージの初期化 - インメモリストアを利用（本番環境では他の実装に差し替え可能）
-	blogstore := store.NewMemoryBlogStore()
+	// ストレージの初期化 - STORE_BACKENDに応じて実装を選択
+	blogstore, err := newBlogStore(cfg)
+	if err != nil {
+		return fmt.Errorf("create store: %w", err)
+	}
+	baseStore := blogstore
+	// ENCRYPTION_KEY指定時は本文（と作者）を暗号化して保存する
+	if cfg.EncryptionKey != nil {
+		blogstore, err = store.NewEncryptedBlogStore(blogstore, cfg.EncryptionKey, cfg.EncryptAuthor)
+		if err != nil {
+			return fmt.Errorf("create encrypted store: %w", err)
+		}
+	}
 
 	// サーバーの初期化 - 必要なコンポーネントを注入
 	server, err := api.NewServer(
@@ -58,6 +74,53 @@ func run(
 	if err != nil {
 		return fmt.Errorf("create server: %w", err)
 	}
+	// ファイルストアなど後片付けが必要なストアは、リクエストの処理が終わってから閉じる
+	// 暗号化ラッパーはCloseを持たないため、ラップ前のストアで判定する
+	if closer, ok := baseStore.(io.Closer); ok {
+		server.RegisterShutdownHook("close store", func(ctx context.Context) error {
+			return closer.Close()
+		})
+	}
 
 	return server.Start(ctx)
 }
+
+// newBlogStore selects the storage implementation from the configuration
+// バックエンドの選択を一箇所に集約し、環境変数のチェックが散らばらないようにする
+func newBlogStore(cfg *config.Config) (store.BlogStore, error) {
+	switch cfg.StoreBackend {
+	case "memory":
+		s := store.NewMemoryBlogStoreWithCapacity(cfg.MemoryStoreCapacity)
+		s.SetUniqueSlugs(cfg.UniqueSlugs)
+		s.SetDeduplicateContent(cfg.DeduplicateContent)
+		return s, nil
+	case "file":
+		if cfg.FileStoreDir == "" {
+			return nil, fmt.Errorf("store backend %q requires FILE_STORE_DIR", cfg.StoreBackend)
+		}
+		s, err := store.NewFileBlogStoreWithInterval(cfg.FileStoreDir, cfg.PersistInterval)
+		if err != nil {
+			return nil, err
+		}
+		s.SetUniqueSlugs(cfg.UniqueSlugs)
+		s.SetDeduplicateContent(cfg.DeduplicateContent)
+		return s, nil
+	case "http":
+		if cfg.RemoteStoreURL == "" {
+			return nil, fmt.Errorf("store backend %q requires REMOTE_STORE_URL", cfg.StoreBackend)
+		}
+		return store.NewHTTPBlogStore(cfg.RemoteStoreURL, &http.Client{Timeout: cfg.RemoteStoreTimeout}), nil
+	case "sqlite":
+		if cfg.SQLitePath == "" {
+			return nil, fmt.Errorf("store backend %q requires SQLITE_PATH", cfg.StoreBackend)
+		}
+		return nil, fmt.Errorf("store backend %q is not supported in this build", cfg.StoreBackend)
+	case "postgres":
+		if cfg.DatabaseURL == "" {
+			return nil, fmt.Errorf("store backend %q requires DATABASE_URL", cfg.StoreBackend)
+		}
+		return nil, fmt.Errorf("store backend %q is not supported in this build", cfg.StoreBackend)
+	default:
+		return nil, fmt.Errorf("unknown store backend: %q", cfg.StoreBackend)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestNewBlogStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name: "default is memory",
+			env:  map[string]string{},
+		},
+		{
+			name: "memory",
+			env:  map[string]string{"STORE_BACKEND": "memory"},
+		},
+		{
+			name:    "file without directory",
+			env:     map[string]string{"STORE_BACKEND": "file"},
+			wantErr: "requires FILE_STORE_DIR",
+		},
+		{
+			name:    "http without url",
+			env:     map[string]string{"STORE_BACKEND": "http"},
+			wantErr: "requires REMOTE_STORE_URL",
+		},
+		{
+			name:    "sqlite without path",
+			env:     map[string]string{"STORE_BACKEND": "sqlite"},
+			wantErr: "requires SQLITE_PATH",
+		},
+		{
+			name:    "sqlite with path",
+			env:     map[string]string{"STORE_BACKEND": "sqlite", "SQLITE_PATH": "/tmp/blog.db"},
+			wantErr: "not supported in this build",
+		},
+		{
+			name:    "postgres without url",
+			env:     map[string]string{"STORE_BACKEND": "postgres"},
+			wantErr: "requires DATABASE_URL",
+		},
+		{
+			name:    "postgres with url",
+			env:     map[string]string{"STORE_BACKEND": "postgres", "DATABASE_URL": "postgres://localhost/blog"},
+			wantErr: "not supported in this build",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				return tt.env[key]
+			}
+
+			cfg, err := config.Load(getenv)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			blogStore, err := newBlogStore(cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if _, ok := blogStore.(*store.MemoryBlogStore); !ok {
+				t.Errorf("expected *store.MemoryBlogStore, got %T", blogStore)
+			}
+		})
+	}
+}
+
+func TestNewBlogStore_Unknown(t *testing.T) {
+	// config.Loadが未知の値を拒否するため、設定を直接組み立ててdefaultの分岐を確認する
+	_, err := newBlogStore(&config.Config{StoreBackend: "mongo"})
+	if err == nil || !strings.Contains(err.Error(), "unknown store backend") {
+		t.Fatalf("expected an unknown store backend error, got %v", err)
+	}
+}
+
+func TestNewBlogStore_File(t *testing.T) {
+	env := map[string]string{"STORE_BACKEND": "file", "FILE_STORE_DIR": t.TempDir()}
+	cfg, err := config.Load(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	blogStore, err := newBlogStore(cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	fileStore, ok := blogStore.(*store.FileBlogStore)
+	if !ok {
+		t.Fatalf("expected *store.FileBlogStore, got %T", blogStore)
+	}
+	fileStore.Close()
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+// authorLimiter throttles how many posts a single author may create per window
+// スパムの連続投稿を抑えるため、作者ごとに直近window内の投稿時刻を保持する
+// スライディングウィンドウ方式で、古い時刻はチェックのたびに取り除く
+// ウィンドウ内の投稿がなくなった作者は、windowごとに全体を走査して取り除く
+type authorLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	posts     map[string][]time.Time
+	lastSweep time.Time
+	now       func() time.Time // テスト時に時刻を制御するため注入可能
+}
+
+// newAuthorLimiter creates a limiter allowing perMinute posts per author
+// perMinuteが0以下の場合はnilを返し、制限を無効化する
+func newAuthorLimiter(perMinute int) *authorLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &authorLimiter{
+		limit:  perMinute,
+		window: time.Minute,
+		posts:  make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// allow records a post for author if it is within the limit
+// 拒否した場合は、次に投稿可能になるまでの時間を返す
+func (l *authorLimiter) allow(author string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-l.window)
+	if now.Sub(l.lastSweep) >= l.window {
+		l.sweepLocked(cutoff)
+		l.lastSweep = now
+	}
+
+	// ウィンドウ外になった投稿時刻を取り除く
+	posts := l.posts[author]
+	i := 0
+	for i < len(posts) && !posts[i].After(cutoff) {
+		i++
+	}
+	posts = posts[i:]
+
+	if len(posts) >= l.limit {
+		l.posts[author] = posts
+		return false, posts[0].Sub(cutoff)
+	}
+
+	l.posts[author] = append(posts, now)
+	return true, 0
+}
+
+// sweepLocked removes authors whose posts have all left the window
+// 一度しか投稿しない作者が多くても、マップが増え続けないようにする
+func (l *authorLimiter) sweepLocked(cutoff time.Time) {
+	for author, posts := range l.posts {
+		if !posts[len(posts)-1].After(cutoff) {
+			delete(l.posts, author)
+		}
+	}
+}
+
+// release gives back the most recent post recorded by allow for author
+// 作成に失敗した投稿を数えないよう、allowが許可した後に作成できなかった場合に呼ぶ
+func (l *authorLimiter) release(author string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	posts := l.posts[author]
+	if len(posts) == 0 {
+		return
+	}
+	if len(posts) == 1 {
+		delete(l.posts, author)
+		return
+	}
+	l.posts[author] = posts[:len(posts)-1]
+}
+
+// errAuthorBudgetExceeded is returned when a write would take an author past MAX_AUTHOR_CONTENT_BYTES
+var errAuthorBudgetExceeded = errors.New("author content budget exceeded")
+
+// checkAuthorBudget reports errAuthorBudgetExceeded if replacing current bytes of
+// the author's content with size bytes would exceed the budget (0 = unlimited)
+// 作成時はcurrentに0を、更新時は変更前の本文の長さを渡す。本文が短くなる更新は常に許可する
+// 確認から保存までの間に同じ作者の書き込みが割り込むと、わずかに上限を超えることがある
+func checkAuthorBudget(ctx context.Context, budget int, blogStore store.BlogStore, author string, current, size int) error {
+	if budget <= 0 || size <= current {
+		return nil
+	}
+	used, err := authorContentBytes(ctx, blogStore, author)
+	if err != nil {
+		return fmt.Errorf("sum author content: %w", err)
+	}
+	if used-current+size > budget {
+		return errAuthorBudgetExceeded
+	}
+	return nil
+}
+
+// authorContentBytes totals the content length of the author's blogs
+// AuthorContentSizerを実装していないストア（暗号化など）では、作者の投稿を取得して合計する
+func authorContentBytes(ctx context.Context, blogStore store.BlogStore, author string) (int, error) {
+	if sizer, ok := blogStore.(store.AuthorContentSizer); ok {
+		return sizer.AuthorContentBytes(ctx, author)
+	}
+	blogs, err := blogStore.GetByAuthor(ctx, author)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, blog := range blogs {
+		total += len(blog.Content)
+	}
+	return total, nil
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestAuthorLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newAuthorLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("alice"); !ok {
+			t.Fatalf("expected post %d to be allowed", i+1)
+		}
+	}
+
+	ok, retryAfter := limiter.allow("alice")
+	if ok {
+		t.Fatal("expected third post within a minute to be throttled")
+	}
+	if retryAfter != time.Minute {
+		t.Errorf("expected retry after %v, got %v", time.Minute, retryAfter)
+	}
+
+	// 別の作者は影響を受けない
+	if ok, _ := limiter.allow("bob"); !ok {
+		t.Error("expected a different author to be allowed")
+	}
+
+	// ウィンドウが過ぎれば再び投稿できる
+	now = now.Add(time.Minute + time.Second)
+	if ok, _ := limiter.allow("alice"); !ok {
+		t.Error("expected post to be allowed after the window passed")
+	}
+}
+
+func TestAuthorLimiter_Prune(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newAuthorLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		limiter.allow(fmt.Sprintf("author-%d", i))
+	}
+
+	// ウィンドウが過ぎた後の最初の投稿で、投稿のなくなった作者が取り除かれる
+	now = now.Add(time.Minute + time.Second)
+	limiter.allow("alice")
+	if len(limiter.posts) != 1 {
+		t.Errorf("expected only the active author to be tracked, got %d", len(limiter.posts))
+	}
+}
+
+func TestAuthorLimiter_Release(t *testing.T) {
+	limiter := newAuthorLimiter(1)
+
+	if ok, _ := limiter.allow("alice"); !ok {
+		t.Fatal("expected first post to be allowed")
+	}
+	limiter.release("alice")
+	if ok, _ := limiter.allow("alice"); !ok {
+		t.Error("expected a released post not to count")
+	}
+	if len(limiter.posts) != 1 {
+		t.Errorf("expected one tracked author, got %d", len(limiter.posts))
+	}
+}
+
+func TestAuthorLimiter_Disabled(t *testing.T) {
+	limiter := newAuthorLimiter(0)
+	if limiter != nil {
+		t.Fatal("expected nil limiter when disabled")
+	}
+
+	for i := 0; i < 100; i++ {
+		if ok, _ := limiter.allow("alice"); !ok {
+			t.Fatal("expected disabled limiter to allow every post")
+		}
+	}
+}
+
+func TestAuthorContentBudget(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{MaxAuthorContentBytes: 100}
+
+	newStore := func() *store.MemoryBlogStore {
+		memStore := store.NewMemoryBlogStore()
+		memStore.Create(context.Background(), &domain.Blog{ID: "existing", Title: "Existing", Content: strings.Repeat("a", 90), Author: "Alice", CreatedAt: time.Now()})
+		return memStore
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "create over the budget is rejected",
+			method:         http.MethodPost,
+			path:           "/api/v1/blogs",
+			body:           `{"title":"Big","content":"` + strings.Repeat("b", 20) + `","author":"Alice"}`,
+			expectedStatus: http.StatusInsufficientStorage,
+		},
+		{
+			name:           "create within the budget succeeds",
+			method:         http.MethodPost,
+			path:           "/api/v1/blogs",
+			body:           `{"title":"Small","content":"` + strings.Repeat("b", 10) + `","author":"Alice"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "other authors have their own budget",
+			method:         http.MethodPost,
+			path:           "/api/v1/blogs",
+			body:           `{"title":"Big","content":"` + strings.Repeat("b", 20) + `","author":"Bob"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "update growing past the budget is rejected",
+			method:         http.MethodPut,
+			path:           "/api/v1/blogs/existing",
+			body:           `{"title":"Existing","content":"` + strings.Repeat("a", 101) + `"}`,
+			expectedStatus: http.StatusInsufficientStorage,
+		},
+		{
+			name:           "update replacing the content within the budget succeeds",
+			method:         http.MethodPut,
+			path:           "/api/v1/blogs/existing",
+			body:           `{"title":"Existing","content":"` + strings.Repeat("a", 100) + `"}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, wrap := range []func(*store.MemoryBlogStore) store.BlogStore{
+		func(s *store.MemoryBlogStore) store.BlogStore { return s },
+		func(s *store.MemoryBlogStore) store.BlogStore { return sliceOnlyStore{s} }, // AuthorContentBytesを持たないストア
+	} {
+		for _, tt := range tests {
+			blogStore := wrap(newStore())
+			t.Run(fmt.Sprintf("%T/%s", blogStore, tt.name), func(t *testing.T) {
+				mux := http.NewServeMux()
+				addRoutes(mux, log, cfg, blogStore, nil, nil, nil)
+
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, req)
+
+				if w.Code != tt.expectedStatus {
+					t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+// byContentType dispatches a request to the handler registered for its Content-Type
+// allowedに含まれないメディアタイプは登録されていても415とする（nilの場合は登録済みの全てを受け付ける）
+// Content-Type未指定のリクエストは従来どおりJSONとして扱う
+func byContentType(allowed []string, handlers map[string]http.Handler) http.Handler {
+	enabled := make(map[string]http.Handler, len(handlers))
+	for mediaType, handler := range handlers {
+		if allowed == nil || slices.Contains(allowed, mediaType) {
+			enabled[mediaType] = handler
+		}
+	}
+	supported := slices.Sorted(maps.Keys(enabled))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType := contentTypeJSON
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			parsed, _, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				unsupportedMediaType(w, r, supported)
+				return
+			}
+			mediaType = parsed
+		}
+		handler, ok := enabled[mediaType]
+		if !ok {
+			unsupportedMediaType(w, r, supported)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// unsupportedMediaType answers 415 listing the accepted Content-Types
+// Accept-Post（RFC 9110の拡張）でもクライアントに受け付ける形式を伝える
+func unsupportedMediaType(w http.ResponseWriter, r *http.Request, supported []string) {
+	w.Header().Set("Accept-Post", strings.Join(supported, ", "))
+	response := ErrorResponse{
+		Error: "Unsupported Media Type",
+		Problems: map[string]string{
+			"content_type": "supported content types: " + strings.Join(supported, ", "),
+		},
+	}
+	encode(w, r, http.StatusUnsupportedMediaType, response)
+}
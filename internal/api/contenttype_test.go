@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestBlogsCreate_ByContentType(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	tests := []struct {
+		name        string
+		allowed     []string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBlogs   int
+	}{
+		{
+			name:        "JSON creates one blog",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"title":"First","content":"Content","author":"Author"}`,
+			wantStatus:  http.StatusCreated,
+			wantBlogs:   1,
+		},
+		{
+			name:        "NDJSON creates one blog per line",
+			contentType: "application/x-ndjson",
+			body: `{"title":"First","content":"Content","author":"Author"}
+{"title":"Second","content":"Content","author":"Author"}
+`,
+			wantStatus: http.StatusCreated,
+			wantBlogs:  2,
+		},
+		{
+			name:        "unsupported type",
+			contentType: "text/csv",
+			body:        "title,content,author",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "registered type not allowed",
+			allowed:     []string{"application/json"},
+			contentType: "application/x-ndjson",
+			body:        `{"title":"First","content":"Content","author":"Author"}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blogStore := store.NewMemoryBlogStore()
+			mux := http.NewServeMux()
+			addRoutes(mux, log, &config.Config{AllowedContentTypes: tt.allowed}, blogStore, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			blogs, _ := blogStore.GetAll(context.Background())
+			if len(blogs) != tt.wantBlogs {
+				t.Errorf("expected %d stored blogs, got %d", tt.wantBlogs, len(blogs))
+			}
+
+			switch {
+			case tt.wantStatus == http.StatusUnsupportedMediaType:
+				if got := w.Header().Get("Accept-Post"); got == "" {
+					t.Error("expected Accept-Post to list the supported types")
+				}
+			case tt.wantBlogs > 1:
+				// 一括作成は行ごとの結果をまとめて返す
+				var batch batchResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &batch); err != nil {
+					t.Fatalf("expected a batch response, got %s", w.Body.String())
+				}
+				if batch.Created != tt.wantBlogs || batch.Results[1].Blog.Title != "Second" {
+					t.Errorf("expected created blogs in line order, got %+v", batch)
+				}
+			case tt.wantBlogs == 1:
+				var created domain.Blog
+				if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+					t.Fatalf("expected a single blog, got %s", w.Body.String())
+				}
+			}
+		})
+	}
+}
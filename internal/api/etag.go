@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// collectionETag derives a weak ETag from the ordered IDs and UpdatedAts of a listing
+// 並び順を含めてハッシュするため、呼び出し側は決定的な順序（sortOrder.apply）で渡す
+// SetSlugはUpdatedAtを更新しないため、スラッグもハッシュに含める
+// gzipの有無などで表現のバイト列は変わるので弱いETagとする
+func collectionETag(blogs []*domain.Blog) string {
+	h := sha256.New()
+	for _, blog := range blogs {
+		h.Write([]byte(blog.ID))
+		h.Write([]byte{0})
+		h.Write([]byte(blog.Slug))
+		h.Write([]byte{0})
+		h.Write(strconv.AppendInt(nil, blog.UpdatedAt.UnixNano(), 10))
+		h.Write([]byte{'\n'})
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// blogETag derives a weak ETag for a single blog
+// 一覧と同じく、ID・スラッグ・更新日時から計算する
+func blogETag(blog *domain.Blog) string {
+	return collectionETag([]*domain.Blog{blog})
+}
+
+// contentETag derives a strong ETag from the raw content bytes
+// /contentはバイト範囲の取得に使われ、If-Rangeは強いETagでしか一致しないため強いETagとする
+func contentETag(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match or If-Match header matches etag
+// ETagは弱いため、If-Matchでも弱い比較を使い W/ の有無は無視する
+// （バイト列ではなくブログの版が一致するかを確認する用途）
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+// runExpirySweeper deletes expired blogs every interval until ctx is done
+// 期限切れの投稿は読み取りからは既に除外されているため、ここでの削除は領域の回収のみ
+// サーバーのコンテキストを渡し、シャットダウン時に確実に停止させる
+// ラップしたストアの内側が削除に対応していない場合（errors.ErrUnsupported）は警告を出して停止する
+func runExpirySweeper(ctx context.Context, log *logger.Logger, sweeper store.ExpirySweeper, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			removed, err := sweeper.DeleteExpired(ctx, now)
+			if errors.Is(err, errors.ErrUnsupported) {
+				log.Warn(ctx, "store cannot delete expired blogs, stopping the expiry sweeper", "error", err)
+				return
+			}
+			if err != nil {
+				log.Error(ctx, "failed to delete expired blogs", "error", err)
+				continue
+			}
+			if removed > 0 {
+				log.Info(ctx, "deleted expired blogs", "count", removed)
+			}
+		}
+	}
+}
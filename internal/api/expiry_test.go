@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestRunExpirySweeper(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	expiresAt := time.Now().Add(20 * time.Millisecond)
+	blogStore.Create(context.Background(), &domain.Blog{ID: "1", Title: "Title", CreatedAt: time.Now(), ExpiresAt: &expiresAt})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runExpirySweeper(ctx, log, blogStore, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// 期限切れ後に削除されるまで待つ（Snapshotは期限切れの投稿も含むため、削除の確認に使える）
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := blogStore.Snapshot(context.Background())
+		if err != nil {
+			t.Fatalf("failed to snapshot: %v", err)
+		}
+		if !strings.Contains(string(data), `"id":"1"`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the sweeper to delete the expired blog")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := blogStore.GetByID(context.Background(), "1"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after the sweep, got %v", err)
+	}
+
+	// シャットダウン時にはコンテキストのキャンセルで停止する
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the sweeper to stop when the context is cancelled")
+	}
+}
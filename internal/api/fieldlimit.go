@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+)
+
+// fieldTooLargeError reports a JSON string field that exceeded its streaming limit
+type fieldTooLargeError struct {
+	Field string
+	Limit int
+}
+
+func (e *fieldTooLargeError) Error() string {
+	return fmt.Sprintf("field %q exceeds %d bytes", e.Field, e.Limit)
+}
+
+// fieldLimits returns the per-field byte limits enforced while decoding a blog body
+// バリデーションの上限をそのまま使うと少し長いだけの入力でも詳細な400ではなく413になるため、
+// EDGE_FIELD_LIMIT_FACTOR倍の余裕を持たせ、桁違いに大きい入力だけを早期に打ち切る
+func fieldLimits(cfg *config.Config) map[string]int {
+	if cfg.EdgeFieldLimitFactor <= 0 {
+		return nil
+	}
+	return map[string]int{
+		"title":   cfg.MaxTitleLen * cfg.EdgeFieldLimitFactor,
+		"content": cfg.MaxContentLen * cfg.EdgeFieldLimitFactor,
+		"author":  cfg.MaxAuthorLen * cfg.EdgeFieldLimitFactor,
+	}
+}
+
+// withFieldLimits makes requestBody enforce limits on top-level string fields
+func withFieldLimits(r *http.Request, limits map[string]int) *http.Request {
+	if len(limits) == 0 {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), fieldLimitsKey, limits))
+}
+
+func fieldLimitsFromContext(ctx context.Context) map[string]int {
+	limits, _ := ctx.Value(fieldLimitsKey).(map[string]int)
+	return limits
+}
+
+// maxTrackedKeyLen caps how much of an object key is remembered
+const maxTrackedKeyLen = 64
+
+// fieldLimitReader scans JSON as it streams through and fails as soon as a
+// top-level string value grows past its field's limit
+// json.Decoderは値全体をバッファしてから構造体に格納するため、
+// 10MBのタイトルでもボディサイズ上限内なら全て読み込まれてしまう
+// トークン単位で長さを数えることで、巨大なフィールドを読み切る前に打ち切る
+// 長さはエスケープのバックスラッシュを除いたバイト数で概算する
+type fieldLimitReader struct {
+	r      io.Reader
+	limits map[string]int
+	err    error
+
+	depth     int
+	inString  bool
+	escaped   bool
+	expectKey bool
+	isKey     bool
+	key       []byte
+	field     string
+	length    int
+	limit     int
+}
+
+func newFieldLimitReader(r io.Reader, limits map[string]int) *fieldLimitReader {
+	return &fieldLimitReader{r: r, limits: limits}
+}
+
+func (f *fieldLimitReader) Read(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	n, err := f.r.Read(p)
+	for i, c := range p[:n] {
+		if scanErr := f.scan(c); scanErr != nil {
+			// 超過したバイト以降は渡さず、デコーダーが値を完成させられないようにする
+			f.err = scanErr
+			return i, scanErr
+		}
+	}
+	return n, err
+}
+
+func (f *fieldLimitReader) scan(c byte) error {
+	if f.inString {
+		switch {
+		case f.escaped:
+			f.escaped = false
+		case c == '\\':
+			f.escaped = true
+			return nil
+		case c == '"':
+			f.inString = false
+			return nil
+		}
+		f.length++
+		if f.isKey && len(f.key) < maxTrackedKeyLen {
+			f.key = append(f.key, c)
+		}
+		if f.limit > 0 && f.length > f.limit {
+			return &fieldTooLargeError{Field: f.field, Limit: f.limit}
+		}
+		return nil
+	}
+
+	switch c {
+	case '{':
+		f.depth++
+		f.expectKey = f.depth == 1
+	case '[':
+		f.depth++
+	case '}', ']':
+		f.depth--
+	case ',':
+		f.expectKey = f.depth == 1
+	case ':':
+		if f.depth == 1 {
+			// encoding/jsonはキーを大文字小文字を区別せずに照合する
+			f.field = strings.ToLower(string(f.key))
+		}
+		f.expectKey = false
+	case '"':
+		f.inString = true
+		f.length = 0
+		f.isKey = f.expectKey && f.depth == 1
+		f.limit = 0
+		if f.isKey {
+			f.key = f.key[:0]
+		} else if f.depth == 1 {
+			f.limit = f.limits[f.field]
+		}
+	}
+	return nil
+}
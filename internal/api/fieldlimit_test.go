@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFieldLimitReader(t *testing.T) {
+	limits := map[string]int{"title": 10, "author": 5}
+
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{
+			name: "within limits",
+			body: `{"title":"0123456789","author":"alice","content":"` + strings.Repeat("x", 100) + `"}`,
+		},
+		{
+			name:      "title over limit",
+			body:      `{"title":"0123456789a","author":"alice"}`,
+			wantField: "title",
+		},
+		{
+			name:      "keys are matched case-insensitively",
+			body:      `{"Author":"alice and bob"}`,
+			wantField: "author",
+		},
+		{
+			name: "escapes count as one byte",
+			body: `{"title":"\"quoted\"!!"}`,
+		},
+		{
+			name: "key text inside values is ignored",
+			body: `{"content":"\"title\":\"` + strings.Repeat("x", 20) + `"}`,
+		},
+		{
+			name: "nested values are not limited",
+			body: `{"tags":[{"title":"` + strings.Repeat("x", 20) + `"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v map[string]any
+			err := json.NewDecoder(newFieldLimitReader(strings.NewReader(tt.body), limits)).Decode(&v)
+
+			var fieldErr *fieldTooLargeError
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("expected fieldTooLargeError, got %v", err)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("expected field %q, got %q", tt.wantField, fieldErr.Field)
+			}
+		})
+	}
+}
+
+// countingReader records how many bytes have been consumed
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
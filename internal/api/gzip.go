@@ -0,0 +1,131 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMiddleware compresses responses for clients that accept gzip
+// gzip.Writerの生成はコストが高いため、圧縮レベルごとにsync.Poolで再利用する
+// レベルはconfig.Loadで1〜9に検証済み
+func gzipMiddleware(level int) func(http.Handler) http.Handler {
+	pool := &sync.Pool{
+		New: func() any {
+			zw, err := gzip.NewWriterLevel(io.Discard, level)
+			if err != nil {
+				// 範囲外のレベルはLoadで弾かれるため、ここに来るのはプログラムの誤り
+				panic(err)
+			}
+			return zw
+		},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, pool: pool}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		// gzip;q=0 は明示的な拒否
+		if qStr, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(qStr, 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter compresses the body written through it
+// ボディを持たないステータス（204/304など）や、既にエンコード済みの
+// レスポンスは圧縮せずにそのまま書き込む
+// Accept-Rangesを返すレスポンスも、バイト範囲が元のバイト列を指すよう圧縮しない
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	pool        *sync.Pool
+	zw          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.ResponseWriter.Header()
+	if bodyAllowed(statusCode) && h.Get("Content-Encoding") == "" && h.Get("Accept-Ranges") == "" {
+		h.Set("Content-Encoding", "gzip")
+		// 圧縮後のサイズは事前にわからない
+		h.Del("Content-Length")
+		w.zw = w.pool.Get().(*gzip.Writer)
+		w.zw.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.zw == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.zw.Write(p)
+}
+
+// Flush flushes compressed data so streaming responses reach the client
+func (w *gzipResponseWriter) Flush() {
+	if w.zw != nil {
+		w.zw.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the gzip stream and returns the writer to the pool
+func (w *gzipResponseWriter) close() {
+	if w.zw == nil {
+		return
+	}
+	w.zw.Close()
+	w.zw.Reset(io.Discard)
+	w.pool.Put(w.zw)
+	w.zw = nil
+}
+
+// bodyAllowed reports whether a response with the given status may have a body
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
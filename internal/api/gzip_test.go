@@ -0,0 +1,137 @@
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"title":"Compressible","content":"Lorem ipsum dolor sit amet"}`, 50)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	})
+
+	wrappedHandler := gzipMiddleware(5)(handler)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		expectGzip     bool
+	}{
+		{
+			name:           "gzip accepted",
+			acceptEncoding: "gzip, deflate",
+			expectGzip:     true,
+		},
+		{
+			name:           "gzip with quality",
+			acceptEncoding: "br;q=1.0, gzip;q=0.8",
+			expectGzip:     true,
+		},
+		{
+			name:           "gzip explicitly refused",
+			acceptEncoding: "gzip;q=0",
+			expectGzip:     false,
+		},
+		{
+			name:           "no Accept-Encoding",
+			acceptEncoding: "",
+			expectGzip:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+
+			wrappedHandler.ServeHTTP(w, req)
+
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+			}
+
+			gotGzip := w.Header().Get("Content-Encoding") == "gzip"
+			if gotGzip != tt.expectGzip {
+				t.Fatalf("expected gzip=%v, got %v", tt.expectGzip, gotGzip)
+			}
+
+			var reader io.Reader = w.Body
+			if gotGzip {
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("failed to read gzip body: %v", err)
+				}
+				reader = zr
+			}
+			decoded, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+			if string(decoded) != body {
+				t.Error("decoded body does not match original")
+			}
+		})
+	}
+}
+
+func TestGzipMiddleware_NoContent(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	gzipMiddleware(5)(handler).ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Error("expected no Content-Encoding for 204")
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %d bytes", w.Body.Len())
+	}
+}
+
+func TestGzipMiddleware_Level(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&sb, `{"id":"%d","title":"Post number %d","content":"%s"}`, i, i*7919%1000, strings.Repeat("ab", i%13))
+	}
+	body := sb.String()
+
+	compressedSize := func(level int) int {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, body)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		gzipMiddleware(level)(handler).ServeHTTP(w, req)
+		return w.Body.Len()
+	}
+
+	fastest := compressedSize(1)
+	smallest := compressedSize(9)
+
+	if smallest > fastest {
+		t.Errorf("expected level 9 (%d bytes) to be no larger than level 1 (%d bytes)", smallest, fastest)
+	}
+	if fastest >= len(body) {
+		t.Errorf("expected compression, got %d bytes for %d byte input", fastest, len(body))
+	}
+}
@@ -1,21 +1,37 @@
 package api
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
 	"github.com/moko-poi/blog-api-server/internal/logger"
 	"github.com/moko-poi/blog-api-server/internal/store"
-	"github.com/moko-poi/blog-api-server/internal/domain"
 )
 
 // handleHealthz returns a simple health check
-func handleHealthz(log *logger.Logger) http.Handler {
+// バックグラウンドのヘルスチェックが劣化を検知している場合も200のまま、statusを"degraded"とする
+func handleHealthz(log *logger.Logger, health *healthState) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := map[string]string{
 			"status": "ok",
 		}
+		if health != nil {
+			if reason, ok := health.degraded(); ok {
+				response["status"] = "degraded"
+				response["reason"] = reason
+			}
+		}
 		if err := encode(w, r, http.StatusOK, response); err != nil {
 			log.Error(r.Context(), "failed to encode health response", "error", err)
 		}
@@ -23,13 +39,21 @@ func handleHealthz(log *logger.Logger) http.Handler {
 }
 
 // handleBlogsCreate creates a new blog post
-func handleBlogsCreate(log *logger.Logger, blogStore store.BlogStore) http.Handler {
+func handleBlogsCreate(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, authors *authorLimiter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			methodNotAllowed(w, r, http.MethodPost)
 			return
 		}
 
+		// ?tz= の誤りは作成する前に400とする
+		loc, ok := locationOrError(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		// 巨大なフィールドは全て読み込む前に413で打ち切る
+		r = withFieldLimits(r, fieldLimits(cfg))
 		req, problems, err := decodeValid[domain.CreateBlogRequest](r)
 		if err != nil {
 			if problems != nil {
@@ -41,80 +65,796 @@ func handleBlogsCreate(log *logger.Logger, blogStore store.BlogStore) http.Handl
 				return
 			}
 			log.Error(r.Context(), "failed to decode request", "error", err)
-			response := ErrorResponse{Error: "Invalid request body"}
-			encode(w, r, http.StatusBadRequest, response)
+			status, response := decodeErrorResponse(err)
+			encode(w, r, status, response)
 			return
 		}
 
-		blog := domain.NewBlog(req)
-		if err := blogStore.Create(r.Context(), blog); err != nil {
-			log.Error(r.Context(), "failed to create blog", "error", err)
-			response := ErrorResponse{Error: "Failed to create blog"}
-			encode(w, r, http.StatusInternalServerError, response)
+		// 作者ごとの投稿レート制限
+		author := authorNormalization(cfg).Apply(req.Author)
+		if ok, retryAfter := authors.allow(author); !ok {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			response := ErrorResponse{Error: "Author post rate exceeded"}
+			encode(w, r, http.StatusTooManyRequests, response)
+			return
+		}
+
+		stop := timeStore(r.Context())
+		blog, err := createBlog(r.Context(), cfg, blogStore, req, authSubject(r, cfg))
+		stop()
+		if err != nil {
+			// 作成できなかった投稿は投稿レートに数えない
+			authors.release(author)
+			status, response := createErrorResponse(r, err)
+			switch {
+			case errors.Is(err, errAuthorBudgetExceeded):
+				log.Info(r.Context(), "author content budget exceeded", "author", req.Author)
+			case status == http.StatusInsufficientStorage:
+				log.Warn(r.Context(), "blog store is full", "error", err)
+			case status >= http.StatusInternalServerError:
+				log.Error(r.Context(), "failed to create blog", "error", err)
+			}
+			encode(w, r, status, response)
 			return
 		}
 
 		log.Info(r.Context(), "blog created", "id", blog.ID, "title", blog.Title)
-		encode(w, r, http.StatusCreated, blog)
+		encode(w, r, http.StatusCreated, createResponse{Blog: inZone(blog, loc), Warnings: createWarnings(r.Context(), log, cfg, blogStore, blog)})
+	})
+}
+
+// handleBlogsCreateNDJSON creates one blog per line of an application/x-ndjson body
+// 各行は独立して処理し、不正な行や作成に失敗した行があっても残りの行は作成する
+// 全て成功なら201、全て失敗なら400、混在する場合は207で、行ごとの結果と件数を返す
+func handleBlogsCreateNDJSON(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, authors *authorLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		loc, ok := locationOrError(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		// 行ごとに巨大なフィールドを全て読み込む前に打ち切る（単一の作成と同じ上限）
+		r = withFieldLimits(r, fieldLimits(cfg))
+		records, err := decodeNDJSON[domain.CreateBlogRequest](r, cfg.NDJSONMaxRecords)
+		if err != nil {
+			log.Error(r.Context(), "failed to decode request", "error", err)
+			status, response := decodeErrorResponse(err)
+			encode(w, r, status, response)
+			return
+		}
+
+		response := batchResponse{Results: make([]batchResult, 0, len(records))}
+		for _, record := range records {
+			result := createBatchItem(r, log, cfg, blogStore, authors, record)
+			if result.Blog != nil {
+				result.Blog = inZone(result.Blog, loc)
+				response.Created++
+			} else {
+				response.Failed++
+			}
+			response.Results = append(response.Results, result)
+		}
+
+		status := http.StatusMultiStatus
+		switch {
+		case response.Failed == 0:
+			status = http.StatusCreated
+		case response.Created == 0:
+			status = http.StatusBadRequest
+		}
+		log.Info(r.Context(), "blog batch processed", "created", response.Created, "failed", response.Failed)
+		encode(w, r, status, response)
 	})
 }
 
-// handleBlogsGet retrieves all blogs or filters by author
-func handleBlogsGet(log *logger.Logger, blogStore store.BlogStore) http.Handler {
+// createBatchItem creates the blog for one NDJSON record and classifies the outcome
+func createBatchItem(r *http.Request, log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, authors *authorLimiter, record ndjsonRecord[domain.CreateBlogRequest]) batchResult {
+	if record.Err != nil {
+		status, response := decodeErrorResponse(record.Err)
+		return batchResult{Line: record.Line, Status: status, Error: response.Error, Problems: response.Problems}
+	}
+	if record.Problems != nil {
+		return batchResult{Line: record.Line, Status: http.StatusBadRequest, Error: "Validation failed", Problems: record.Problems}
+	}
+	author := authorNormalization(cfg).Apply(record.Value.Author)
+	if ok, retryAfter := authors.allow(author); !ok {
+		return batchResult{
+			Line:       record.Line,
+			Status:     http.StatusTooManyRequests,
+			Error:      "Author post rate exceeded",
+			RetryAfter: int(math.Ceil(retryAfter.Seconds())),
+		}
+	}
+
+	blog, err := createBlog(r.Context(), cfg, blogStore, record.Value, authSubject(r, cfg))
+	if err != nil {
+		// 作成できなかった行は投稿レートに数えない
+		authors.release(author)
+		status, response := createErrorResponse(r, err)
+		if status >= http.StatusInternalServerError && !errors.Is(err, errAuthorBudgetExceeded) {
+			log.Error(r.Context(), "failed to create blog", "error", err, "line", record.Line)
+		}
+		return batchResult{Line: record.Line, Status: status, Error: response.Error}
+	}
+	return batchResult{Line: record.Line, Status: http.StatusCreated, Blog: blog, Warnings: createWarnings(r.Context(), log, cfg, blogStore, blog)}
+}
+
+// handleBlogsGet retrieves all blogs or filters by author and/or category
+// ?limit= と ?offset= でページングする
+func handleBlogsGet(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		// STRICT_QUERY_PARAMS指定時は、黙って無視する代わりに未知のパラメータを400とする
+		// （?autor= のようなクライアントの綴り間違いで絞り込みが効かないことに気づけるように）
+		if cfg.StrictQueryParams {
+			if name, ok := unknownQueryParam(r, blogsListParams); ok {
+				response := ErrorResponse{
+					Error:    "Unknown query parameter",
+					Problems: map[string]string{name: "unknown query parameter " + strconv.Quote(name)},
+				}
+				encode(w, r, http.StatusBadRequest, response)
+				return
+			}
+		}
+
+		fields, err := parseFields(r)
+		if err != nil {
+			response := ErrorResponse{
+				Error:    "Invalid fields parameter",
+				Problems: map[string]string{"fields": err.Error()},
+			}
+			encode(w, r, http.StatusBadRequest, response)
+			return
+		}
+
+		loc, ok := locationOrError(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		p, problems := parsePage(r, cfg)
+		if len(problems) > 0 {
+			response := ErrorResponse{
+				Error:    "Invalid pagination parameters",
+				Problems: problems,
+			}
+			encode(w, r, http.StatusBadRequest, response)
+			return
+		}
+
+		// ?sort= が省略された場合はDEFAULT_SORTを使う
+		sortSpec := r.URL.Query().Get("sort")
+		if sortSpec == "" {
+			sortSpec = cfg.DefaultSort
+		}
+		order, err := parseSort(sortSpec)
+		if err == nil {
+			err = checkSortable(order, cfg.SortableFields)
+		}
+		if err != nil {
+			response := ErrorResponse{
+				Error:    "Invalid sort parameter",
+				Problems: map[string]string{"sort": err.Error()},
+			}
+			encode(w, r, http.StatusBadRequest, response)
 			return
 		}
 
-		author := r.URL.Query().Get("author")
+		filter, err := parseBlogFilter(r, cfg)
+		if err != nil {
+			response := ErrorResponse{
+				Error:    "Too many tag parameters",
+				Problems: map[string]string{"tag": err.Error()},
+			}
+			encode(w, r, http.StatusBadRequest, response)
+			return
+		}
+		author, category := filter.Author, filter.Category
 
 		var blogs []*domain.Blog
-		var err error
+		// pagedはストア側の走査で並べ替えとページングが済んでいることを示す
+		paged := false
 
-		if author != "" {
+		stop := timeStore(r.Context())
+		iterator, canIterate := blogStore.(store.Iterator)
+		switch {
+		case author != "":
 			blogs, err = blogStore.GetByAuthor(r.Context(), author)
-		} else {
+			// 作者とカテゴリーの両方が指定された場合は作者の投稿を絞り込む
+			if err == nil && category != "" {
+				blogs = filterBlogs(blogs, filter.matches)
+			}
+		case category != "":
+			blogs, err = blogStore.GetByCategory(r.Context(), category)
+		case canIterate:
+			// 全件のコピーを作らず、ページに入る分だけを集める
+			blogs, err = selectPage(r.Context(), iterator, order, p, filter.matches)
+			paged = true
+		default:
 			blogs, err = blogStore.GetAll(r.Context())
 		}
+		stop()
 
 		if err != nil {
 			log.Error(r.Context(), "failed to get blogs", "error", err)
-			response := ErrorResponse{Error: "Failed to retrieve blogs"}
+			status, response := storeErrorResponse(err, "Failed to retrieve blogs")
+			encode(w, r, status, response)
+			return
+		}
+
+		// 作者IDで取得するストアのメソッドはないため、取得した中から絞り込む
+		// （?author= は表示名のみ、?author_id= は作者IDのみに一致する）
+		if !paged && filter.AuthorID != "" {
+			blogs = filterBlogs(blogs, filter.matches)
+		}
+
+		// 存在しない作者を404とするかは設定で選択できる（デフォルトは200と空配列）
+		if (author != "" || filter.AuthorID != "") && len(blogs) == 0 && cfg.EmptyResultStatus == http.StatusNotFound {
+			response := ErrorResponse{Error: "No blogs found for author"}
+			encode(w, r, http.StatusNotFound, response)
+			return
+		}
+
+		if !paged {
+			if len(filter.Tags) > 0 {
+				blogs = filterByTags(blogs, filter.Tags, filter.MatchAny)
+			}
+			order.apply(blogs)
+			blogs = p.apply(blogs)
+		}
+		blogs = inZoneAll(blogs, loc)
+
+		// 一覧が変わっていなければ304を返し、クライアントは再取得を省ける
+		etag := collectionETag(blogs)
+		w.Header().Set("ETag", etag)
+		if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatches(inm, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		// スパースフィールドセット指定時は要求されたフィールドのみ返す
+		if fields != nil {
+			sparse := make([]map[string]json.RawMessage, 0, len(blogs))
+			for _, blog := range blogs {
+				selected, err := selectFields(r.Context(), blog, fields)
+				if err != nil {
+					log.Error(r.Context(), "failed to select fields", "error", err)
+					response := ErrorResponse{Error: "Failed to retrieve blogs"}
+					encode(w, r, http.StatusInternalServerError, response)
+					return
+				}
+				sparse = append(sparse, selected)
+			}
+			encode(w, r, http.StatusOK, sparse)
+			return
+		}
+
+		encodeArray(w, r, http.StatusOK, blogs)
+	})
+}
+
+// handleBlogsCount returns how many blogs the listing would return for the same filters
+// ダッシュボード向けに、投稿本体を返さず件数だけを返す（ページングと並び順は関係しない）
+func handleBlogsCount(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		if cfg.StrictQueryParams {
+			if name, ok := unknownQueryParam(r, blogsCountParams); ok {
+				response := ErrorResponse{
+					Error:    "Unknown query parameter",
+					Problems: map[string]string{name: "unknown query parameter " + strconv.Quote(name)},
+				}
+				encode(w, r, http.StatusBadRequest, response)
+				return
+			}
+		}
+
+		filter, err := parseBlogFilter(r, cfg)
+		if err != nil {
+			response := ErrorResponse{
+				Error:    "Too many tag parameters",
+				Problems: map[string]string{"tag": err.Error()},
+			}
+			encode(w, r, http.StatusBadRequest, response)
+			return
+		}
+
+		count := 0
+		stop := timeStore(r.Context())
+		if iterator, ok := blogStore.(store.Iterator); ok {
+			err = iterator.Each(r.Context(), func(blog *domain.Blog) error {
+				if filter.matches(blog) {
+					count++
+				}
+				return nil
+			})
+		} else {
+			var blogs []*domain.Blog
+			blogs, err = blogStore.GetAll(r.Context())
+			for _, blog := range blogs {
+				if filter.matches(blog) {
+					count++
+				}
+			}
+		}
+		stop()
+		if err != nil {
+			log.Error(r.Context(), "failed to count blogs", "error", err)
+			status, response := storeErrorResponse(err, "Failed to count blogs")
+			encode(w, r, status, response)
+			return
+		}
+
+		encode(w, r, http.StatusOK, countResponse{Count: count})
+	})
+}
+
+// defaultRecentCount is the number of blogs returned by /recent without ?n=
+const defaultRecentCount = 5
+
+// handleBlogsRecent returns the n newest blogs for "latest posts" widgets
+// nはMAX_PAGE_SIZE（未設定時は100）で頭打ちにする
+func handleBlogsRecent(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		n := defaultRecentCount
+		if nStr := r.URL.Query().Get("n"); nStr != "" {
+			parsed, err := strconv.Atoi(nStr)
+			if err != nil || parsed < 1 {
+				response := ErrorResponse{
+					Error:    "Invalid n parameter",
+					Problems: map[string]string{"n": "n must be a positive integer"},
+				}
+				encode(w, r, http.StatusBadRequest, response)
+				return
+			}
+			n = parsed
+		}
+		maxRecent := cfg.MaxPageSize
+		if maxRecent <= 0 {
+			maxRecent = 100
+		}
+		n = min(n, maxRecent)
+
+		loc, ok := locationOrError(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		stop := timeStore(r.Context())
+		blogs, err := blogStore.Recent(r.Context(), n)
+		stop()
+		if err != nil {
+			log.Error(r.Context(), "failed to get recent blogs", "error", err)
+			status, response := storeErrorResponse(err, "Failed to retrieve blogs")
+			encode(w, r, status, response)
+			return
+		}
+
+		encode(w, r, http.StatusOK, inZoneAll(blogs, loc))
+	})
+}
+
+// handleBlogsExport streams every blog as NDJSON (one JSON object per line) in ID order
+// ?after=<id> を指定するとそのIDより後ろから再開できるため、中断したエクスポートを続きから取得できる
+func handleBlogsExport(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		loc, ok := locationOrError(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		blogs, err := blogStore.GetAll(r.Context())
+		if err != nil {
+			log.Error(r.Context(), "failed to get blogs for export", "error", err)
+			status, response := storeErrorResponse(err, "Failed to export blogs")
+			encode(w, r, status, response)
+			return
+		}
+
+		// IDの昇順をカーソルの基準とする
+		// カーソルのIDが削除済みでも「それより大きいID」から再開できる
+		slices.SortFunc(blogs, func(a, b *domain.Blog) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+		if after := r.URL.Query().Get("after"); after != "" {
+			i, _ := slices.BinarySearchFunc(blogs, after, func(b *domain.Blog, id string) int {
+				return cmp.Compare(b.ID, id)
+			})
+			for i < len(blogs) && blogs[i].ID == after {
+				i++
+			}
+			blogs = blogs[i:]
+		}
+
+		stream, err := startStream(w, cfg.StreamIdleTimeout)
+		if err != nil {
+			log.Error(r.Context(), "failed to start export stream", "error", err)
+			response := ErrorResponse{Error: "Failed to export blogs"}
 			encode(w, r, http.StatusInternalServerError, response)
 			return
 		}
 
-		encode(w, r, http.StatusOK, blogs)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+
+		camel := fieldCaseFromContext(r.Context()) == fieldCaseCamel
+		enc := newJSONEncoder(r.Context(), stream)
+		for _, blog := range blogs {
+			var line any = inZone(blog, loc)
+			if camel {
+				line = camelCaseKeys(line)
+			}
+			if err := enc.Encode(line); err != nil {
+				// ヘッダー送信後なのでステータスは変更できない。クライアントは?afterで再開する
+				log.Warn(r.Context(), "export stream interrupted", "id", blog.ID, "error", err)
+				return
+			}
+		}
+	})
+}
+
+// handleStats returns aggregate statistics for the admin dashboard
+func handleStats(log *logger.Logger, blogStore store.BlogStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		stop := timeStore(r.Context())
+		stats, err := blogStore.Stats(r.Context())
+		stop()
+		if err != nil {
+			log.Error(r.Context(), "failed to compute stats", "error", err)
+			status, response := storeErrorResponse(err, "Failed to retrieve stats")
+			encode(w, r, status, response)
+			return
+		}
+
+		encode(w, r, http.StatusOK, stats)
+	})
+}
+
+// handleAdminRateLimits returns the current rate-limit bucket states
+// デバッグ/可観測性用途。レート制限が無効な場合は空配列を返す
+func handleAdminRateLimits(log *logger.Logger, limiter *rateLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		buckets := []rateLimitBucket{}
+		if limiter != nil {
+			buckets = limiter.snapshot()
+		}
+
+		if err := encode(w, r, http.StatusOK, buckets); err != nil {
+			log.Error(r.Context(), "failed to encode rate limit buckets", "error", err)
+		}
+	})
+}
+
+// handleAdminSnapshot returns the whole dataset as a JSON snapshot
+// スナップショットに対応していないストアでは501を返す
+func handleAdminSnapshot(log *logger.Logger, blogStore store.BlogStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		snapshotter, ok := blogStore.(store.Snapshotter)
+		if !ok {
+			response := ErrorResponse{Error: "Snapshots are not supported by this store"}
+			encode(w, r, http.StatusNotImplemented, response)
+			return
+		}
+
+		data, err := snapshotter.Snapshot(r.Context())
+		if errors.Is(err, errors.ErrUnsupported) {
+			response := ErrorResponse{Error: "Snapshots are not supported by this store"}
+			encode(w, r, http.StatusNotImplemented, response)
+			return
+		}
+		if err != nil {
+			log.Error(r.Context(), "failed to snapshot store", "error", err)
+			status, response := storeErrorResponse(err, "Failed to create snapshot")
+			encode(w, r, status, response)
+			return
+		}
+
+		// スナップショットは既にJSONなのでそのまま書き出す
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(data); err != nil {
+			log.Error(r.Context(), "failed to write snapshot", "error", err)
+		}
+	})
+}
+
+// handleAdminRestore replaces the whole dataset with a snapshot from the request body
+func handleAdminRestore(log *logger.Logger, blogStore store.BlogStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, r, http.MethodPost)
+			return
+		}
+
+		snapshotter, ok := blogStore.(store.Snapshotter)
+		if !ok {
+			response := ErrorResponse{Error: "Snapshots are not supported by this store"}
+			encode(w, r, http.StatusNotImplemented, response)
+			return
+		}
+
+		body, err := requestBody(r)
+		if err == nil {
+			var data []byte
+			if data, err = io.ReadAll(body); err == nil {
+				err = snapshotter.Restore(r.Context(), data)
+			}
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			switch {
+			case errors.As(err, &maxBytesErr), errors.Is(err, errInvalidGzip):
+				status, response := decodeErrorResponse(err)
+				encode(w, r, status, response)
+			case errors.Is(err, store.ErrCapacityExceeded):
+				response := ErrorResponse{Error: "Snapshot exceeds store capacity"}
+				encode(w, r, http.StatusInsufficientStorage, response)
+			case errors.Is(err, errors.ErrUnsupported):
+				response := ErrorResponse{Error: "Snapshots are not supported by this store"}
+				encode(w, r, http.StatusNotImplemented, response)
+			default:
+				log.Warn(r.Context(), "failed to restore snapshot", "error", err)
+				response := ErrorResponse{Error: "Invalid snapshot", Problems: map[string]string{"snapshot": err.Error()}}
+				encode(w, r, http.StatusBadRequest, response)
+			}
+			return
+		}
+
+		log.Info(r.Context(), "store restored from snapshot")
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
 
 // handleBlogsByID handles operations on a specific blog (GET, PUT, DELETE)
-func handleBlogsByID(log *logger.Logger, blogStore store.BlogStore) http.Handler {
+func handleBlogsByID(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore) http.Handler {
+	// 同じIDへの同時のGETはストアの呼び出しを1回にまとめる（SINGLEFLIGHT_READS）
+	reads := newFlightGroup[*domain.Blog](cfg.SingleflightReads)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extract ID from path
+		// Extract ID (and optional sub-resource) from path
 		path := strings.TrimPrefix(r.URL.Path, "/api/v1/blogs/")
-		if path == "" || strings.Contains(path, "/") {
+		id, subresource, _ := strings.Cut(path, "/")
+		if id == "" {
+			response := ErrorResponse{Error: "Invalid blog ID"}
+			encode(w, r, http.StatusBadRequest, response)
+			return
+		}
+
+		// サブリソース /api/v1/blogs/{id}/{subresource}
+		// ルートテンプレートにはIDを含めず、既知のサブリソースのみ含める
+		switch subresource {
+		case "":
+			setRouteTemplate(r.Context(), "/api/v1/blogs/{id}")
+		case "revisions":
+			setRouteTemplate(r.Context(), "/api/v1/blogs/{id}/revisions")
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, r, http.MethodGet)
+				return
+			}
+			handleBlogRevisions(log, cfg, blogStore, id, w, r)
+			return
+		case "slug/regenerate":
+			setRouteTemplate(r.Context(), "/api/v1/blogs/{id}/slug/regenerate")
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, r, http.MethodPost)
+				return
+			}
+			handleBlogSlugRegenerate(log, cfg, blogStore, id, w, r)
+			return
+		case "content":
+			setRouteTemplate(r.Context(), "/api/v1/blogs/{id}/content")
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+				return
+			}
+			handleBlogContent(log, blogStore, id, w, r)
+			return
+		case "tags":
+			setRouteTemplate(r.Context(), "/api/v1/blogs/{id}/tags")
+			if r.Method != http.MethodPut {
+				methodNotAllowed(w, r, http.MethodPut)
+				return
+			}
+			handleBlogTags(log, cfg, blogStore, id, w, r)
+			return
+		default:
 			response := ErrorResponse{Error: "Invalid blog ID"}
 			encode(w, r, http.StatusBadRequest, response)
 			return
 		}
-		id := path
 
 		switch r.Method {
 		case http.MethodGet:
-			handleBlogGet(log, blogStore, id, w, r)
+			handleBlogGet(log, cfg, blogStore, reads, id, w, r)
 		case http.MethodPut:
-			handleBlogUpdate(log, blogStore, id, w, r)
+			handleBlogUpdate(log, cfg, blogStore, id, w, r)
 		case http.MethodDelete:
-			handleBlogDelete(log, blogStore, id, w, r)
+			handleBlogDelete(log, cfg, blogStore, id, w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			methodNotAllowed(w, r, http.MethodGet, http.MethodPut, http.MethodDelete)
 		}
 	})
 }
 
-func handleBlogGet(log *logger.Logger, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+func handleBlogGet(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, reads *flightGroup[*domain.Blog], id string, w http.ResponseWriter, r *http.Request) {
+	fields, err := parseFields(r)
+	if err != nil {
+		response := ErrorResponse{
+			Error:    "Invalid fields parameter",
+			Problems: map[string]string{"fields": err.Error()},
+		}
+		encode(w, r, http.StatusBadRequest, response)
+		return
+	}
+
+	loc, ok := locationOrError(w, r, cfg)
+	if !ok {
+		return
+	}
+
+	stop := timeStore(r.Context())
+	// 共有する呼び出しは最初のリクエストの切断で他のリクエストまで失敗しないよう、キャンセルを引き継がない
+	blog, err, shared := reads.do(id, func() (*domain.Blog, error) {
+		return getByIDOrSlug(context.WithoutCancel(r.Context()), blogStore, id)
+	})
+	stop()
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			response := ErrorResponse{Error: "Blog not found"}
+			encode(w, r, http.StatusNotFound, response)
+			return
+		}
+		log.Error(r.Context(), "failed to get blog", "error", err, "id", id, "shared", shared)
+		status, response := storeErrorResponse(err, "Failed to retrieve blog")
+		encode(w, r, status, response)
+		return
+	}
+
+	// 共有した結果は他のリクエストと同じポインタのため、書き換えないようコピーを使う
+	if shared {
+		blogCopy := *blog
+		blog = &blogCopy
+	}
+
+	// If-Matchによる条件付き削除に使えるよう、版を表すETagを返す
+	w.Header().Set("ETag", blogETag(blog))
+
+	blog = inZone(blog, loc)
+	var body any = blog
+
+	// スパースフィールドセット指定時は要求されたフィールドのみ返す
+	if fields != nil {
+		selected, err := selectFields(r.Context(), blog, fields)
+		if err != nil {
+			log.Error(r.Context(), "failed to select fields", "error", err, "id", id)
+			response := ErrorResponse{Error: "Failed to retrieve blog"}
+			encode(w, r, http.StatusInternalServerError, response)
+			return
+		}
+		body = selected
+	}
+
+	// 設定またはAcceptのprofile指定で {"data": ...} に包む
+	if wantsEnvelope(r, cfg) {
+		body = dataEnvelope{Data: body}
+	}
+
+	encode(w, r, http.StatusOK, body)
+}
+
+// getByIDOrSlug looks a blog up by ID and falls back to its slug
+// IDを常に先に照合するため、スラッグが他のブログのIDと一致してもIDの方が優先される
+// スラッグでの検索はIDで見つからなかった場合のみ行い、それ以外のエラーはそのまま返す
+func getByIDOrSlug(ctx context.Context, blogStore store.BlogStore, idOrSlug string) (*domain.Blog, error) {
+	blog, err := blogStore.GetByID(ctx, idOrSlug)
+	if !errors.Is(err, store.ErrNotFound) {
+		return blog, err
+	}
+	return blogStore.GetBySlug(ctx, idOrSlug)
+}
+
+// handleBlogRevisions returns the revision history of a blog, oldest first
+// 更新履歴は作業者の名前を含むため、他のレスポンスには含めずここでのみ返す
+func handleBlogRevisions(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+	loc, ok := locationOrError(w, r, cfg)
+	if !ok {
+		return
+	}
+
+	blog, err := blogStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			response := ErrorResponse{Error: "Blog not found"}
+			encode(w, r, http.StatusNotFound, response)
+			return
+		}
+		log.Error(r.Context(), "failed to get blog revisions", "error", err, "id", id)
+		status, response := storeErrorResponse(err, "Failed to retrieve blog")
+		encode(w, r, status, response)
+		return
+	}
+
+	revisions := blog.Revisions
+	if revisions == nil {
+		revisions = []domain.BlogRevision{}
+	}
+	encode(w, r, http.StatusOK, revisionsInZone(revisions, loc))
+}
+
+// handleBlogContent serves the raw content of a blog as text/plain
+// http.ServeContentに任せ、Rangeによる部分取得（206）やIf-Range、If-Modified-Sinceに対応する
+// 大きな本文の分割取得や、中断したダウンロードの再開に使う
+func handleBlogContent(log *logger.Logger, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+	stop := timeStore(r.Context())
+	blog, err := blogStore.GetByID(r.Context(), id)
+	stop()
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			response := ErrorResponse{Error: "Blog not found"}
+			encode(w, r, http.StatusNotFound, response)
+			return
+		}
+		log.Error(r.Context(), "failed to get blog content", "error", err, "id", id)
+		status, response := storeErrorResponse(err, "Failed to retrieve blog")
+		encode(w, r, status, response)
+		return
+	}
+
+	// Content-Typeを先に設定し、ServeContentによる内容の推測を避ける
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("ETag", contentETag(blog.Content))
+	http.ServeContent(w, r, "", blog.UpdatedAt, strings.NewReader(blog.Content))
+}
+
+// handleBlogSlugRegenerate recomputes a blog's slug from its current title
+// タイトル修正後にスラッグだけを作り直すためのエンドポイント
+// 判定から保存までの間に他のリクエストが同じスラッグを取った場合は数回やり直す
+func handleBlogSlugRegenerate(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+	const maxAttempts = 3
+
+	loc, ok := locationOrError(w, r, cfg)
+	if !ok {
+		return
+	}
+
 	blog, err := blogStore.GetByID(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -122,18 +862,52 @@ func handleBlogGet(log *logger.Logger, blogStore store.BlogStore, id string, w h
 			encode(w, r, http.StatusNotFound, response)
 			return
 		}
-		log.Error(r.Context(), "failed to get blog", "error", err, "id", id)
-		response := ErrorResponse{Error: "Failed to retrieve blog"}
-		encode(w, r, http.StatusInternalServerError, response)
+		log.Error(r.Context(), "failed to get blog for slug regeneration", "error", err, "id", id)
+		status, response := storeErrorResponse(err, "Failed to retrieve blog")
+		encode(w, r, status, response)
 		return
 	}
 
-	encode(w, r, http.StatusOK, blog)
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		slug, err := uniqueSlug(r.Context(), blogStore, domain.Slugify(blog.Title), blog.ID)
+		if err == nil {
+			err = blogStore.SetSlug(r.Context(), blog.ID, slug)
+		}
+		if errors.Is(err, store.ErrSlugConflict) {
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				response := ErrorResponse{Error: "Blog not found"}
+				encode(w, r, http.StatusNotFound, response)
+				return
+			}
+			log.Error(r.Context(), "failed to regenerate slug", "error", err, "id", id)
+			status, response := storeErrorResponse(err, "Failed to regenerate slug")
+			encode(w, r, status, response)
+			return
+		}
+
+		blog.Slug = slug
+		log.Info(r.Context(), "blog slug regenerated", "id", id, "slug", slug)
+		encode(w, r, http.StatusOK, inZone(blog, loc))
+		return
+	}
+
+	response := ErrorResponse{Error: "Slug is in use, please retry"}
+	encode(w, r, http.StatusConflict, response)
 }
 
-func handleBlogUpdate(log *logger.Logger, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+func handleBlogUpdate(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+	loc, ok := locationOrError(w, r, cfg)
+	if !ok {
+		return
+	}
+
 	// First check if blog exists
+	stop := timeStore(r.Context())
 	existingBlog, err := blogStore.GetByID(r.Context(), id)
+	stop()
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			response := ErrorResponse{Error: "Blog not found"}
@@ -141,11 +915,12 @@ func handleBlogUpdate(log *logger.Logger, blogStore store.BlogStore, id string,
 			return
 		}
 		log.Error(r.Context(), "failed to get blog for update", "error", err, "id", id)
-		response := ErrorResponse{Error: "Failed to retrieve blog"}
-		encode(w, r, http.StatusInternalServerError, response)
+		status, response := storeErrorResponse(err, "Failed to retrieve blog")
+		encode(w, r, status, response)
 		return
 	}
 
+	r = withFieldLimits(r, fieldLimits(cfg))
 	req, problems, err := decodeValid[domain.UpdateBlogRequest](r)
 	if err != nil {
 		if problems != nil {
@@ -157,37 +932,417 @@ func handleBlogUpdate(log *logger.Logger, blogStore store.BlogStore, id string,
 			return
 		}
 		log.Error(r.Context(), "failed to decode update request", "error", err)
-		response := ErrorResponse{Error: "Invalid request body"}
-		encode(w, r, http.StatusBadRequest, response)
+		status, response := decodeErrorResponse(err)
+		encode(w, r, status, response)
 		return
 	}
 
 	// Update the blog
-	existingBlog.Update(req)
-	if err := blogStore.Update(r.Context(), id, existingBlog); err != nil {
+	// 認証機構がないため、更新者はX-Actorヘッダーの自己申告値を記録する
+	opts := append(blogOptions(cfg), domain.WithActor(r.Header.Get("X-Actor")))
+	currentSize := len(existingBlog.Content)
+	currentETag := blogETag(existingBlog)
+	if changed := existingBlog.Update(req, opts...); !changed && cfg.UpdateNotModified {
+		notModified(w, currentETag)
+		return
+	}
+
+	// 作者ごとの本文サイズ上限（更新では作者は変わらない）
+	err = checkAuthorBudget(r.Context(), cfg.MaxAuthorContentBytes, blogStore, existingBlog.Author, currentSize, len(existingBlog.Content))
+	if errors.Is(err, errAuthorBudgetExceeded) {
+		response := ErrorResponse{Error: "Author content budget exceeded"}
+		encode(w, r, http.StatusInsufficientStorage, response)
+		return
+	}
+	if err != nil {
+		log.Error(r.Context(), "failed to check author content budget", "error", err, "id", id)
+		status, response := storeErrorResponse(err, "Failed to update blog")
+		encode(w, r, status, response)
+		return
+	}
+
+	stop = timeStore(r.Context())
+	err = blogStore.Update(r.Context(), id, existingBlog)
+	stop()
+	if err != nil {
 		log.Error(r.Context(), "failed to update blog", "error", err, "id", id)
-		response := ErrorResponse{Error: "Failed to update blog"}
-		encode(w, r, http.StatusInternalServerError, response)
+		status, response := storeErrorResponse(err, "Failed to update blog")
+		encode(w, r, status, response)
 		return
 	}
 
 	log.Info(r.Context(), "blog updated", "id", id)
-	encode(w, r, http.StatusOK, existingBlog)
+	encode(w, r, http.StatusOK, inZone(existingBlog, loc))
+}
+
+// handleBlogTags replaces only the tags of a blog, leaving title and content untouched
+func handleBlogTags(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+	loc, ok := locationOrError(w, r, cfg)
+	if !ok {
+		return
+	}
+
+	stop := timeStore(r.Context())
+	blog, err := blogStore.GetByID(r.Context(), id)
+	stop()
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			response := ErrorResponse{Error: "Blog not found"}
+			encode(w, r, http.StatusNotFound, response)
+			return
+		}
+		log.Error(r.Context(), "failed to get blog for tag update", "error", err, "id", id)
+		status, response := storeErrorResponse(err, "Failed to retrieve blog")
+		encode(w, r, status, response)
+		return
+	}
+
+	req, problems, err := decodeValid[domain.SetTagsRequest](r)
+	if err != nil {
+		if problems != nil {
+			response := ErrorResponse{
+				Error:    "Validation failed",
+				Problems: problems,
+			}
+			encode(w, r, http.StatusBadRequest, response)
+			return
+		}
+		log.Error(r.Context(), "failed to decode tags request", "error", err)
+		status, response := decodeErrorResponse(err)
+		encode(w, r, status, response)
+		return
+	}
+
+	opts := append(blogOptions(cfg), domain.WithActor(r.Header.Get("X-Actor")))
+	currentETag := blogETag(blog)
+	if changed := blog.SetTags(req.Tags, opts...); !changed && cfg.UpdateNotModified {
+		notModified(w, currentETag)
+		return
+	}
+	stop = timeStore(r.Context())
+	err = blogStore.Update(r.Context(), id, blog)
+	stop()
+	if err != nil {
+		log.Error(r.Context(), "failed to update blog tags", "error", err, "id", id)
+		status, response := storeErrorResponse(err, "Failed to update blog")
+		encode(w, r, status, response)
+		return
+	}
+
+	log.Info(r.Context(), "blog tags updated", "id", id, "tags", blog.Tags)
+	encode(w, r, http.StatusOK, inZone(blog, loc))
 }
 
-func handleBlogDelete(log *logger.Logger, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
-	if err := blogStore.Delete(r.Context(), id); err != nil {
+// notModified answers an update that changed nothing with 304 and no body
+// 保存せずに返すため、更新日時と版は更新前のまま（etagは更新前のETag）
+func notModified(w http.ResponseWriter, etag string) {
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// handleBlogDelete removes a blog, answering 204 or, with DELETE_RESPONSE_BODY, 200 and a JSON body
+func handleBlogDelete(log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, id string, w http.ResponseWriter, r *http.Request) {
+	var err error
+	stop := timeStore(r.Context())
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		err = deleteIfMatch(r.Context(), blogStore, id, ifMatch)
+	} else {
+		err = blogStore.Delete(r.Context(), id)
+	}
+	stop()
+	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			response := ErrorResponse{Error: "Blog not found"}
 			encode(w, r, http.StatusNotFound, response)
 			return
 		}
+		// クライアントが最後に取得した後に更新されている
+		if errors.Is(err, store.ErrPreconditionFailed) {
+			response := ErrorResponse{Error: "Blog has been modified"}
+			encode(w, r, http.StatusPreconditionFailed, response)
+			return
+		}
 		log.Error(r.Context(), "failed to delete blog", "error", err, "id", id)
-		response := ErrorResponse{Error: "Failed to delete blog"}
-		encode(w, r, http.StatusInternalServerError, response)
+		status, response := storeErrorResponse(err, "Failed to delete blog")
+		encode(w, r, status, response)
 		return
 	}
 
 	log.Info(r.Context(), "blog deleted", "id", id)
+	if cfg.DeleteResponseBody {
+		encode(w, r, http.StatusOK, deleteResponse{Deleted: true, ID: id})
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// deleteIfMatch deletes the blog only if its current ETag matches the If-Match header
+// 条件付き削除に対応していないストアでは、取得と削除の間に更新が割り込む可能性が残る
+// ラップしたストアは内側が対応していない場合にerrors.ErrUnsupportedを返すため、その場合も同様に扱う
+func deleteIfMatch(ctx context.Context, blogStore store.BlogStore, id, ifMatch string) error {
+	cond := func(blog *domain.Blog) bool { return etagMatches(ifMatch, blogETag(blog)) }
+	if deleter, ok := blogStore.(store.ConditionalDeleter); ok {
+		if err := deleter.DeleteIf(ctx, id, cond); !errors.Is(err, errors.ErrUnsupported) {
+			return err
+		}
+	}
+	blog, err := blogStore.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !cond(blog) {
+		return store.ErrPreconditionFailed
+	}
+	return blogStore.Delete(ctx, id)
+}
+
+// storeErrorResponse maps an unexpected store error to a status and error body
+// タイムアウトやキャンセルでコンテキストが終了した場合はサーバーの不具合ではないため、
+// 500ではなく503を返してクライアントが再試行できることを伝える
+func storeErrorResponse(err error, message string) (int, ErrorResponse) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable, ErrorResponse{Error: "Request timed out"}
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, ErrorResponse{Error: "Request canceled"}
+	default:
+		return http.StatusInternalServerError, ErrorResponse{Error: message}
+	}
+}
+
+// blogOptions translates the configuration into domain options for NewBlog/Update
+func blogOptions(cfg *config.Config) []domain.Option {
+	return []domain.Option{
+		domain.WithContentNormalization(cfg.NormalizeContent),
+		domain.WithContentTrimming(cfg.TrimContent),
+		domain.WithMaxRevisions(cfg.MaxRevisions),
+		domain.WithAuthorNormalization(authorNormalization(cfg)),
+		domain.WithTTL(cfg.BlogTTL),
+		domain.WithIDGenerator(idGenerator(cfg)),
+	}
+}
+
+// authSubject returns the authenticated subject that AUTHOR_ID_HEADER carries, if any
+// このサーバー自身は認証しないため、ヘッダーは前段の認証ゲートウェイが設定し、クライアントからの値を上書きすること
+func authSubject(r *http.Request, cfg *config.Config) string {
+	if cfg.AuthorIDHeader == "" {
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get(cfg.AuthorIDHeader))
+}
+
+// idGenerator returns the ID generator for the configured ID_STRATEGY
+func idGenerator(cfg *config.Config) domain.IDGenerator {
+	if cfg.IDStrategy == "ulid" {
+		return domain.NewULID
+	}
+	return domain.NewUUID
+}
+
+// authorNormalization returns the configured author canonicalization
+func authorNormalization(cfg *config.Config) domain.AuthorNormalization {
+	return domain.AuthorNormalization{
+		CollapseSpace: cfg.NormalizeAuthor,
+		TitleCase:     cfg.AuthorTitleCase,
+	}
+}
+
+// authorDefaultTags returns the AUTHOR_DEFAULT_TAGS configured for author
+// 保存される作者名は正規化済みのため、設定側の作者名にも同じ正規化をかけて比較する
+func authorDefaultTags(cfg *config.Config, author string) []string {
+	normalization := authorNormalization(cfg)
+	for name, tags := range cfg.AuthorDefaultTags {
+		if normalization.Apply(name) == author {
+			return tags
+		}
+	}
+	return nil
+}
+
+// createBlog builds a blog from req and stores it
+// 単体作成とNDJSONでの一括作成で共通の処理（既定タグ、スラッグの決定）
+// 本文の重複投稿はストアのCreateがErrDuplicateContentで拒否する（DEDUPLICATE_CONTENT）
+func createBlog(ctx context.Context, cfg *config.Config, blogStore store.BlogStore, req domain.CreateBlogRequest, subject string) (*domain.Blog, error) {
+	blog := domain.NewBlog(req, append(blogOptions(cfg), domain.WithAuthorID(subject))...)
+	blog.Tags = domain.MergeTags(blog.Tags, authorDefaultTags(cfg, blog.Author))
+
+	if err := checkAuthorBudget(ctx, cfg.MaxAuthorContentBytes, blogStore, blog.Author, 0, len(blog.Content)); err != nil {
+		return nil, err
+	}
+
+	// 他の投稿とスラッグが衝突する場合は連番を付ける
+	// 判定から保存までの間に同時の作成が同じスラッグを取ると、ストアがErrSlugConflictを返すのでやり直す
+	// 同時に作成する他のリクエストはそれぞれ一度しか勝たないため、並行数が上限を超えない限り成功する
+	const maxSlugAttempts = 10
+	base := blog.Slug
+	var err error
+	for attempt := 0; attempt < maxSlugAttempts; attempt++ {
+		blog.Slug, err = uniqueSlug(ctx, blogStore, base, blog.ID)
+		if err != nil {
+			return nil, fmt.Errorf("resolve slug: %w", err)
+		}
+		if err = blogStore.Create(ctx, blog); !errors.Is(err, store.ErrSlugConflict) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return blog, nil
+}
+
+// warnDuplicateTitle is the warning returned when the author already has a post with the same title
+const warnDuplicateTitle = "an existing post by this author has the same title"
+
+// createWarnings returns the non-fatal warnings about a blog that was just created
+// 警告の確認に失敗しても作成は成功しているため、ログに残して警告なしとする
+func createWarnings(ctx context.Context, log *logger.Logger, cfg *config.Config, blogStore store.BlogStore, blog *domain.Blog) []string {
+	if !cfg.WarnDuplicateTitles {
+		return nil
+	}
+	blogs, err := blogStore.GetByAuthor(ctx, blog.Author)
+	if err != nil {
+		log.Warn(ctx, "failed to check for duplicate titles", "error", err, "id", blog.ID)
+		return nil
+	}
+	for _, other := range blogs {
+		// 作成したばかりの投稿自身は除く。大文字小文字の違いだけのタイトルも重複とみなす
+		if other.ID != blog.ID && strings.EqualFold(other.Title, blog.Title) {
+			return []string{warnDuplicateTitle}
+		}
+	}
+	return nil
+}
+
+// createErrorResponse maps an error from createBlog to a status and body
+func createErrorResponse(r *http.Request, err error) (int, ErrorResponse) {
+	switch {
+	case errors.Is(err, store.ErrDuplicateContent):
+		return http.StatusConflict, ErrorResponse{Error: "Duplicate blog post"}
+	case errors.Is(err, store.ErrSlugConflict):
+		return http.StatusConflict, ErrorResponse{Error: "Slug is in use, please retry"}
+	case errors.Is(err, store.ErrAlreadyExists):
+		// If-None-Match: * は「存在しない場合のみ作成」を意味する条件付きリクエスト
+		if r.Header.Get("If-None-Match") == "*" {
+			return http.StatusPreconditionFailed, ErrorResponse{Error: "Blog already exists"}
+		}
+		return http.StatusConflict, ErrorResponse{Error: "Blog already exists"}
+	case errors.Is(err, store.ErrCapacityExceeded):
+		return http.StatusInsufficientStorage, ErrorResponse{Error: "Blog storage is full"}
+	case errors.Is(err, errAuthorBudgetExceeded):
+		return http.StatusInsufficientStorage, ErrorResponse{Error: "Author content budget exceeded"}
+	default:
+		return storeErrorResponse(err, "Failed to create blog")
+	}
+}
+
+// uniqueSlug returns base, or base with a numeric suffix, that no blog other
+// than id is using
+func uniqueSlug(ctx context.Context, blogStore store.BlogStore, base, id string) (string, error) {
+	slug := base
+	for n := 2; ; n++ {
+		existing, err := blogStore.GetBySlug(ctx, slug)
+		if errors.Is(err, store.ErrNotFound) {
+			return slug, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if existing.ID == id {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// filterBlogs returns the blogs for which keep reports true
+func filterBlogs(blogs []*domain.Blog, keep func(blog *domain.Blog) bool) []*domain.Blog {
+	filtered := make([]*domain.Blog, 0, len(blogs))
+	for _, blog := range blogs {
+		if keep(blog) {
+			filtered = append(filtered, blog)
+		}
+	}
+	return filtered
+}
+
+// filterByTags returns the blogs carrying every tag in tags, or any of them if matchAny is set
+func filterByTags(blogs []*domain.Blog, tags []string, matchAny bool) []*domain.Blog {
+	filtered := make([]*domain.Blog, 0, len(blogs))
+	for _, blog := range blogs {
+		if hasTags(blog, tags, matchAny) {
+			filtered = append(filtered, blog)
+		}
+	}
+	return filtered
+}
+
+// hasTags reports whether blog carries every tag in tags, or any of them if matchAny is set
+// tagsはNormalizeTags済みであること（保存済みのタグも正規化されている）
+func hasTags(blog *domain.Blog, tags []string, matchAny bool) bool {
+	matched := 0
+	for _, tag := range tags {
+		if slices.Contains(blog.Tags, tag) {
+			matched++
+		}
+	}
+	return (matchAny && matched > 0) || matched == len(tags)
+}
+
+// validationConfig translates the configuration into the domain validation rules
+func validationConfig(cfg *config.Config) domain.ValidationConfig {
+	return domain.ValidationConfig{
+		MaxTitleLen:   cfg.MaxTitleLen,
+		MaxContentLen: cfg.MaxContentLen,
+		MaxAuthorLen:  cfg.MaxAuthorLen,
+
+		AllowedAuthors: cfg.AuthorAllowlist,
+		DeniedAuthors:  cfg.AuthorDenylist,
+
+		AllowedCategories: cfg.AllowedCategories,
+		RequireCategory:   cfg.RequireCategory,
+
+		AuthorNormalization: authorNormalization(cfg),
+
+		MaxProblems: cfg.MaxValidationProblems,
+	}
+}
+
+// decodeErrorResponse returns the status and body for a request body decode failure
+func decodeErrorResponse(err error) (int, ErrorResponse) {
+	var maxBytesErr *http.MaxBytesError
+	var fieldErr *fieldTooLargeError
+	var limitErr *jsonLimitError
+	var utf8Err *invalidUTF8Error
+	var recordsErr *tooManyRecordsError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Request body too large"}
+	case errors.As(err, &recordsErr):
+		return http.StatusRequestEntityTooLarge, ErrorResponse{
+			Error:    "Too many records",
+			Problems: map[string]string{"body": fmt.Sprintf("body must not have more than %d records", recordsErr.Max)},
+		}
+	case errors.As(err, &fieldErr):
+		return http.StatusRequestEntityTooLarge, ErrorResponse{
+			Error:    "Request field too large",
+			Problems: map[string]string{fieldErr.Field: fmt.Sprintf("%s must not exceed %d bytes", fieldErr.Field, fieldErr.Limit)},
+		}
+	case errors.As(err, &limitErr):
+		return http.StatusBadRequest, ErrorResponse{
+			Error:    "Request body too complex",
+			Code:     "json_limit",
+			Problems: map[string]string{"body": limitErr.Reason},
+		}
+	case errors.As(err, &utf8Err):
+		return http.StatusBadRequest, ErrorResponse{Error: "Validation failed", Code: "invalid_utf8", Problems: utf8Err.Problems}
+	case errors.Is(err, errInvalidGzip):
+		return http.StatusBadRequest, ErrorResponse{Error: "Invalid gzip request body"}
+	case errors.Is(err, errEmptyBody):
+		return http.StatusBadRequest, ErrorResponse{Error: "Request body is empty", Code: "empty_body"}
+	case errors.Is(err, errTrailingData):
+		return http.StatusBadRequest, ErrorResponse{Error: "Unexpected data after JSON body", Code: "trailing_data"}
+	default:
+		return http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"}
+	}
+}
@@ -2,25 +2,31 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
 	"github.com/moko-poi/blog-api-server/internal/logger"
 	"github.com/moko-poi/blog-api-server/internal/store"
-	"github.com/moko-poi/blog-api-server/internal/domain"
 )
 
 func TestHandleHealthz(t *testing.T) {
 	log := logger.New(io.Discard, slog.LevelError)
-	handler := handleHealthz(log)
+	handler := handleHealthz(log, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 	w := httptest.NewRecorder()
@@ -49,7 +55,7 @@ func TestHandleHealthz(t *testing.T) {
 func TestHandleBlogsCreate(t *testing.T) {
 	log := logger.New(io.Discard, slog.LevelError)
 	blogStore := store.NewMemoryBlogStore()
-	handler := handleBlogsCreate(log, blogStore)
+	handler := handleBlogsCreate(log, &config.Config{}, blogStore, nil)
 
 	tests := []struct {
 		name           string
@@ -124,6 +130,9 @@ func TestHandleBlogsCreate(t *testing.T) {
 				if blog.CreatedAt.IsZero() {
 					t.Error("expected CreatedAt to be set")
 				}
+				if bytes.Contains(body, []byte("content_hash")) {
+					t.Errorf("expected the content hash to be kept out of the response, got %s", body)
+				}
 			},
 		},
 	}
@@ -158,7 +167,7 @@ func TestHandleBlogsCreate(t *testing.T) {
 func TestHandleBlogsGet(t *testing.T) {
 	log := logger.New(io.Discard, slog.LevelError)
 	blogStore := store.NewMemoryBlogStore()
-	handler := handleBlogsGet(log, blogStore)
+	handler := handleBlogsGet(log, &config.Config{}, blogStore)
 
 	// Add test data
 	blog1 := &domain.Blog{
@@ -275,7 +284,7 @@ func TestHandleBlogsGet(t *testing.T) {
 func TestHandleBlogsByID(t *testing.T) {
 	log := logger.New(io.Discard, slog.LevelError)
 	blogStore := store.NewMemoryBlogStore()
-	handler := handleBlogsByID(log, blogStore)
+	handler := handleBlogsByID(log, &config.Config{}, blogStore)
 
 	// Add test blog
 	blog := &domain.Blog{
@@ -393,6 +402,20 @@ func TestHandleBlogsByID(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "update with explicit null title",
+			method:         http.MethodPut,
+			path:           "/api/v1/blogs/test-id",
+			body:           json.RawMessage(`{"title":null}`),
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				var resp ErrorResponse
+				json.Unmarshal(body, &resp)
+				if resp.Problems["title"] != "title cannot be null" {
+					t.Errorf("expected 'title cannot be null', got %q", resp.Problems["title"])
+				}
+			},
+		},
 		{
 			name:           "update non-existent blog",
 			method:         http.MethodPut,
@@ -446,12 +469,17 @@ func TestHandleBlogsByID(t *testing.T) {
 
 // Mock store for testing error conditions
 type mockBlogStore struct {
-	createError    error
-	getAllError    error
-	getByIDError   error
-	getByAuthorError error
-	updateError    error
-	deleteError    error
+	createError        error
+	getAllError        error
+	getByIDError       error
+	getByAuthorError   error
+	getByCategoryError error
+	getBySlugError     error
+	setSlugError       error
+	recentError        error
+	updateError        error
+	deleteError        error
+	statsError         error
 }
 
 func (m *mockBlogStore) Create(ctx context.Context, blog *domain.Blog) error {
@@ -470,6 +498,25 @@ func (m *mockBlogStore) GetByAuthor(ctx context.Context, author string) ([]*doma
 	return nil, m.getByAuthorError
 }
 
+func (m *mockBlogStore) GetByCategory(ctx context.Context, category string) ([]*domain.Blog, error) {
+	return nil, m.getByCategoryError
+}
+
+func (m *mockBlogStore) GetBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	if m.getBySlugError != nil {
+		return nil, m.getBySlugError
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *mockBlogStore) SetSlug(ctx context.Context, id, slug string) error {
+	return m.setSlugError
+}
+
+func (m *mockBlogStore) Recent(ctx context.Context, n int) ([]*domain.Blog, error) {
+	return nil, m.recentError
+}
+
 func (m *mockBlogStore) Update(ctx context.Context, id string, blog *domain.Blog) error {
 	return m.updateError
 }
@@ -478,54 +525,2724 @@ func (m *mockBlogStore) Delete(ctx context.Context, id string) error {
 	return m.deleteError
 }
 
-func TestHandleBlogsCreate_StoreError(t *testing.T) {
+func (m *mockBlogStore) Stats(ctx context.Context) (domain.BlogStats, error) {
+	return domain.BlogStats{}, m.statsError
+}
+
+func TestHandleBlogsByID_Envelope(t *testing.T) {
 	log := logger.New(io.Discard, slog.LevelError)
-	mockStore := &mockBlogStore{
-		createError: errors.New("store error"),
+	blogStore := store.NewMemoryBlogStore()
+	blog := domain.NewBlog(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+	blogStore.Create(context.Background(), blog)
+
+	tests := []struct {
+		name         string
+		cfg          *config.Config
+		accept       string
+		query        string
+		expectedWrap bool
+	}{
+		{
+			name:         "bare object by default",
+			cfg:          &config.Config{},
+			expectedWrap: false,
+		},
+		{
+			name:         "wrapped when configured",
+			cfg:          &config.Config{ResponseEnvelope: true},
+			expectedWrap: true,
+		},
+		{
+			name:         "wrapped via Accept profile",
+			cfg:          &config.Config{},
+			accept:       `application/json; profile="envelope"`,
+			expectedWrap: true,
+		},
+		{
+			name:         "sparse fields are wrapped too",
+			cfg:          &config.Config{ResponseEnvelope: true},
+			query:        "?fields=id,title",
+			expectedWrap: true,
+		},
 	}
-	handler := handleBlogsCreate(log, mockStore)
 
-	reqBody := domain.CreateBlogRequest{
-		Title:   "Test Title",
-		Content: "Test Content",
-		Author:  "Test Author",
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleBlogsByID(log, tt.cfg, blogStore)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+blog.ID+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			data, wrapped := body["data"]
+			if wrapped != tt.expectedWrap {
+				t.Fatalf("expected wrapped=%v, got body %s", tt.expectedWrap, w.Body.String())
+			}
+			if wrapped {
+				if err := json.Unmarshal(data, &body); err != nil {
+					t.Fatalf("failed to unmarshal data: %v", err)
+				}
+			}
+			var id string
+			json.Unmarshal(body["id"], &id)
+			if id != blog.ID {
+				t.Errorf("expected id %q, got %q", blog.ID, id)
+			}
+		})
 	}
-	body, _ := json.Marshal(reqBody)
+}
 
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", bytes.NewReader(body))
-	w := httptest.NewRecorder()
+func TestHandleBlogsByID_IDOrSlug(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	handler := handleBlogsByID(log, &config.Config{}, blogStore)
+	ctx := context.Background()
 
-	handler.ServeHTTP(w, req)
+	blog := domain.NewBlog(domain.CreateBlogRequest{Title: "Hello World", Content: "C", Author: "A"})
+	blogStore.Create(ctx, blog)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	// スラッグが別のブログのIDと同じでも、IDでの照合が優先される
+	shadow := domain.NewBlog(domain.CreateBlogRequest{Title: "Shadow", Content: "C", Author: "B"})
+	blogStore.Create(ctx, shadow)
+	blogStore.SetSlug(ctx, shadow.ID, blog.ID)
+
+	tests := []struct {
+		name           string
+		idOrSlug       string
+		expectedStatus int
+		expectedID     string
+	}{
+		{
+			name:           "by UUID",
+			idOrSlug:       blog.ID,
+			expectedStatus: http.StatusOK,
+			expectedID:     blog.ID,
+		},
+		{
+			name:           "by slug",
+			idOrSlug:       "hello-world",
+			expectedStatus: http.StatusOK,
+			expectedID:     blog.ID,
+		},
+		{
+			name:           "neither matches",
+			idOrSlug:       "no-such-blog",
+			expectedStatus: http.StatusNotFound,
+		},
 	}
 
-	var resp ErrorResponse
-	json.Unmarshal(w.Body.Bytes(), &resp)
-	if resp.Error != "Failed to create blog" {
-		t.Errorf("expected error 'Failed to create blog', got %q", resp.Error)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+tt.idOrSlug, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedID == "" {
+				return
+			}
+			var got domain.Blog
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if got.ID != tt.expectedID {
+				t.Errorf("expected blog %s, got %s", tt.expectedID, got.ID)
+			}
+		})
 	}
 }
 
-func TestHandleBlogsGet_StoreError(t *testing.T) {
+func TestHandleBlogsByID_Tags(t *testing.T) {
 	log := logger.New(io.Discard, slog.LevelError)
-	mockStore := &mockBlogStore{
-		getAllError: errors.New("store error"),
+	blogStore := store.NewMemoryBlogStore()
+	handler := handleBlogsByID(log, &config.Config{}, blogStore)
+	ctx := context.Background()
+
+	blog := domain.NewBlog(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author", Tags: []string{"old"}})
+	blog.UpdatedAt = blog.UpdatedAt.Add(-time.Hour)
+	blogStore.Create(ctx, blog)
+
+	tests := []struct {
+		name           string
+		method         string
+		id             string
+		body           string
+		expectedStatus int
+		expectedTags   []string
+	}{
+		{
+			name:           "replace tags",
+			method:         http.MethodPut,
+			id:             blog.ID,
+			body:           `{"tags":["Go","api","go"]}`,
+			expectedStatus: http.StatusOK,
+			expectedTags:   []string{"go", "api"},
+		},
+		{
+			name:           "invalid tag",
+			method:         http.MethodPut,
+			id:             blog.ID,
+			body:           `{"tags":["not valid"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedTags:   []string{"go", "api"},
+		},
+		{
+			name:           "clear tags",
+			method:         http.MethodPut,
+			id:             blog.ID,
+			body:           `{"tags":[]}`,
+			expectedStatus: http.StatusOK,
+			expectedTags:   []string{},
+		},
+		{
+			name:           "unknown blog",
+			method:         http.MethodPut,
+			id:             "missing",
+			body:           `{"tags":["go"]}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodPost,
+			id:             blog.ID,
+			body:           `{"tags":["go"]}`,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
 	}
-	handler := handleBlogsGet(log, mockStore)
 
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
-	w := httptest.NewRecorder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/blogs/"+tt.id+"/tags", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
 
-	handler.ServeHTTP(w, req)
+			handler.ServeHTTP(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedTags == nil {
+				return
+			}
+
+			stored, err := blogStore.GetByID(ctx, blog.ID)
+			if err != nil {
+				t.Fatalf("failed to get blog: %v", err)
+			}
+			if !slices.Equal(stored.Tags, tt.expectedTags) {
+				t.Errorf("expected tags %v, got %v", tt.expectedTags, stored.Tags)
+			}
+			if stored.Title != blog.Title || stored.Content != blog.Content {
+				t.Errorf("expected title and content unchanged, got %q %q", stored.Title, stored.Content)
+			}
+			if !stored.UpdatedAt.After(blog.UpdatedAt) {
+				t.Error("expected UpdatedAt to advance")
+			}
+		})
 	}
 }
 
-// Helper function to create string pointer
-func stringPtr(s string) *string {
-	return &s
-}
\ No newline at end of file
+func TestHandleBlogsByID_UpdateNotModified(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	ctx := context.Background()
+
+	blog := domain.NewBlog(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author", Tags: []string{"go"}})
+	blog.UpdatedAt = blog.UpdatedAt.Add(-time.Hour)
+	blogStore.Create(ctx, blog)
+
+	// テストは順に実行され、前のテストの更新結果を引き継ぐ
+	tests := []struct {
+		name           string
+		cfg            *config.Config
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "real change", cfg: &config.Config{UpdateNotModified: true}, body: `{"title":"New Title"}`, expectedStatus: http.StatusOK},
+		{name: "same values", cfg: &config.Config{UpdateNotModified: true}, body: `{"title":"  New Title  ","content":"Content"}`, expectedStatus: http.StatusNotModified},
+		{name: "empty update", cfg: &config.Config{UpdateNotModified: true}, body: `{}`, expectedStatus: http.StatusNotModified},
+		{name: "same tags", cfg: &config.Config{UpdateNotModified: true}, path: "/tags", body: `{"tags":["Go"]}`, expectedStatus: http.StatusNotModified},
+		{name: "changed tags", cfg: &config.Config{UpdateNotModified: true}, path: "/tags", body: `{"tags":["go","api"]}`, expectedStatus: http.StatusOK},
+		{name: "disabled", cfg: &config.Config{}, body: `{"title":"New Title"}`, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := blogStore.GetByID(ctx, blog.ID)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/blogs/"+blog.ID+tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handleBlogsByID(log, tt.cfg, blogStore).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			after, _ := blogStore.GetByID(ctx, blog.ID)
+			if tt.expectedStatus == http.StatusNotModified {
+				if w.Body.Len() != 0 {
+					t.Errorf("expected no body, got %q", w.Body.String())
+				}
+				if etag := w.Header().Get("ETag"); etag != blogETag(before) {
+					t.Errorf("expected the current ETag %q, got %q", blogETag(before), etag)
+				}
+				// 保存しないため更新日時は進まない
+				if !after.UpdatedAt.Equal(before.UpdatedAt) {
+					t.Errorf("expected updated_at to stay %v, got %v", before.UpdatedAt, after.UpdatedAt)
+				}
+				return
+			}
+			if !after.UpdatedAt.After(before.UpdatedAt) {
+				t.Errorf("expected updated_at to move past %v, got %v", before.UpdatedAt, after.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsByID_DeleteResponseBody(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	tests := []struct {
+		name       string
+		body       bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "default 204 without body", wantStatus: http.StatusNoContent},
+		{name: "200 with body", body: true, wantStatus: http.StatusOK, wantBody: `{"deleted":true,"id":"blog-1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blogStore := store.NewMemoryBlogStore()
+			blogStore.Create(context.Background(), &domain.Blog{ID: "blog-1", Title: "Title"})
+			handler := handleBlogsByID(log, &config.Config{DeleteResponseBody: tt.body}, blogStore)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/blogs/blog-1", nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, got)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsByID_DeleteIfMatch(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	tests := []struct {
+		name       string
+		wrap       func(store.BlogStore) store.BlogStore
+		stale      bool
+		ifMatch    string
+		wantStatus int
+	}{
+		{name: "matching ETag", wantStatus: http.StatusNoContent},
+		{name: "stale ETag", stale: true, wantStatus: http.StatusPreconditionFailed},
+		{name: "wildcard", ifMatch: "*", stale: true, wantStatus: http.StatusNoContent},
+		{
+			name:       "stale ETag without conditional delete support",
+			wrap:       func(s store.BlogStore) store.BlogStore { return sliceOnlyStore{BlogStore: s} },
+			stale:      true,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "stale ETag through an encrypted store",
+			wrap:       encryptedStore,
+			stale:      true,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "matching ETag through an encrypted store",
+			wrap:       encryptedStore,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			// Primaryが条件付き削除に対応していない場合は、取得してから削除する
+			name: "stale ETag through a replicated store without conditional delete support",
+			wrap: func(s store.BlogStore) store.BlogStore {
+				return &store.ReplicatedBlogStore{Primary: sliceOnlyStore{BlogStore: s}, Replica: s}
+			},
+			stale:      true,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memStore := store.NewMemoryBlogStore()
+			var blogStore store.BlogStore = memStore
+			if tt.wrap != nil {
+				blogStore = tt.wrap(memStore)
+			}
+			handler := handleBlogsByID(log, &config.Config{}, blogStore)
+			blog := domain.NewBlog(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+			memStore.Create(context.Background(), blog)
+
+			get := httptest.NewRecorder()
+			handler.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+blog.ID, nil))
+			etag := get.Header().Get("ETag")
+			if etag == "" {
+				t.Fatal("expected GET to return an ETag")
+			}
+
+			// 取得後に別のクライアントが更新した状態を作る
+			if tt.stale {
+				updated := *blog
+				updated.Title = "Changed elsewhere"
+				updated.UpdatedAt = blog.UpdatedAt.Add(time.Second)
+				memStore.Update(context.Background(), blog.ID, &updated)
+			}
+
+			ifMatch := etag
+			if tt.ifMatch != "" {
+				ifMatch = tt.ifMatch
+			}
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/blogs/"+blog.ID, nil)
+			req.Header.Set("If-Match", ifMatch)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			_, err := memStore.GetByID(context.Background(), blog.ID)
+			if deleted := errors.Is(err, store.ErrNotFound); deleted != (tt.wantStatus == http.StatusNoContent) {
+				t.Errorf("expected deleted=%v, got %v", tt.wantStatus == http.StatusNoContent, deleted)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsByID_SlugRegenerate(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	handler := handleBlogsByID(log, &config.Config{}, blogStore)
+	ctx := context.Background()
+
+	// タイトル修正済みだがスラッグは古いままの投稿
+	fixed := domain.NewBlog(domain.CreateBlogRequest{Title: "Helo Wrld", Content: "C", Author: "A"})
+	fixed.Title = "Hello World"
+	blogStore.Create(ctx, fixed)
+
+	// 既に hello-world を使っている別の投稿
+	taken := domain.NewBlog(domain.CreateBlogRequest{Title: "Hello World", Content: "C", Author: "B"})
+	collides := domain.NewBlog(domain.CreateBlogRequest{Title: "Other", Content: "C", Author: "B"})
+	collides.Title = "Hello World"
+	blogStore.Create(ctx, taken)
+	blogStore.Create(ctx, collides)
+
+	tests := []struct {
+		name           string
+		method         string
+		id             string
+		expectedStatus int
+		expectedSlug   string
+	}{
+		{
+			name:           "slug recomputed from current title",
+			method:         http.MethodPost,
+			id:             taken.ID,
+			expectedStatus: http.StatusOK,
+			expectedSlug:   "hello-world",
+		},
+		{
+			name:           "collision gets a suffix",
+			method:         http.MethodPost,
+			id:             fixed.ID,
+			expectedStatus: http.StatusOK,
+			expectedSlug:   "hello-world-2",
+		},
+		{
+			name:           "next collision gets the next suffix",
+			method:         http.MethodPost,
+			id:             collides.ID,
+			expectedStatus: http.StatusOK,
+			expectedSlug:   "hello-world-3",
+		},
+		{
+			name:           "regenerating again keeps the slug",
+			method:         http.MethodPost,
+			id:             fixed.ID,
+			expectedStatus: http.StatusOK,
+			expectedSlug:   "hello-world-2",
+		},
+		{
+			name:           "unknown blog",
+			method:         http.MethodPost,
+			id:             "non-existent",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			id:             fixed.ID,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/blogs/"+tt.id+"/slug/regenerate", nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedSlug == "" {
+				return
+			}
+
+			var blog domain.Blog
+			if err := json.Unmarshal(w.Body.Bytes(), &blog); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if blog.Slug != tt.expectedSlug {
+				t.Errorf("expected slug %q, got %q", tt.expectedSlug, blog.Slug)
+			}
+			stored, _ := blogStore.GetByID(ctx, tt.id)
+			if stored.Slug != tt.expectedSlug {
+				t.Errorf("expected stored slug %q, got %q", tt.expectedSlug, stored.Slug)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsByID_Content(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	content := strings.Repeat("0123456789", 100)
+	blogStore.Create(context.Background(), &domain.Blog{ID: "1", Title: "Title", Content: content, CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	// gzipを通しても範囲が元の本文のバイト位置を指すこと
+	handler := gzipMiddleware(5)(handleBlogsByID(log, &config.Config{}, blogStore))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		rangeHeader    string
+		ifRange        func(etag string) string
+		expectedStatus int
+		wantBody       string
+		wantRange      string
+	}{
+		{
+			name:           "full content",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/1/content",
+			expectedStatus: http.StatusOK,
+			wantBody:       content,
+		},
+		{
+			name:           "byte range",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/1/content",
+			rangeHeader:    "bytes=10-24",
+			expectedStatus: http.StatusPartialContent,
+			wantBody:       content[10:25],
+			wantRange:      "bytes 10-24/1000",
+		},
+		{
+			name:           "suffix range",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/1/content",
+			rangeHeader:    "bytes=-5",
+			expectedStatus: http.StatusPartialContent,
+			wantBody:       content[995:],
+			wantRange:      "bytes 995-999/1000",
+		},
+		{
+			name:           "resume with matching If-Range",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/1/content",
+			rangeHeader:    "bytes=500-",
+			ifRange:        func(etag string) string { return etag },
+			expectedStatus: http.StatusPartialContent,
+			wantBody:       content[500:],
+			wantRange:      "bytes 500-999/1000",
+		},
+		{
+			name:           "stale If-Range returns the whole content",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/1/content",
+			rangeHeader:    "bytes=500-",
+			ifRange:        func(string) string { return `"stale"` },
+			expectedStatus: http.StatusOK,
+			wantBody:       content,
+		},
+		{
+			name:           "unsatisfiable range",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/1/content",
+			rangeHeader:    "bytes=2000-",
+			expectedStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:           "not found",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/missing/content",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodPut,
+			path:           "/api/v1/blogs/1/content",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+			if tt.ifRange != nil {
+				req.Header.Set("If-Range", tt.ifRange(contentETag(content)))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			if encoding := w.Header().Get("Content-Encoding"); encoding != "" {
+				t.Errorf("expected an uncompressed body, got Content-Encoding %q", encoding)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, got)
+			}
+			if got := w.Header().Get("Content-Range"); got != tt.wantRange {
+				t.Errorf("expected Content-Range %q, got %q", tt.wantRange, got)
+			}
+			if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+				t.Errorf("expected Accept-Ranges bytes, got %q", got)
+			}
+			if got := w.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+				t.Errorf("expected text/plain, got %q", got)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsByID_Revisions(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	handler := handleBlogsByID(log, &config.Config{}, blogStore)
+
+	blog := domain.NewBlog(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+	blogStore.Create(context.Background(), blog)
+
+	// No revisions yet
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+blog.ID+"/revisions", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("expected empty revisions, got %s", w.Body.String())
+	}
+
+	// Title-only update
+	body, _ := json.Marshal(domain.UpdateBlogRequest{Title: stringPtr("New Title")})
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/blogs/"+blog.ID, bytes.NewReader(body))
+	req.Header.Set("X-Actor", "editor")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	// 履歴は/revisions以外のレスポンスには含めない
+	if strings.Contains(w.Body.String(), "revisions") || strings.Contains(w.Body.String(), "editor") {
+		t.Errorf("expected the update response without revisions, got %s", w.Body.String())
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+blog.ID, nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if strings.Contains(w.Body.String(), "revisions") {
+		t.Errorf("expected the blog response without revisions, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+blog.ID+"/revisions", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var revisions []domain.BlogRevision
+	if err := json.Unmarshal(w.Body.Bytes(), &revisions); err != nil {
+		t.Fatalf("failed to unmarshal revisions: %v", err)
+	}
+	if len(revisions) != 1 {
+		t.Fatalf("expected 1 revision, got %d", len(revisions))
+	}
+	if len(revisions[0].ChangedFields) != 1 || revisions[0].ChangedFields[0] != "title" {
+		t.Errorf("expected changed fields [title], got %v", revisions[0].ChangedFields)
+	}
+	if revisions[0].Actor != "editor" {
+		t.Errorf("expected actor 'editor', got %q", revisions[0].Actor)
+	}
+
+	t.Run("time zone", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+blog.ID+"/revisions?tz=Asia/Tokyo", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "+09:00") {
+			t.Errorf("expected changed_at in Asia/Tokyo, got %s", w.Body.String())
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+blog.ID+"/revisions?tz=Nowhere/City", nil)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d for an unknown tz, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("non-existent blog", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/non-existent/revisions", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("wrong method", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs/"+blog.ID+"/revisions", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+		}
+	})
+}
+
+func TestHandleBlogs_SparseFieldsets(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	listHandler := handleBlogsGet(log, &config.Config{}, blogStore)
+	itemHandler := handleBlogsByID(log, &config.Config{}, blogStore)
+
+	blogStore.Create(context.Background(), &domain.Blog{
+		ID:        "test-id",
+		Title:     "Test Blog",
+		Content:   "Test Content",
+		Author:    "Test Author",
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	})
+
+	assertKeys := func(t *testing.T, got map[string]any, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("expected keys %v, got %v", want, got)
+		}
+		for _, key := range want {
+			if _, ok := got[key]; !ok {
+				t.Errorf("expected key %q in %v", key, got)
+			}
+		}
+	}
+
+	t.Run("list with subset", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs?fields=id,title,author", nil)
+		w := httptest.NewRecorder()
+		listHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		var blogs []map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &blogs); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if len(blogs) != 1 {
+			t.Fatalf("expected 1 blog, got %d", len(blogs))
+		}
+		assertKeys(t, blogs[0], "id", "title", "author")
+	})
+
+	t.Run("single with subset", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/test-id?fields=title,%20created_at", nil)
+		w := httptest.NewRecorder()
+		itemHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		var blog map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &blog); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		assertKeys(t, blog, "title", "created_at")
+		if blog["title"] != "Test Blog" {
+			t.Errorf("expected title 'Test Blog', got %v", blog["title"])
+		}
+	})
+
+	t.Run("camel case output", func(t *testing.T) {
+		handler := fieldCaseMiddleware(fieldCaseCamel)(itemHandler)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/test-id?fields=id,createdAt", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		var blog map[string]any
+		json.Unmarshal(w.Body.Bytes(), &blog)
+		assertKeys(t, blog, "id", "createdAt")
+	})
+
+	t.Run("unknown field on list", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs?fields=id,password", nil)
+		w := httptest.NewRecorder()
+		listHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if !strings.Contains(resp.Problems["fields"], "password") {
+			t.Errorf("expected problem naming the unknown field, got %v", resp.Problems)
+		}
+	})
+
+	t.Run("unknown field on single", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/test-id?fields=nope", nil)
+		w := httptest.NewRecorder()
+		itemHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
+
+func TestHandleBlogsCreate_CamelCase(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := fieldCaseMiddleware(fieldCaseCamel)(handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil))
+
+	body := `{"title":"Title","content":"Content","author":"Author"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var blog map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &blog); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if blog["title"] != "Title" {
+		t.Errorf("expected title at the top level, got %v", blog)
+	}
+	if _, ok := blog["createdAt"]; !ok {
+		t.Errorf("expected createdAt key, got %v", blog)
+	}
+}
+
+func TestHandleBlogsCreate_EmptyBody(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil)
+
+	tests := []struct {
+		name            string
+		body            string
+		expectedMessage string
+		expectedCode    string
+	}{
+		{
+			name:            "empty body",
+			body:            "",
+			expectedMessage: "Request body is empty",
+			expectedCode:    "empty_body",
+		},
+		{
+			name:            "whitespace-only body",
+			body:            " \n\t ",
+			expectedMessage: "Request body is empty",
+			expectedCode:    "empty_body",
+		},
+		{
+			name:            "malformed JSON",
+			body:            `{"title":`,
+			expectedMessage: "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			var resp ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Error != tt.expectedMessage {
+				t.Errorf("expected error %q, got %q", tt.expectedMessage, resp.Error)
+			}
+			if resp.Code != tt.expectedCode {
+				t.Errorf("expected code %q, got %q", tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCreate_TrailingData(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "single object",
+			body:           `{"title":"Title","content":"Content","author":"Author"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "single object with trailing whitespace",
+			body:           "{\"title\":\"Title\",\"content\":\"Content\",\"author\":\"Author\"}\n\t ",
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "trailing garbage",
+			body:           `{"title":"Title","content":"Content","author":"Author"}garbage`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "trailing_data",
+		},
+		{
+			name:           "second object",
+			body:           `{"title":"Title","content":"Content","author":"Author"}{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "trailing_data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode == "" {
+				return
+			}
+			var resp ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Code != tt.expectedCode {
+				t.Errorf("expected code %q, got %q", tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCreate_InvalidGzip(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if response.Error != "Invalid gzip request body" {
+		t.Errorf("expected error 'Invalid gzip request body', got %q", response.Error)
+	}
+}
+
+func TestHandleBlogsCreate_GzipBomb(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	const maxBytes = 4096
+	handler := bodyLimitMiddleware(maxBytes)(handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil))
+
+	// 圧縮後は上限を大きく下回るが、展開すると上限を超えるボディ
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"title":"Bomb","author":"Author","content":"`))
+	zw.Write(bytes.Repeat([]byte("a"), 1<<20))
+	zw.Write([]byte(`"}`))
+	zw.Close()
+	if buf.Len() >= maxBytes {
+		t.Fatalf("compressed body is %d bytes, want less than %d", buf.Len(), maxBytes)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+	}
+}
+
+func TestHandleBlogsCreate_IfNoneMatch(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil)
+
+	post := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		body := `{"id":"client-id-1","title":"Imported","content":"Content","author":"Author"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("*")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected first create to succeed with %d, got %d", http.StatusCreated, w.Code)
+	}
+	var blog domain.Blog
+	if err := json.Unmarshal(w.Body.Bytes(), &blog); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if blog.ID != "client-id-1" {
+		t.Errorf("expected client-specified ID, got %q", blog.ID)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := post("*"); w.Code != http.StatusPreconditionFailed {
+			t.Errorf("expected repeat %d to get %d, got %d", i+1, http.StatusPreconditionFailed, w.Code)
+		}
+	}
+
+	// 条件なしの重複は従来どおり409
+	if w := post(""); w.Code != http.StatusConflict {
+		t.Errorf("expected %d without If-None-Match, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestHandleBlogsCreate_SlugCollision(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil)
+
+	for i, expected := range []string{"same-title", "same-title-2", "same-title-3"} {
+		body := fmt.Sprintf(`{"title":"Same Title","content":"Content %d","author":"Author"}`, i)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		var blog domain.Blog
+		if err := json.Unmarshal(w.Body.Bytes(), &blog); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if blog.Slug != expected {
+			t.Errorf("expected slug %q, got %q", expected, blog.Slug)
+		}
+	}
+}
+
+func TestHandleBlogsCreate_ConcurrentSlugs(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.SetUniqueSlugs(true)
+	handler := handleBlogsCreate(log, &config.Config{}, blogStore, nil)
+
+	const n = 8
+	var wg sync.WaitGroup
+	statuses := make([]int, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"title":"Same Title","content":"Content %d","author":"Author"}`, i)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			statuses[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusCreated {
+			t.Errorf("request %d: expected status %d, got %d", i, http.StatusCreated, status)
+		}
+	}
+	blogs, _ := blogStore.GetAll(context.Background())
+	slugs := make(map[string]bool, len(blogs))
+	for _, blog := range blogs {
+		if slugs[blog.Slug] {
+			t.Errorf("duplicate slug %q", blog.Slug)
+		}
+		slugs[blog.Slug] = true
+	}
+	if len(slugs) != n {
+		t.Errorf("expected %d distinct slugs, got %d", n, len(slugs))
+	}
+}
+
+func TestHandleBlogsCreateNDJSON(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	valid := `{"title":"Title %d","content":"Content","author":"Author"}`
+
+	tests := []struct {
+		name        string
+		lines       []string
+		wantStatus  int
+		wantCreated int
+		wantFailed  int
+		wantLines   map[int]int // 行番号ごとのステータス
+	}{
+		{
+			name:        "all succeed",
+			lines:       []string{fmt.Sprintf(valid, 1), fmt.Sprintf(valid, 2)},
+			wantStatus:  http.StatusCreated,
+			wantCreated: 2,
+			wantLines:   map[int]int{1: http.StatusCreated, 2: http.StatusCreated},
+		},
+		{
+			name:       "all fail",
+			lines:      []string{`{"title":"","content":"Content","author":"Author"}`, `{not json}`},
+			wantStatus: http.StatusBadRequest,
+			wantFailed: 2,
+			wantLines:  map[int]int{1: http.StatusBadRequest, 2: http.StatusBadRequest},
+		},
+		{
+			name: "mixed",
+			lines: []string{
+				fmt.Sprintf(valid, 1),
+				`{"title":"Title","content":"","author":"Author"}`,
+				"",
+				`{"id":"taken","title":"Taken","content":"Content","author":"Author"}`,
+			},
+			wantStatus:  http.StatusMultiStatus,
+			wantCreated: 1,
+			wantFailed:  2,
+			wantLines:   map[int]int{1: http.StatusCreated, 2: http.StatusBadRequest, 4: http.StatusConflict},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blogStore := store.NewMemoryBlogStore()
+			blogStore.Create(context.Background(), &domain.Blog{ID: "taken", Title: "Taken", Slug: "existing"})
+			handler := handleBlogsCreateNDJSON(log, &config.Config{}, blogStore, nil)
+
+			body := strings.Join(tt.lines, "\n")
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			var resp batchResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if resp.Created != tt.wantCreated || resp.Failed != tt.wantFailed {
+				t.Errorf("expected %d created and %d failed, got %d and %d", tt.wantCreated, tt.wantFailed, resp.Created, resp.Failed)
+			}
+			got := make(map[int]int, len(resp.Results))
+			for _, result := range resp.Results {
+				got[result.Line] = result.Status
+				if (result.Blog != nil) != (result.Status == http.StatusCreated) {
+					t.Errorf("line %d: expected a blog only for created results, got %+v", result.Line, result)
+				}
+			}
+			if !maps.Equal(got, tt.wantLines) {
+				t.Errorf("expected line statuses %v, got %v", tt.wantLines, got)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCreateNDJSON_Limits(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	post := func(cfg *config.Config, lines ...string) *httptest.ResponseRecorder {
+		handler := handleBlogsCreateNDJSON(log, cfg, store.NewMemoryBlogStore(), nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(strings.Join(lines, "\n")))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("too many records", func(t *testing.T) {
+		line := `{"title":"Title","content":"Content","author":"Author"}`
+		w := post(&config.Config{NDJSONMaxRecords: 2}, line, line, line)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("field limits per line", func(t *testing.T) {
+		cfg := &config.Config{MaxTitleLen: 10, MaxContentLen: 100, MaxAuthorLen: 100, EdgeFieldLimitFactor: 1}
+		w := post(cfg,
+			`{"title":"Title","content":"Content","author":"Author"}`,
+			`{"title":"`+strings.Repeat("a", 50)+`","content":"Content","author":"Author"}`,
+		)
+		if w.Code != http.StatusMultiStatus {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusMultiStatus, w.Code, w.Body.String())
+		}
+		var resp batchResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Results) != 2 || resp.Results[1].Status != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected line 2 to be rejected with 413, got %+v", resp.Results)
+		}
+	})
+}
+
+func TestHandleBlogsCreate_AuthorPostRate(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), newAuthorLimiter(3))
+
+	post := func(author, title string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"title":%q,"content":"Content","author":%q}`, title, author)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		if w := post("spammer", fmt.Sprintf("Post %d", i)); w.Code != http.StatusCreated {
+			t.Fatalf("expected post %d to be created, got %d", i+1, w.Code)
+		}
+	}
+
+	w := post("spammer", "One too many")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+
+	if w := post("someone-else", "Unaffected"); w.Code != http.StatusCreated {
+		t.Errorf("expected other author to be unaffected, got %d", w.Code)
+	}
+}
+
+func TestHandleBlogsCreateNDJSON_AuthorPostRate(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.Create(context.Background(), &domain.Blog{ID: "taken", Title: "Taken", Slug: "existing"})
+	handler := handleBlogsCreateNDJSON(log, &config.Config{}, blogStore, newAuthorLimiter(2))
+
+	// 作成に失敗した行（IDの重複）は投稿レートに数えない
+	body := strings.Join([]string{
+		`{"id":"taken","title":"Duplicate","content":"Content","author":"Author"}`,
+		`{"title":"First","content":"Content","author":"Author"}`,
+		`{"title":"Second","content":"Content","author":"Author"}`,
+		`{"title":"Third","content":"Content","author":"Author"}`,
+	}, "\n")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp batchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.Created != 2 {
+		t.Fatalf("expected 2 created, got %d: %s", resp.Created, w.Body.String())
+	}
+	last := resp.Results[len(resp.Results)-1]
+	if last.Status != http.StatusTooManyRequests {
+		t.Fatalf("expected the last line to be throttled, got %+v", last)
+	}
+	if last.RetryAfter <= 0 {
+		t.Errorf("expected retry_after on the throttled line, got %d", last.RetryAfter)
+	}
+}
+
+func TestHandleBlogsCreate_DuplicateTitleWarning(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.Create(context.Background(), &domain.Blog{ID: "existing", Title: "Hello World", Content: "Content", Author: "Alice", CreatedAt: time.Now()})
+
+	tests := []struct {
+		name         string
+		cfg          *config.Config
+		body         string
+		wantWarnings []string
+	}{
+		{
+			name:         "same title by the same author",
+			cfg:          &config.Config{WarnDuplicateTitles: true},
+			body:         `{"title":"hello world","content":"Other","author":"Alice"}`,
+			wantWarnings: []string{warnDuplicateTitle},
+		},
+		{
+			name: "same title by another author",
+			cfg:  &config.Config{WarnDuplicateTitles: true},
+			body: `{"title":"Hello World","content":"Other","author":"Bob"}`,
+		},
+		{
+			name: "different title",
+			cfg:  &config.Config{WarnDuplicateTitles: true},
+			body: `{"title":"Another Post","content":"Other","author":"Alice"}`,
+		},
+		{
+			name: "disabled",
+			cfg:  &config.Config{},
+			body: `{"title":"Hello World","content":"Other","author":"Alice"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleBlogsCreate(log, tt.cfg, blogStore, nil)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			// 警告があっても投稿は作成される
+			if w.Code != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+			}
+			var response struct {
+				ID       string   `json:"id"`
+				Warnings []string `json:"warnings"`
+			}
+			json.NewDecoder(w.Body).Decode(&response)
+			if response.ID == "" {
+				t.Error("expected the created blog in the response")
+			}
+			if !slices.Equal(response.Warnings, tt.wantWarnings) {
+				t.Errorf("expected warnings %v, got %v", tt.wantWarnings, response.Warnings)
+			}
+		})
+	}
+}
+
+func TestHandleBlogs_AuthorID(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	cfg := &config.Config{AuthorIDHeader: "X-Auth-Subject"}
+	mux := http.NewServeMux()
+	addRoutes(mux, log, cfg, blogStore, nil, nil, nil)
+
+	// 同じ作者が改名の前後で投稿し、別の作者が改名前と同じ表示名を使う
+	creates := []struct {
+		body    string
+		subject string
+	}{
+		{body: `{"title":"Before","content":"Content","author":"Alice","author_id":"user-1"}`},
+		{body: `{"title":"After","content":"Content","author":"Alice Smith","author_id":"spoofed"}`, subject: "user-1"},
+		{body: `{"title":"Other","content":"Content","author":"Alice","author_id":"user-2"}`},
+	}
+	for _, c := range creates {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		if c.subject != "" {
+			req.Header.Set("X-Auth-Subject", c.subject)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantTitles []string
+	}{
+		{name: "author id across display names", query: "author_id=user-1", wantTitles: []string{"After", "Before"}},
+		{name: "author does not match the author id", query: "author=user-1"},
+		{name: "author matches the display name", query: "author=Alice", wantTitles: []string{"Before", "Other"}},
+		{name: "author and author id", query: "author=Alice&author_id=user-2", wantTitles: []string{"Other"}},
+		{name: "display name is not an author id", query: "author_id=Alice"},
+		{name: "spoofed author id is replaced", query: "author_id=spoofed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs?sort=title&"+tt.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			var blogs []domain.Blog
+			json.NewDecoder(w.Body).Decode(&blogs)
+			var titles []string
+			for _, blog := range blogs {
+				titles = append(titles, blog.Title)
+			}
+			if !slices.Equal(titles, tt.wantTitles) {
+				t.Errorf("expected %v, got %v", tt.wantTitles, titles)
+			}
+
+			// 件数も一覧と同じ絞り込みになる
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/blogs/count?"+tt.query, nil)
+			w = httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			var count countResponse
+			json.NewDecoder(w.Body).Decode(&count)
+			if count.Count != len(tt.wantTitles) {
+				t.Errorf("expected count %d, got %d", len(tt.wantTitles), count.Count)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCreate_AuthorDefaultTags(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{
+		NormalizeAuthor:   true,
+		AuthorDefaultTags: map[string][]string{"Jane  Doe": {"go", "backend"}},
+	}
+	handler := handleBlogsCreate(log, cfg, store.NewMemoryBlogStore(), nil)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantTags []string
+	}{
+		{
+			name:     "defaults applied",
+			body:     `{"title":"Title","content":"Content","author":"Jane Doe"}`,
+			wantTags: []string{"go", "backend"},
+		},
+		{
+			name:     "merged after explicit tags without duplicates",
+			body:     `{"title":"Title","content":"Content","author":"Jane Doe","tags":["Backend","api"]}`,
+			wantTags: []string{"backend", "api", "go"},
+		},
+		{
+			name:     "other author unaffected",
+			body:     `{"title":"Title","content":"Content","author":"John Roe","tags":["api"]}`,
+			wantTags: []string{"api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+			}
+			var blog domain.Blog
+			json.NewDecoder(w.Body).Decode(&blog)
+			if !slices.Equal(blog.Tags, tt.wantTags) {
+				t.Errorf("expected tags %v, got %v", tt.wantTags, blog.Tags)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCreate_StoreError(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	mockStore := &mockBlogStore{
+		createError: errors.New("store error"),
+	}
+	handler := handleBlogsCreate(log, &config.Config{}, mockStore, nil)
+
+	reqBody := domain.CreateBlogRequest{
+		Title:   "Test Title",
+		Content: "Test Content",
+		Author:  "Test Author",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Error != "Failed to create blog" {
+		t.Errorf("expected error 'Failed to create blog', got %q", resp.Error)
+	}
+}
+
+func TestHandleBlogsCreate_HugeFieldRejectedEarly(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{MaxTitleLen: 200, MaxContentLen: 1000, MaxAuthorLen: 100, EdgeFieldLimitFactor: 4}
+	handler := handleBlogsCreate(log, cfg, store.NewMemoryBlogStore(), nil)
+
+	const titleSize = 10 << 20
+	body := &countingReader{r: io.MultiReader(
+		strings.NewReader(`{"title":"`),
+		strings.NewReader(strings.Repeat("x", titleSize)),
+		strings.NewReader(`","content":"Content","author":"Author"}`),
+	)}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", body)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if _, ok := resp.Problems["title"]; !ok {
+		t.Errorf("expected a title problem, got %v", resp.Problems)
+	}
+	// タイトル全体を読み込む前に打ち切られていること
+	if body.read >= titleSize/10 {
+		t.Errorf("expected early rejection, but %d bytes were read", body.read)
+	}
+}
+
+func TestHandleBlogsCreate_SlightlyLongTitleStillValidated(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{MaxTitleLen: 200, MaxContentLen: 1000, MaxAuthorLen: 100, EdgeFieldLimitFactor: 4}
+	handler := handleBlogsCreate(log, cfg, store.NewMemoryBlogStore(), nil)
+
+	body, _ := json.Marshal(domain.CreateBlogRequest{Title: strings.Repeat("x", 201), Content: "Content", Author: "Author"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleAdminSnapshotRestore(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	ctx := context.Background()
+	blogStore.Create(ctx, &domain.Blog{ID: "a", Title: "First"})
+	blogStore.Create(ctx, &domain.Blog{ID: "b", Title: "Second"})
+
+	w := httptest.NewRecorder()
+	handleAdminSnapshot(log, blogStore).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/snapshot", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	snapshot := w.Body.Bytes()
+
+	blogStore.Delete(ctx, "a")
+	blogStore.Create(ctx, &domain.Blog{ID: "c", Title: "Third"})
+
+	w = httptest.NewRecorder()
+	handleAdminRestore(log, blogStore).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/restore", bytes.NewReader(snapshot)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+
+	blogs, _ := blogStore.GetAll(ctx)
+	ids := make([]string, 0, len(blogs))
+	for _, blog := range blogs {
+		ids = append(ids, blog.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"a", "b"}) {
+		t.Errorf("expected restored IDs [a b], got %v", ids)
+	}
+
+	w = httptest.NewRecorder()
+	handleAdminRestore(log, blogStore).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/restore", strings.NewReader(`{"not":"a list"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid snapshot, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleAdminSnapshot_Unsupported(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	w := httptest.NewRecorder()
+	handleAdminSnapshot(log, &mockBlogStore{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/snapshot", nil))
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status %d, got %d", http.StatusNotImplemented, w.Code)
+	}
+
+	// ラップしたストアの内側が対応していない場合も501とする
+	wrapped := encryptedStore(sliceOnlyStore{BlogStore: store.NewMemoryBlogStore()})
+	w = httptest.NewRecorder()
+	handleAdminSnapshot(log, wrapped).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/snapshot", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status %d through a wrapper, got %d", http.StatusNotImplemented, w.Code)
+	}
+	w = httptest.NewRecorder()
+	handleAdminRestore(log, wrapped).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/restore", strings.NewReader("[]")))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected restore status %d through a wrapper, got %d", http.StatusNotImplemented, w.Code)
+	}
+}
+
+// encryptedStore wraps s in an EncryptedBlogStore with a fixed test key
+func encryptedStore(s store.BlogStore) store.BlogStore {
+	encrypted, err := store.NewEncryptedBlogStore(s, bytes.Repeat([]byte{0x42}, 32), false)
+	if err != nil {
+		panic(err)
+	}
+	return encrypted
+}
+
+func TestHandlers_ContextErrors(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{}
+	createBody := `{"title":"Test Title","content":"Test Content","author":"Test Author"}`
+
+	tests := []struct {
+		name            string
+		handler         http.Handler
+		method          string
+		path            string
+		body            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "create deadline exceeded",
+			handler:         handleBlogsCreate(log, cfg, &mockBlogStore{createError: context.DeadlineExceeded}, nil),
+			method:          http.MethodPost,
+			path:            "/api/v1/blogs",
+			body:            createBody,
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "Request timed out",
+		},
+		{
+			name:            "list deadline exceeded",
+			handler:         handleBlogsGet(log, cfg, &mockBlogStore{getAllError: fmt.Errorf("query: %w", context.DeadlineExceeded)}),
+			method:          http.MethodGet,
+			path:            "/api/v1/blogs",
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "Request timed out",
+		},
+		{
+			name:            "get canceled",
+			handler:         handleBlogsByID(log, cfg, &mockBlogStore{getByIDError: context.Canceled}),
+			method:          http.MethodGet,
+			path:            "/api/v1/blogs/some-id",
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "Request canceled",
+		},
+		{
+			name:            "other errors stay 500",
+			handler:         handleBlogsByID(log, cfg, &mockBlogStore{deleteError: errors.New("disk failure")}),
+			method:          http.MethodDelete,
+			path:            "/api/v1/blogs/some-id",
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "Failed to delete blog",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			tt.handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			var resp ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Error != tt.expectedMessage {
+				t.Errorf("expected error %q, got %q", tt.expectedMessage, resp.Error)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCreate_Deduplicate(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.SetDeduplicateContent(true)
+	cfg := &config.Config{DeduplicateContent: true, TrimContent: true}
+	handler := handleBlogsCreate(log, cfg, blogStore, nil)
+
+	tests := []struct {
+		name           string
+		body           domain.CreateBlogRequest
+		expectedStatus int
+	}{
+		{
+			name:           "first create succeeds",
+			body:           domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "identical create is rejected",
+			body:           domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "identical after trimming is rejected",
+			body:           domain.CreateBlogRequest{Title: "  Title ", Content: "Content\n", Author: "Author"},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "slightly different content succeeds",
+			body:           domain.CreateBlogRequest{Title: "Title", Content: "Content!", Author: "Author"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "same post by another author succeeds",
+			body:           domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Another Author"},
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCreate_DeduplicateDisabled(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	handler := handleBlogsCreate(log, &config.Config{}, blogStore, nil)
+
+	body, _ := json.Marshal(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("create %d: expected status %d, got %d", i+1, http.StatusCreated, w.Code)
+		}
+	}
+}
+
+func TestHandleBlogsCreate_AlreadyExists(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	mockStore := &mockBlogStore{
+		createError: store.ErrAlreadyExists,
+	}
+	handler := handleBlogsCreate(log, &config.Config{}, mockStore, nil)
+
+	body, _ := json.Marshal(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Error != "Blog already exists" {
+		t.Errorf("expected error 'Blog already exists', got %q", resp.Error)
+	}
+}
+
+func TestHandleBlogsCreate_CapacityExceeded(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStoreWithCapacity(1)
+	cfg := &config.Config{MaxTitleLen: 200, MaxContentLen: 1000, MaxAuthorLen: 100}
+	handler := handleBlogsCreate(log, cfg, blogStore, nil)
+
+	expected := []int{http.StatusCreated, http.StatusInsufficientStorage}
+	for i, want := range expected {
+		body, _ := json.Marshal(domain.CreateBlogRequest{Title: fmt.Sprintf("Title %d", i), Content: "Content", Author: "Author"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("request %d: expected status %d, got %d", i, want, w.Code)
+		}
+	}
+}
+
+func TestHandleBlogsExport(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	for _, id := range []string{"c", "a", "d", "b"} {
+		blogStore.Create(context.Background(), &domain.Blog{ID: id, Title: "Title " + id})
+	}
+
+	// ストリーミングはResponseControllerを使うため実サーバー経由でテストする
+	ts := httptest.NewServer(handleBlogsExport(log, &config.Config{}, blogStore))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "all blogs in ID order", query: "", expected: []string{"a", "b", "c", "d"}},
+		{name: "resume after cursor", query: "?after=b", expected: []string{"c", "d"}},
+		{name: "cursor for a deleted ID", query: "?after=bb", expected: []string{"c", "d"}},
+		{name: "cursor at the end", query: "?after=d", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.query)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+				t.Errorf("expected NDJSON content type, got %q", ct)
+			}
+
+			ids := []string{}
+			dec := json.NewDecoder(resp.Body)
+			for dec.More() {
+				var blog domain.Blog
+				if err := dec.Decode(&blog); err != nil {
+					t.Fatalf("failed to decode line: %v", err)
+				}
+				ids = append(ids, blog.ID)
+			}
+			if !slices.Equal(ids, tt.expected) {
+				t.Errorf("expected IDs %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsGet_Pagination(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	cfg := &config.Config{DefaultPageSize: 2, MaxPageSize: 3, MaxPageOffset: 10}
+	handler := handleBlogsGet(log, cfg, blogStore)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		blogStore.Create(ctx, &domain.Blog{
+			ID:        fmt.Sprintf("blog-%d", i),
+			Title:     "Title",
+			Content:   "Content",
+			Author:    "Author",
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+			UpdatedAt: base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+		expectedField  string
+		expectedError  string
+	}{
+		{
+			name:           "default page size applied when limit is absent",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"blog-0", "blog-1"},
+		},
+		{
+			name:           "explicit limit and offset",
+			query:          "?limit=3&offset=2",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"blog-2", "blog-3", "blog-4"},
+		},
+		{
+			name:           "descending page",
+			query:          "?sort=created_at:desc&limit=2&offset=1",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"blog-3", "blog-2"},
+		},
+		{
+			name:           "offset past the end",
+			query:          "?offset=10",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{},
+		},
+		{
+			name:           "limit above max",
+			query:          "?limit=100000",
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "limit",
+			expectedError:  "limit must not exceed 3",
+		},
+		{
+			name:           "non-numeric limit",
+			query:          "?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "limit",
+			expectedError:  "limit must be a positive integer",
+		},
+		{
+			name:           "offset above max",
+			query:          "?offset=11",
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "offset",
+			expectedError:  "offset must not exceed 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedError != "" {
+				var response ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if response.Problems[tt.expectedField] != tt.expectedError {
+					t.Errorf("expected %s problem %q, got %q", tt.expectedField, tt.expectedError, response.Problems[tt.expectedField])
+				}
+				return
+			}
+
+			var blogs []*domain.Blog
+			if err := json.Unmarshal(w.Body.Bytes(), &blogs); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if len(blogs) != len(tt.expectedIDs) {
+				t.Fatalf("expected %d blogs, got %d", len(tt.expectedIDs), len(blogs))
+			}
+			for i, id := range tt.expectedIDs {
+				if blogs[i].ID != id {
+					t.Errorf("expected blog %d to be %q, got %q", i, id, blogs[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBlogs_Category(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	cfg := &config.Config{
+		MaxTitleLen:       100,
+		MaxContentLen:     5000,
+		MaxAuthorLen:      50,
+		AllowedCategories: []string{"tech", "life"},
+	}
+
+	// カテゴリーの許可リストはバリデーション設定としてコンテキストで渡される
+	create := validationMiddleware(validationConfig(cfg))(handleBlogsCreate(log, cfg, blogStore, nil))
+	list := handleBlogsGet(log, cfg, blogStore)
+
+	createTests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "valid category",
+			body:           `{"title":"Go","content":"Content","author":"Alice","category":"tech"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "another valid category",
+			body:           `{"title":"Cooking","content":"Content","author":"Alice","category":"life"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "no category",
+			body:           `{"title":"Misc","content":"Content","author":"Bob"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid category",
+			body:           `{"title":"Rumor","content":"Content","author":"Bob","category":"gossip"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range createTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			create.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusBadRequest {
+				var response ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if response.Problems["category"] == "" {
+					t.Errorf("expected category problem, got %v", response.Problems)
+				}
+			}
+		})
+	}
+
+	filterTests := []struct {
+		name           string
+		query          string
+		expectedTitles []string
+	}{
+		{
+			name:           "filter by category",
+			query:          "?category=tech",
+			expectedTitles: []string{"Go"},
+		},
+		{
+			name:           "filter by author and category",
+			query:          "?author=Alice&category=life",
+			expectedTitles: []string{"Cooking"},
+		},
+		{
+			name:           "unknown category",
+			query:          "?category=news",
+			expectedTitles: []string{},
+		},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			list.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var blogs []*domain.Blog
+			if err := json.Unmarshal(w.Body.Bytes(), &blogs); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if len(blogs) != len(tt.expectedTitles) {
+				t.Fatalf("expected %d blogs, got %d", len(tt.expectedTitles), len(blogs))
+			}
+			for i, title := range tt.expectedTitles {
+				if blogs[i].Title != title {
+					t.Errorf("expected title %q, got %q", title, blogs[i].Title)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBlogsGet_Sort(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	blogStore.Create(ctx, &domain.Blog{ID: "old", Title: "B", Author: "Amy", CreatedAt: base})
+	blogStore.Create(ctx, &domain.Blog{ID: "new", Title: "A", Author: "Zed", CreatedAt: base.Add(time.Hour)})
+
+	tests := []struct {
+		name           string
+		defaultSort    string
+		sortableFields []string
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{
+			name:           "configured default sort",
+			defaultSort:    "created_at:desc",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"new", "old"},
+		},
+		{
+			name:           "query overrides default",
+			defaultSort:    "created_at:desc",
+			query:          "?sort=created_at:asc",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"old", "new"},
+		},
+		{
+			name:           "sort by title",
+			query:          "?sort=title",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"new", "old"},
+		},
+		{
+			name:           "unknown sort field",
+			query:          "?sort=content",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "author is not sortable by default",
+			query:          "?sort=author",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "author sortable when allowed",
+			sortableFields: []string{"created_at", "author"},
+			query:          "?sort=author:desc",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"new", "old"},
+		},
+		{
+			name:           "title rejected when not allowed",
+			sortableFields: []string{"created_at", "author"},
+			query:          "?sort=title",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleBlogsGet(log, &config.Config{DefaultSort: tt.defaultSort, SortableFields: tt.sortableFields}, blogStore)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedIDs == nil {
+				return
+			}
+			var blogs []*domain.Blog
+			if err := json.Unmarshal(w.Body.Bytes(), &blogs); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			for i, id := range tt.expectedIDs {
+				if blogs[i].ID != id {
+					t.Errorf("expected blog %d to be %q, got %q", i, id, blogs[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBlogs_AuthorNormalization(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	cfg := &config.Config{NormalizeAuthor: true, AuthorTitleCase: true}
+
+	create := handleBlogsCreate(log, cfg, blogStore, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs",
+		strings.NewReader(`{"title":"Title","content":"Content","author":"  john   doe "}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	create.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	var created domain.Blog
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if created.Author != "John Doe" {
+		t.Errorf("expected stored author 'John Doe', got %q", created.Author)
+	}
+
+	list := handleBlogsGet(log, cfg, blogStore)
+	for _, query := range []string{"John+Doe", "john%20%20%20doe", "+JOHN+DOE+"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs?author="+query, nil)
+		w := httptest.NewRecorder()
+		list.ServeHTTP(w, req)
+
+		var blogs []*domain.Blog
+		if err := json.Unmarshal(w.Body.Bytes(), &blogs); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if len(blogs) != 1 {
+			t.Errorf("expected author query %q to match 1 blog, got %d", query, len(blogs))
+		}
+	}
+}
+
+func TestHandleBlogsGet_EmptyResultStatus(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.Create(context.Background(), domain.NewBlog(domain.CreateBlogRequest{
+		Title: "Title", Content: "Content", Author: "Known",
+	}))
+
+	tests := []struct {
+		name           string
+		status         int
+		query          string
+		expectedStatus int
+	}{
+		{
+			name:           "200 with empty array for unknown author",
+			status:         http.StatusOK,
+			query:          "?author=Nobody",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "404 for unknown author",
+			status:         http.StatusNotFound,
+			query:          "?author=Nobody",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "404 mode still returns matches",
+			status:         http.StatusNotFound,
+			query:          "?author=Known",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleBlogsGet(log, &config.Config{EmptyResultStatus: tt.status}, blogStore)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus == http.StatusOK && tt.query == "?author=Nobody" {
+				if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+					t.Errorf("expected empty array, got %s", body)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBlogsRecent(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		blogStore.Create(ctx, &domain.Blog{
+			ID:        fmt.Sprintf("blog-%d", i),
+			Title:     "Title",
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+
+	tests := []struct {
+		name           string
+		cfg            *config.Config
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{
+			name:           "newest n first",
+			cfg:            &config.Config{},
+			query:          "?n=2",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"blog-3", "blog-2"},
+		},
+		{
+			name:           "n larger than total",
+			cfg:            &config.Config{},
+			query:          "?n=50",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"blog-3", "blog-2", "blog-1", "blog-0"},
+		},
+		{
+			name:           "n capped at MAX_PAGE_SIZE",
+			cfg:            &config.Config{MaxPageSize: 3},
+			query:          "?n=50",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"blog-3", "blog-2", "blog-1"},
+		},
+		{
+			name:           "invalid n",
+			cfg:            &config.Config{},
+			query:          "?n=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleBlogsRecent(log, tt.cfg, blogStore)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/recent"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedIDs == nil {
+				return
+			}
+			var blogs []*domain.Blog
+			if err := json.Unmarshal(w.Body.Bytes(), &blogs); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if len(blogs) != len(tt.expectedIDs) {
+				t.Fatalf("expected %d blogs, got %d", len(tt.expectedIDs), len(blogs))
+			}
+			for i, id := range tt.expectedIDs {
+				if blogs[i].ID != id {
+					t.Errorf("expected blog %d to be %q, got %q", i, id, blogs[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBlogsGet_ETag(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	ctx := context.Background()
+	blog := domain.NewBlog(domain.CreateBlogRequest{Title: "First", Content: "Content", Author: "Author"})
+	blogStore.Create(ctx, blog)
+	blogStore.Create(ctx, domain.NewBlog(domain.CreateBlogRequest{Title: "Second", Content: "Content", Author: "Author"}))
+
+	handler := handleBlogsGet(log, &config.Config{}, blogStore)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, first.Code)
+	}
+	etag := first.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected weak ETag, got %q", etag)
+	}
+
+	unchanged := get(etag)
+	if unchanged.Code != http.StatusNotModified {
+		t.Fatalf("expected status %d for unchanged collection, got %d", http.StatusNotModified, unchanged.Code)
+	}
+	if unchanged.Body.Len() != 0 {
+		t.Errorf("expected empty body on 304, got %q", unchanged.Body.String())
+	}
+	if got := unchanged.Header().Get("ETag"); got != etag {
+		t.Errorf("expected ETag %q on 304, got %q", etag, got)
+	}
+
+	if w := get(`"other", ` + strings.TrimPrefix(etag, "W/")); w.Code != http.StatusNotModified {
+		t.Errorf("expected list with strong form to match, got %d", w.Code)
+	}
+
+	updated := *blog
+	updated.Title = "First (edited)"
+	updated.UpdatedAt = blog.UpdatedAt.Add(time.Second)
+	if err := blogStore.Update(ctx, blog.ID, &updated); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	modified := get(etag)
+	if modified.Code != http.StatusOK {
+		t.Fatalf("expected status %d after modification, got %d", http.StatusOK, modified.Code)
+	}
+	if got := modified.Header().Get("ETag"); got == etag {
+		t.Errorf("expected ETag to change after modification, still %q", got)
+	}
+}
+
+func TestHandleBlogsGet_Tags(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	ctx := context.Background()
+	for id, tags := range map[string][]string{"go-only": {"go"}, "api-only": {"api"}, "both": {"go", "api"}, "none": nil} {
+		blogStore.Create(ctx, &domain.Blog{ID: id, Title: id, Tags: tags, CreatedAt: time.Now()})
+	}
+
+	tests := []struct {
+		name       string
+		cfg        *config.Config
+		query      string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{name: "single tag", cfg: &config.Config{TagMatch: "all"}, query: "?tag=go", wantStatus: http.StatusOK, wantIDs: []string{"both", "go-only"}},
+		{name: "all tags", cfg: &config.Config{TagMatch: "all"}, query: "?tag=go&tag=api", wantStatus: http.StatusOK, wantIDs: []string{"both"}},
+		{name: "any tag", cfg: &config.Config{TagMatch: "any"}, query: "?tag=go&tag=api", wantStatus: http.StatusOK, wantIDs: []string{"api-only", "both", "go-only"}},
+		{name: "tags normalized", cfg: &config.Config{TagMatch: "all"}, query: "?tag=GO", wantStatus: http.StatusOK, wantIDs: []string{"both", "go-only"}},
+		{name: "too many tags", cfg: &config.Config{MaxTagsPerQuery: 1}, query: "?tag=go&tag=api", wantStatus: http.StatusBadRequest},
+		{name: "duplicates count once", cfg: &config.Config{MaxTagsPerQuery: 1}, query: "?tag=go&tag=Go", wantStatus: http.StatusOK, wantIDs: []string{"both", "go-only"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.DefaultSort = "title:asc"
+			handler := handleBlogsGet(log, tt.cfg, blogStore)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var blogs []domain.Blog
+			json.NewDecoder(w.Body).Decode(&blogs)
+			ids := make([]string, 0, len(blogs))
+			for _, blog := range blogs {
+				ids = append(ids, blog.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestHandleBlogsCount(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	memStore := store.NewMemoryBlogStore()
+	ctx := context.Background()
+	for i, author := range []string{"Alice", "Alice", "Bob", "Carol"} {
+		tags := []string{"go"}
+		if i%2 == 1 {
+			tags = []string{"api"}
+		}
+		memStore.Create(ctx, &domain.Blog{ID: fmt.Sprint(i), Title: "Title", Author: author, Tags: tags, CreatedAt: time.Now()})
+	}
+	cfg := &config.Config{TagMatch: "all"}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCount int
+	}{
+		{name: "no filter", query: "", wantCount: 4},
+		{name: "author", query: "?author=Alice", wantCount: 2},
+		{name: "author normalized", query: "?author=+Alice+", wantCount: 2},
+		{name: "tag", query: "?tag=go", wantCount: 2},
+		{name: "author and tag", query: "?author=Alice&tag=api", wantCount: 1},
+		{name: "unknown author", query: "?author=Dave", wantCount: 0},
+	}
+
+	for _, blogStore := range []store.BlogStore{memStore, sliceOnlyStore{memStore}} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%T/%s", blogStore, tt.name), func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/count"+tt.query, nil)
+				w := httptest.NewRecorder()
+				handleBlogsCount(log, cfg, blogStore).ServeHTTP(w, req)
+
+				if w.Code != http.StatusOK {
+					t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+				}
+				var resp countResponse
+				json.NewDecoder(w.Body).Decode(&resp)
+				if resp.Count != tt.wantCount {
+					t.Errorf("expected count %d, got %d", tt.wantCount, resp.Count)
+				}
+
+				// 件数は同じ絞り込みで一覧が返す件数と一致すること
+				req = httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+tt.query, nil)
+				w = httptest.NewRecorder()
+				handleBlogsGet(log, cfg, blogStore).ServeHTTP(w, req)
+				var blogs []domain.Blog
+				json.NewDecoder(w.Body).Decode(&blogs)
+				if len(blogs) != resp.Count {
+					t.Errorf("expected count to match the %d listed blogs, got %d", len(blogs), resp.Count)
+				}
+			})
+		}
+	}
+}
+
+// sliceOnlyStore hides the memory store's Each so handlers take the GetAll path
+type sliceOnlyStore struct {
+	store.BlogStore
+}
+
+func TestHandleBlogsGet_StreamedMatchesSlice(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	memStore := store.NewMemoryBlogStore()
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 30 {
+		memStore.Create(ctx, &domain.Blog{
+			ID:        fmt.Sprintf("blog-%02d", i),
+			Title:     fmt.Sprintf("Title <%d>", i%7),
+			Content:   "Content",
+			Author:    "Author",
+			Tags:      []string{[]string{"go", "api"}[i%2]},
+			CreatedAt: base.Add(time.Duration(i%5) * time.Hour),
+		})
+	}
+
+	queries := []string{
+		"",
+		"?sort=title:desc&limit=7&offset=3",
+		"?sort=created_at:asc&limit=100",
+		"?tag=go&limit=4&offset=2",
+		"?offset=100",
+	}
+	cfg := &config.Config{DefaultPageSize: 10, TagMatch: "all"}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			get := func(blogStore store.BlogStore) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+query, nil)
+				w := httptest.NewRecorder()
+				handleBlogsGet(log, cfg, blogStore).ServeHTTP(w, req)
+				return w
+			}
+			streamed := get(memStore)
+			sliced := get(sliceOnlyStore{BlogStore: memStore})
+
+			if !json.Valid(streamed.Body.Bytes()) {
+				t.Fatalf("expected valid JSON, got %q", streamed.Body.String())
+			}
+			if streamed.Body.String() != sliced.Body.String() {
+				t.Errorf("expected streamed body to equal the GetAll body\nstreamed: %s\nsliced:   %s", streamed.Body.String(), sliced.Body.String())
+			}
+			if streamed.Header().Get("ETag") != sliced.Header().Get("ETag") {
+				t.Errorf("expected equal ETags, got %q and %q", streamed.Header().Get("ETag"), sliced.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestEncodeArray(t *testing.T) {
+	blogs := []*domain.Blog{
+		{ID: "1", Title: "A & B", CreatedAt: time.Unix(0, 0).UTC()},
+		{ID: "2", Title: "<b>", CreatedAt: time.Unix(0, 0).UTC()},
+	}
+
+	for _, fieldCase := range []string{fieldCaseSnake, fieldCaseCamel} {
+		t.Run(fieldCase, func(t *testing.T) {
+			for _, items := range [][]*domain.Blog{blogs, {}} {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
+				req = req.WithContext(context.WithValue(req.Context(), fieldCaseKey, fieldCase))
+				want := httptest.NewRecorder()
+				encode(want, req, http.StatusOK, items)
+				got := httptest.NewRecorder()
+				encodeArray(got, req, http.StatusOK, items)
+
+				if got.Body.String() != want.Body.String() {
+					t.Errorf("expected %q, got %q", want.Body.String(), got.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBlogsGet_StrictQueryParams(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.Create(context.Background(), domain.NewBlog(domain.CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"}))
+
+	tests := []struct {
+		name        string
+		strict      bool
+		query       string
+		wantStatus  int
+		wantProblem string
+	}{
+		{name: "known params accepted", strict: true, query: "?author=Author&limit=10&sort=title", wantStatus: http.StatusOK},
+		{name: "unknown param rejected", strict: true, query: "?autor=Author", wantStatus: http.StatusBadRequest, wantProblem: "autor"},
+		{name: "unknown param ignored when lenient", strict: false, query: "?autor=Author", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleBlogsGet(log, &config.Config{StrictQueryParams: tt.strict}, blogStore)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantProblem != "" {
+				var response ErrorResponse
+				json.NewDecoder(w.Body).Decode(&response)
+				if _, ok := response.Problems[tt.wantProblem]; !ok {
+					t.Errorf("expected problem naming %q, got %v", tt.wantProblem, response.Problems)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBlogsGet_StoreError(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	mockStore := &mockBlogStore{
+		getAllError: errors.New("store error"),
+	}
+	handler := handleBlogsGet(log, &config.Config{}, mockStore)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestHandleStats(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	handler := handleStats(log, blogStore)
+
+	oldest := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newest := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+	blogStore.Create(ctx, &domain.Blog{ID: "1", Title: "Blog 1", Content: "abcd", Author: "Author A", CreatedAt: oldest, UpdatedAt: oldest})
+	blogStore.Create(ctx, &domain.Blog{ID: "2", Title: "Blog 2", Content: "abcdefgh", Author: "Author A", CreatedAt: newest, UpdatedAt: newest})
+
+	t.Run("wrong method", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/stats", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+		}
+	})
+
+	t.Run("get stats", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var stats domain.BlogStats
+		if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("failed to unmarshal stats response: %v", err)
+		}
+		if stats.TotalBlogs != 2 {
+			t.Errorf("expected 2 blogs, got %d", stats.TotalBlogs)
+		}
+		if stats.BlogsPerAuthor["Author A"] != 2 {
+			t.Errorf("expected 2 blogs by Author A, got %d", stats.BlogsPerAuthor["Author A"])
+		}
+		if stats.AverageContentLength != 6 {
+			t.Errorf("expected average content length 6, got %v", stats.AverageContentLength)
+		}
+		if stats.NewestPostAt == nil || !stats.NewestPostAt.Equal(newest) {
+			t.Errorf("expected newest post at %v, got %v", newest, stats.NewestPostAt)
+		}
+		if stats.OldestPostAt == nil || !stats.OldestPostAt.Equal(oldest) {
+			t.Errorf("expected oldest post at %v, got %v", oldest, stats.OldestPostAt)
+		}
+	})
+}
+
+func TestHandleStats_StoreError(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	mockStore := &mockBlogStore{
+		statsError: errors.New("store error"),
+	}
+	handler := handleStats(log, mockStore)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+// Helper function to create string pointer
+func stringPtr(s string) *string {
+	return &s
+}
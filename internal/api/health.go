@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+// healthState is the server's shared view of its own health, reported by /healthz
+// 劣化状態でも/healthzは200を返し、ボディの status でのみ知らせる
+// （ストアが遅いだけでlivenessプローブに再起動させないため）
+type healthState struct {
+	mu     sync.RWMutex
+	reason string
+}
+
+func newHealthState() *healthState {
+	return &healthState{}
+}
+
+// setDegraded marks the server as degraded for reason
+func (h *healthState) setDegraded(reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reason = reason
+}
+
+// setHealthy clears a previous degraded state
+func (h *healthState) setHealthy() {
+	h.setDegraded("")
+}
+
+// degraded returns the reason the server is degraded, if it is
+func (h *healthState) degraded() (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.reason, h.reason != ""
+}
+
+// check pings the store once, marking the server degraded if the ping fails
+// or takes longer than slow
+func (h *healthState) check(ctx context.Context, log *logger.Logger, pinger store.Pinger, slow time.Duration) {
+	start := time.Now()
+	err := pinger.Ping(ctx)
+	elapsed := time.Since(start)
+
+	switch {
+	case err != nil:
+		if _, wasDegraded := h.degraded(); !wasDegraded {
+			log.Warn(ctx, "health check failed, reporting degraded", "error", err)
+		}
+		h.setDegraded("store ping failed")
+	case slow > 0 && elapsed > slow:
+		if _, wasDegraded := h.degraded(); !wasDegraded {
+			log.Warn(ctx, "store is slow, reporting degraded", "latency", elapsed)
+		}
+		h.setDegraded("store is slow")
+	default:
+		if _, wasDegraded := h.degraded(); wasDegraded {
+			log.Info(ctx, "health check recovered")
+		}
+		h.setHealthy()
+	}
+}
+
+// runChecker runs check every interval until ctx is done
+// 各チェックはintervalでタイムアウトさせ、応答しないストアでチェックが積み重ならないようにする
+func (h *healthState) runChecker(ctx context.Context, log *logger.Logger, pinger store.Pinger, interval, slow time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			h.check(checkCtx, log, pinger, slow)
+			cancel()
+		}
+	}
+}
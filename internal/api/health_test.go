@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/logger"
+)
+
+// pingerFunc adapts a function to store.Pinger
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestHandleHealthz_Degraded(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	health := newHealthState()
+	handler := handleHealthz(log, health)
+
+	health.setDegraded("store is slow")
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	// livenessプローブに再起動させないよう、劣化していても200を返す
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if response["status"] != "degraded" || response["reason"] != "store is slow" {
+		t.Errorf("expected degraded status with reason, got %v", response)
+	}
+}
+
+func TestHealthState_Check(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	tests := []struct {
+		name         string
+		ping         func(ctx context.Context) error
+		wantDegraded bool
+	}{
+		{
+			name: "healthy",
+			ping: func(ctx context.Context) error { return nil },
+		},
+		{
+			name:         "ping fails",
+			ping:         func(ctx context.Context) error { return errors.New("connection refused") },
+			wantDegraded: true,
+		},
+		{
+			name: "ping slower than the threshold",
+			ping: func(ctx context.Context) error {
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			},
+			wantDegraded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := newHealthState()
+			health.check(context.Background(), log, pingerFunc(tt.ping), 10*time.Millisecond)
+			if _, got := health.degraded(); got != tt.wantDegraded {
+				t.Errorf("expected degraded %v, got %v", tt.wantDegraded, got)
+			}
+
+			// 次のチェックが成功すれば劣化状態は解除される
+			health.check(context.Background(), log, pingerFunc(func(ctx context.Context) error { return nil }), 10*time.Millisecond)
+			if _, got := health.degraded(); got {
+				t.Error("expected a successful check to clear the degraded state")
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+// newRemoteServer runs the real routes over a memory store, standing in for another instance
+func newRemoteServer(t *testing.T) (*httptest.Server, *store.MemoryBlogStore) {
+	t.Helper()
+	log := logger.New(io.Discard, slog.LevelError)
+	backing := store.NewMemoryBlogStore()
+	mux := http.NewServeMux()
+	addRoutes(mux, log, &config.Config{}, backing, nil, nil, nil)
+	remote := httptest.NewServer(mux)
+	t.Cleanup(remote.Close)
+	return remote, backing
+}
+
+func TestHTTPBlogStore_CRUD(t *testing.T) {
+	remote, backing := newRemoteServer(t)
+	proxy := store.NewHTTPBlogStore(remote.URL+"/", remote.Client())
+	ctx := context.Background()
+
+	if err := proxy.Ping(ctx); err != nil {
+		t.Fatalf("expected ping to succeed, got %v", err)
+	}
+
+	blog := domain.NewBlog(domain.CreateBlogRequest{ID: "proxied", Title: "Hello World", Content: "Content", Author: "Author", Tags: []string{"go"}})
+	if err := proxy.Create(ctx, blog); err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	if blog.Slug != "hello-world" {
+		t.Errorf("expected the remote's slug, got %q", blog.Slug)
+	}
+	if _, err := backing.GetByID(ctx, "proxied"); err != nil {
+		t.Errorf("expected the blog in the remote store, got %v", err)
+	}
+	if err := proxy.Create(ctx, blog); !errors.Is(err, store.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a duplicate ID, got %v", err)
+	}
+	// 本文の重複は接続先が拒否し、ErrDuplicateContentとして返る
+	backing.SetDeduplicateContent(true)
+	duplicate := domain.NewBlog(domain.CreateBlogRequest{ID: "duplicate", Title: "Hello World", Content: "Content", Author: "Author"})
+	if err := proxy.Create(ctx, duplicate); !errors.Is(err, store.ErrDuplicateContent) {
+		t.Errorf("expected ErrDuplicateContent for identical content, got %v", err)
+	}
+	backing.SetDeduplicateContent(false)
+
+	got, err := proxy.GetByID(ctx, "proxied")
+	if err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	if got.Title != "Hello World" || got.Author != "Author" || !slices.Equal(got.Tags, []string{"go"}) {
+		t.Errorf("expected the blog to round-trip, got %+v", got)
+	}
+	// 接続先はスラッグでも検索するが、ストアとしてはIDの一致のみを返す
+	if _, err := proxy.GetByID(ctx, "hello-world"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a slug passed as ID, got %v", err)
+	}
+	if bySlug, err := proxy.GetBySlug(ctx, "hello-world"); err != nil || bySlug.ID != "proxied" {
+		t.Errorf("expected lookup by slug, got %v, %v", bySlug, err)
+	}
+
+	got.Title = "Updated"
+	got.Tags = []string{"go", "api"}
+	if err := proxy.Update(ctx, "proxied", got); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	updated, _ := backing.GetByID(ctx, "proxied")
+	if updated.Title != "Updated" || !slices.Equal(updated.Tags, []string{"go", "api"}) {
+		t.Errorf("expected title and tags updated remotely, got %+v", updated)
+	}
+
+	blogs, err := proxy.GetAll(ctx)
+	if err != nil || len(blogs) != 1 {
+		t.Fatalf("expected one blog from GetAll, got %v, %v", blogs, err)
+	}
+	if byAuthor, _ := proxy.GetByAuthor(ctx, "Author"); len(byAuthor) != 1 {
+		t.Errorf("expected one blog by author, got %d", len(byAuthor))
+	}
+	if stats, err := proxy.Stats(ctx); err != nil || stats.TotalBlogs != 1 {
+		t.Errorf("expected remote stats, got %+v, %v", stats, err)
+	}
+
+	if err := proxy.Delete(ctx, "proxied"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := proxy.GetByID(ctx, "proxied"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := proxy.Delete(ctx, "proxied"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestHTTPBlogStore_Fronting(t *testing.T) {
+	remote, backing := newRemoteServer(t)
+	log := logger.New(io.Discard, slog.LevelError)
+	mux := http.NewServeMux()
+	addRoutes(mux, log, &config.Config{}, store.NewHTTPBlogStore(remote.URL, remote.Client()), nil, nil, nil)
+
+	body := `{"title":"Fronted","content":"Content","author":"Author"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created domain.Blog
+	json.NewDecoder(w.Body).Decode(&created)
+
+	// 前段のサーバーで作成したブログが接続先に保存され、前段から取得できること
+	if _, err := backing.GetByID(context.Background(), created.ID); err != nil {
+		t.Fatalf("expected the blog in the remote store, got %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/blogs/"+created.ID, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"title":"Fronted"`) {
+		t.Errorf("expected the blog through the front server, got %d: %s", w.Code, w.Body.String())
+	}
+}
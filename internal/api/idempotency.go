@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idempotencyKeyHeader is the request header carrying a client-chosen idempotency key
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyStore remembers successful responses by idempotency key for ttl
+// タイムアウト後の再送などで同じ作成リクエストが二重に処理されないよう、
+// 同じキーの再リクエストには保存したレスポンスをそのまま返す
+// 期限切れのキーはget時に無視し、janitorが定期的にメモリから取り除く
+type idempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxKeys int
+	entries map[string]idempotencyEntry
+	now     func() time.Time // テスト時に時刻を制御するため注入可能
+}
+
+type idempotencyEntry struct {
+	status      int
+	contentType string
+	body        []byte
+	// requestHashは最初のリクエストボディのSHA-256。同じキーで別の内容を送った再リクエストを検出する
+	requestHash []byte
+	expiresAt   time.Time
+}
+
+// newIdempotencyStore creates a store keeping responses for ttl, at most maxKeys of them
+// ttlが0以下の場合はnilを返し、Idempotency-Keyを無視する。maxKeysが0以下の場合は上限なし
+func newIdempotencyStore(ttl time.Duration, maxKeys int) *idempotencyStore {
+	if ttl <= 0 {
+		return nil
+	}
+	return &idempotencyStore{
+		ttl:     ttl,
+		maxKeys: maxKeys,
+		entries: make(map[string]idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// scopedIdempotencyKey scopes the client's key to the client, method and path
+// キーはクライアントが選ぶ値のため、他のクライアントが同じキーを使っても別のレスポンスとして扱う
+func scopedIdempotencyKey(r *http.Request, key string) string {
+	return strings.Join([]string{clientKey(r), r.Method, r.URL.Path, key}, " ")
+}
+
+// get returns the unexpired response stored for key
+func (s *idempotencyStore) get(key string) (idempotencyEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !s.now().Before(entry.expiresAt) {
+		return idempotencyEntry{}, false
+	}
+	return entry, true
+}
+
+// put stores a response for key, expiring ttl from now
+// 上限に達している場合は期限切れのものを、それでも足りなければ最も古いものを取り除く
+func (s *idempotencyStore) put(key string, entry idempotencyEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if _, ok := s.entries[key]; !ok && s.maxKeys > 0 && len(s.entries) >= s.maxKeys {
+		s.evictLocked(now)
+	}
+	entry.expiresAt = now.Add(s.ttl)
+	s.entries[key] = entry
+}
+
+// evictLocked makes room for one entry; s.mu must be held
+func (s *idempotencyStore) evictLocked(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(s.entries) >= s.maxKeys {
+		delete(s.entries, oldestKey)
+	}
+}
+
+// evictExpired removes expired entries and returns how many were removed
+func (s *idempotencyStore) evictExpired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	removed := 0
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// janitorInterval is how often expired keys are evicted
+// TTLが短い場合はTTLごとに、長い場合でも1分ごとには掃除する
+func (s *idempotencyStore) janitorInterval() time.Duration {
+	return min(s.ttl, time.Minute)
+}
+
+// runJanitor evicts expired keys every interval until ctx is done
+// サーバーのコンテキストを渡し、シャットダウン時に確実に停止させる
+func (s *idempotencyStore) runJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.evictExpired()
+		}
+	}
+}
+
+// idempotencyMiddleware replays the stored response for a repeated Idempotency-Key
+// キーはクライアント・メソッド・パスごとに区別し、ボディが最初のリクエストと異なる再利用は422とする
+// 2xxのレスポンスのみ保存し、検証エラーなどは同じキーで再試行できるようにする
+// 同じキーの同時リクエストは排他しないため、両方が処理される可能性がある
+// keysがnilの場合（IDEMPOTENCY_TTL=0）はパススルー
+func idempotencyMiddleware(keys *idempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if keys == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key = scopedIdempotencyKey(r, key)
+
+			// ボディは全体を保持せず、読みながらハッシュを計算する（大きさはMAX_BODY_BYTESで制限済み）
+			hasher := sha256.New()
+			if entry, ok := keys.get(key); ok {
+				if _, err := io.Copy(hasher, r.Body); err != nil {
+					status, response := decodeErrorResponse(err)
+					encode(w, r, status, response)
+					return
+				}
+				if !bytes.Equal(hasher.Sum(nil), entry.requestHash) {
+					response := ErrorResponse{Error: "Idempotency-Key was already used with a different request body"}
+					encode(w, r, http.StatusUnprocessableEntity, response)
+					return
+				}
+				w.Header().Set("Content-Type", entry.contentType)
+				w.Header().Set("Idempotent-Replayed", strconv.FormatBool(true))
+				w.WriteHeader(entry.status)
+				w.Write(entry.body)
+				return
+			}
+
+			body := &hashingBody{ReadCloser: r.Body, hash: hasher}
+			r.Body = body
+			rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= 200 && rec.status < 300 {
+				// ハンドラーが読み残した分も含めてボディ全体のハッシュとする
+				if _, err := io.Copy(io.Discard, body); err != nil {
+					return
+				}
+				keys.put(key, idempotencyEntry{
+					status:      rec.status,
+					contentType: w.Header().Get("Content-Type"),
+					body:        rec.body.Bytes(),
+					requestHash: hasher.Sum(nil),
+				})
+			}
+		})
+	}
+}
+
+// hashingBody hashes a request body as it is read
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+}
+
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	return n, err
+}
+
+// recordingWriter passes a response through while keeping a copy of its status and body
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
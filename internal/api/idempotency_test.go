@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := newIdempotencyStore(time.Second, 0)
+	keys.now = func() time.Time { return now }
+
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	handler := idempotencyMiddleware(keys)(handleBlogsCreate(log, &config.Config{}, blogStore, nil))
+
+	create := func(key string) (*httptest.ResponseRecorder, domain.Blog) {
+		body := `{"title":"Title","content":"Content","author":"Author"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+		req.Header.Set(idempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		var blog domain.Blog
+		if err := json.Unmarshal(w.Body.Bytes(), &blog); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		return w, blog
+	}
+
+	first, created := create("key-1")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, first.Code)
+	}
+
+	replayed, again := create("key-1")
+	if replayed.Code != http.StatusCreated {
+		t.Errorf("expected replayed status %d, got %d", http.StatusCreated, replayed.Code)
+	}
+	if replayed.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected Idempotent-Replayed header on replay")
+	}
+	if again.ID != created.ID {
+		t.Errorf("expected replay of blog %s, got %s", created.ID, again.ID)
+	}
+
+	// TTLを過ぎたキーは保存済みのレスポンスを返さず、新たに作成する
+	now = now.Add(2 * time.Second)
+	expired, fresh := create("key-1")
+	if expired.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("expected expired key not to be replayed")
+	}
+	if fresh.ID == created.ID {
+		t.Error("expected a fresh blog after the key expired")
+	}
+
+	blogs, _ := blogStore.GetAll(context.Background())
+	if len(blogs) != 2 {
+		t.Errorf("expected 2 blogs to be created, got %d", len(blogs))
+	}
+}
+
+func TestIdempotencyMiddleware_FailureNotStored(t *testing.T) {
+	keys := newIdempotencyStore(time.Minute, 0)
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := idempotencyMiddleware(keys)(handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(`{`))
+	req.Header.Set(idempotencyKeyHeader, "key-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if _, ok := keys.get(scopedIdempotencyKey(req, "key-1")); ok {
+		t.Error("expected failed response not to be stored")
+	}
+}
+
+func TestIdempotencyMiddleware_Scope(t *testing.T) {
+	keys := newIdempotencyStore(time.Minute, 0)
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	handler := idempotencyMiddleware(keys)(handleBlogsCreate(log, &config.Config{}, blogStore, nil))
+
+	create := func(remoteAddr, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(idempotencyKeyHeader, "shared-key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	const body = `{"title":"Title","content":"Content","author":"Author"}`
+
+	if w := create("192.0.2.1:1234", body); w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	// 別のクライアントが同じキーを使っても、他人のレスポンスは返さず新たに作成する
+	other := create("192.0.2.2:1234", body)
+	if other.Code != http.StatusCreated || other.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expected a fresh create for another client, got %d (replayed=%q)", other.Code, other.Header().Get("Idempotent-Replayed"))
+	}
+
+	// 同じクライアントが同じキーを別のボディで使うと422
+	changed := create("192.0.2.1:5678", `{"title":"Other","content":"Content","author":"Author"}`)
+	if changed.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d for a different body, got %d", http.StatusUnprocessableEntity, changed.Code)
+	}
+
+	// 同じクライアントの同じボディは再送として扱う
+	if replayed := create("192.0.2.1:5678", body); replayed.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected a replay, got %d", replayed.Code)
+	}
+
+	blogs, _ := blogStore.GetAll(context.Background())
+	if len(blogs) != 2 {
+		t.Errorf("expected 2 blogs to be created, got %d", len(blogs))
+	}
+}
+
+func TestIdempotencyStore_MaxKeys(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := newIdempotencyStore(time.Hour, 2)
+	keys.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		keys.put(key, idempotencyEntry{status: http.StatusCreated})
+		now = now.Add(time.Second)
+	}
+
+	if n := len(keys.entries); n != 2 {
+		t.Errorf("expected at most 2 keys, got %d", n)
+	}
+	// 上限に達した場合は最も古いキーを取り除く
+	if _, ok := keys.get("a"); ok {
+		t.Error("expected the oldest key to be evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := keys.get(key); !ok {
+			t.Errorf("expected key %q to be kept", key)
+		}
+	}
+}
+
+func TestIdempotencyStore_Janitor(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := newIdempotencyStore(time.Second, 0)
+	keys.now = func() time.Time { return now }
+	keys.put("old", idempotencyEntry{status: http.StatusCreated})
+	now = now.Add(2 * time.Second)
+	keys.put("new", idempotencyEntry{status: http.StatusCreated})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		keys.runJanitor(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		keys.mu.Lock()
+		n := len(keys.entries)
+		keys.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected janitor to evict the expired key, %d entries remain", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok := keys.get("new"); !ok {
+		t.Error("expected unexpired key to be kept")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected janitor to stop after cancel")
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+)
+
+// jsonLimits bounds the structure of a JSON request body; 0 disables a limit
+// ボディサイズの上限内でも、深いネストや巨大な配列はデコード時に大量のメモリと時間を消費する
+// 現在のリクエスト型はほぼフラットだが、タグのような配列フィールドが増えても安全なように先に検査する
+type jsonLimits struct {
+	MaxDepth    int
+	MaxArrayLen int
+	MaxTokens   int
+	// RejectInvalidUTF8 rejects bodies containing malformed UTF-8 (INVALID_UTF8=reject)
+	RejectInvalidUTF8 bool
+}
+
+// jsonLimitsConfig builds jsonLimits from the MAX_JSON_* settings
+func jsonLimitsConfig(cfg *config.Config) jsonLimits {
+	return jsonLimits{
+		MaxDepth:    cfg.MaxJSONDepth,
+		MaxArrayLen: cfg.MaxJSONArrayLen,
+		MaxTokens:   cfg.MaxJSONTokens,
+
+		RejectInvalidUTF8: cfg.InvalidUTF8 == "reject",
+	}
+}
+
+// jsonLimitError reports a request body that exceeded jsonLimits
+type jsonLimitError struct {
+	Reason string
+}
+
+func (e *jsonLimitError) Error() string {
+	return "json limit exceeded: " + e.Reason
+}
+
+// invalidUTF8Error reports a request body containing malformed UTF-8
+type invalidUTF8Error struct {
+	Problems map[string]string
+}
+
+func (e *invalidUTF8Error) Error() string {
+	return "request body is not valid UTF-8"
+}
+
+// invalidUTF8Problems names the top-level fields of data holding malformed UTF-8, or nil if data is valid
+// encoding/jsonはデコード時に不正なバイトをU+FFFDに置き換えるため、Validメソッドでは検出できない
+// json.RawMessageは値のバイト列をそのまま保持するので、フィールドごとに元の値を検査できる
+func invalidUTF8Problems(data []byte) map[string]string {
+	if utf8.Valid(data) {
+		return nil
+	}
+	problems := make(map[string]string)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err == nil {
+		for field, value := range fields {
+			if !utf8.Valid(value) {
+				problems[field] = field + " must be valid UTF-8"
+			}
+		}
+	}
+	// キー自体が壊れている場合やオブジェクトでない場合はボディ全体として報告する
+	if len(problems) == 0 {
+		problems["body"] = "body must be valid UTF-8"
+	}
+	return problems
+}
+
+// jsonLimitsMiddleware makes decode check request bodies against limits
+// 全ての上限が0でUTF-8も検査しない場合はパススルー（ボディをバッファせずにそのままデコードする）
+func jsonLimitsMiddleware(limits jsonLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limits == (jsonLimits{}) {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), jsonLimitsKey, limits)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func jsonLimitsFromContext(ctx context.Context) (jsonLimits, bool) {
+	limits, ok := ctx.Value(jsonLimitsKey).(jsonLimits)
+	return limits, ok
+}
+
+// check walks the tokens read from r and fails as soon as a limit is exceeded
+// 構文エラーや途中で途切れたボディはここでは報告せず、後続のDecodeに任せて通常のエラー応答にする
+// 読み込み自体の失敗（サイズ超過など）はそのまま返す
+func (l jsonLimits) check(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	// 開いている配列ごとの要素数。オブジェクトは-1で、要素数を数えない
+	var open []int
+	tokens := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if err == io.EOF || err == io.ErrUnexpectedEOF || errors.As(err, &syntaxErr) {
+				return nil
+			}
+			return decodeError(err)
+		}
+		tokens++
+		if l.MaxTokens > 0 && tokens > l.MaxTokens {
+			return &jsonLimitError{Reason: fmt.Sprintf("body has more than %d tokens", l.MaxTokens)}
+		}
+
+		delim, isDelim := tok.(json.Delim)
+		if isDelim && (delim == ']' || delim == '}') {
+			open = open[:len(open)-1]
+			continue
+		}
+		// 配列の直下で値が始まるたびに要素として数える
+		if n := len(open); n > 0 && open[n-1] >= 0 {
+			open[n-1]++
+			if l.MaxArrayLen > 0 && open[n-1] > l.MaxArrayLen {
+				return &jsonLimitError{Reason: fmt.Sprintf("array has more than %d elements", l.MaxArrayLen)}
+			}
+		}
+		if isDelim {
+			if delim == '[' {
+				open = append(open, 0)
+			} else {
+				open = append(open, -1)
+			}
+			if l.MaxDepth > 0 && len(open) > l.MaxDepth {
+				return &jsonLimitError{Reason: fmt.Sprintf("nesting deeper than %d levels", l.MaxDepth)}
+			}
+		}
+	}
+}
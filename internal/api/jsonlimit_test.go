@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestJSONLimits_Check(t *testing.T) {
+	limits := jsonLimits{MaxDepth: 3, MaxArrayLen: 3, MaxTokens: 20}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "within limits", body: `{"title":"Title","tags":["a","b","c"]}`},
+		{name: "array too long", body: `{"tags":["a","b","c","d"]}`, wantErr: true},
+		{name: "nested arrays count their own elements", body: `[[1,2,3],[1,2,3],[1,2,3]]`},
+		{name: "objects count as one element", body: `[{"a":1,"b":2},{"c":3,"d":4},{}]`},
+		{name: "too deep", body: `{"a":{"b":{"c":{}}}}`, wantErr: true},
+		{name: "too many tokens", body: `{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":8,"i":9,"j":10}`, wantErr: true},
+		{name: "syntax errors left to the decoder", body: `{"title":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.check(strings.NewReader(tt.body))
+			var limitErr *jsonLimitError
+			if got := errors.As(err, &limitErr); got != tt.wantErr {
+				t.Errorf("expected limit error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// endlessReader returns an unbounded stream of the same byte
+type endlessReader byte
+
+func (b endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+func TestJSONLimits_CheckStopsReading(t *testing.T) {
+	limits := jsonLimits{MaxArrayLen: 3}
+
+	// 上限を超えた時点で拒否し、続きのボディは読まない（読み続けると終わらない）
+	body := io.MultiReader(strings.NewReader(`{"tags":[1,2,3,4`), endlessReader(' '))
+	err := limits.check(body)
+	var limitErr *jsonLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected limit error, got %v", err)
+	}
+}
+
+func TestHandleBlogsCreate_JSONLimits(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	handler := jsonLimitsMiddleware(jsonLimits{MaxArrayLen: 100})(
+		handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil),
+	)
+
+	// タグの検証より前に、配列の長さだけで拒否される
+	tags := `"go"` + strings.Repeat(`,"go"`, 10000)
+	body := `{"title":"Title","content":"Content","author":"Author","tags":[` + tags + `]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Code != "json_limit" || resp.Problems["body"] == "" {
+		t.Errorf("expected json_limit error with a body problem, got %+v", resp)
+	}
+}
+
+func TestHandleBlogsCreate_InvalidUTF8(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	body := "{\"title\":\"Ti\xfftle\",\"content\":\"Content\",\"author\":\"Author\"}"
+
+	tests := []struct {
+		name       string
+		limits     jsonLimits
+		wantStatus int
+		wantTitle  string
+	}{
+		{name: "reject", limits: jsonLimits{RejectInvalidUTF8: true}, wantStatus: http.StatusBadRequest},
+		// replaceではencoding/jsonの既定どおりU+FFFDに置き換えて受け付ける
+		{name: "replace", limits: jsonLimits{}, wantStatus: http.StatusCreated, wantTitle: "Ti�tle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := jsonLimitsMiddleware(tt.limits)(
+				handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil),
+			)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusBadRequest {
+				var blog struct{ Title string }
+				json.NewDecoder(w.Body).Decode(&blog)
+				if blog.Title != tt.wantTitle {
+					t.Errorf("expected title %q, got %q", tt.wantTitle, blog.Title)
+				}
+				return
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Code != "invalid_utf8" || resp.Problems["title"] != "title must be valid UTF-8" || len(resp.Problems) != 1 {
+				t.Errorf("expected a title UTF-8 problem only, got %+v", resp)
+			}
+		})
+	}
+}
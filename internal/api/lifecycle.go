@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Component is a background part of the server stopped during shutdown
+// janitorやスイーパーなど、リクエストとは別に動き続けるgoroutineを持つものが実装する
+type Component interface {
+	// Stop stops the component and waits for it to finish, giving up when ctx is done
+	Stop(ctx context.Context) error
+}
+
+// namedComponent is a registered component with the name used in logs
+type namedComponent struct {
+	name      string
+	component Component
+}
+
+// RegisterComponent adds a component that is stopped after HTTP requests have drained
+// 後から登録したものほど先に停止し（deferと同じ順序）、その後でシャットダウンフックを実行する
+// 全てのコンポーネントにはSHUTDOWN_TIMEOUTのコンテキストが渡される（全体で共有）
+func (s *Server) RegisterComponent(name string, c Component) {
+	s.componentsMu.Lock()
+	defer s.componentsMu.Unlock()
+	s.components = append(s.components, namedComponent{name: name, component: c})
+}
+
+// stopComponents stops every registered component, newest first, and joins their errors
+// 失敗したコンポーネントがあっても残りは停止させる
+func (s *Server) stopComponents(ctx context.Context) error {
+	s.componentsMu.Lock()
+	components := slices.Clone(s.components)
+	s.componentsMu.Unlock()
+
+	var errs []error
+	for _, c := range slices.Backward(components) {
+		if err := c.component.Stop(ctx); err != nil {
+			s.logger.Error(ctx, "failed to stop component", "component", c.name, "error", err)
+			errs = append(errs, fmt.Errorf("stop component %s: %w", c.name, err))
+			continue
+		}
+		s.logger.Info(ctx, "component stopped", "component", c.name)
+	}
+	if len(errs) > 0 {
+		s.logger.Error(ctx, "some components failed to stop", "failed", len(errs), "total", len(components))
+	}
+	return errors.Join(errs...)
+}
+
+// background is a Component running a function in its own goroutine until stopped
+type background struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startBackground runs fn in a goroutine with a context cancelled by Stop
+// 呼び出し元のキャンセルでは止まらず（値は引き継ぐ）、HTTPの処理が終わるまで動き続ける
+func startBackground(ctx context.Context, fn func(ctx context.Context)) *background {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	b := &background{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(b.done)
+		fn(ctx)
+	}()
+	return b
+}
+
+// Stop cancels the goroutine and waits for it to return
+func (b *background) Stop(ctx context.Context) error {
+	b.cancel()
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait for goroutine: %w", ctx.Err())
+	}
+}
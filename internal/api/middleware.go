@@ -1,9 +1,20 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
+	"mime"
+	"net"
 	"net/http"
+	"net/netip"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/moko-poi/blog-api-server/internal/domain"
 	"github.com/moko-poi/blog-api-server/internal/logger"
 )
 
@@ -15,6 +26,11 @@ func loggingMiddleware(log *logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			log.Debug(r.Context(), "request started",
+				"request_id", requestIDFromContext(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
 
 			// レスポンスライターをラップしてステータスコードをキャプチャ
 			// Mat Ryerのパターン: 構造化ログでリクエスト詳細を記録
@@ -32,8 +48,10 @@ func loggingMiddleware(log *logger.Logger) func(http.Handler) http.Handler {
 			// 構造化ログでリクエスト情報を記録
 			// キー・バリュー形式で後の解析が容易
 			log.Info(r.Context(), "request completed",
+				"request_id", requestIDFromContext(r.Context()),
 				"method", r.Method,
 				"path", r.URL.Path,
+				"route", RouteTemplateFromContext(r.Context()),
 				"status", wrapped.statusCode,
 				"duration", duration,
 				"remote_addr", r.RemoteAddr,
@@ -56,10 +74,16 @@ func (w *responseWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // corsMiddleware adds CORS headers
 // CORS（Cross-Origin Resource Sharing）対応
 // フロントエンドアプリケーションからのAPIアクセスを可能にする
-func corsMiddleware() func(http.Handler) http.Handler {
+// routeOptionsが有効な場合、OPTIONSはルートに渡し、各ルートがAllowヘッダー付きで応答する（withMethods）
+func corsMiddleware(routeOptions bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// 本番環境では "*" ではなく、特定のオリジンを指定することを推奨
@@ -68,7 +92,7 @@ func corsMiddleware() func(http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 			// プリフライトリクエスト（OPTIONS）への対応
-			if r.Method == "OPTIONS" {
+			if r.Method == "OPTIONS" && !routeOptions {
 				w.WriteHeader(http.StatusOK)
 				return
 			}
@@ -95,9 +119,7 @@ func panicRecoveryMiddleware(log *logger.Logger) func(http.Handler) http.Handler
 					)
 
 					// クライアントには内部エラーとして500を返す
-					// セキュリティ上、パニックの詳細は隠蔽
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusInternalServerError)
+					// セキュリティ上、パニックの詳細は隠蔽し、照会用にリクエストIDのみ返す（encodeが付与）
 					response := ErrorResponse{
 						Error: "Internal server error",
 					}
@@ -112,16 +134,506 @@ func panicRecoveryMiddleware(log *logger.Logger) func(http.Handler) http.Handler
 
 // ratelimitMiddleware is a simple in-memory rate limiter
 // レート制限機能 - DoS攻撃対策
+// IPアドレス単位のトークンバケットで制限し、超過時は429を返す
+// limiterがnilの場合（未設定時）はパススルー
 // Mat Ryerの注記: 本番環境ではRedisなど外部ストアを使用すべき
-func ratelimitMiddleware() func(http.Handler) http.Handler {
+func ratelimitMiddleware(limiter *rateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.allow(clientKey(r)) {
+				w.Header().Set("Retry-After", "1")
+				response := ErrorResponse{Error: "Too many requests"}
+				encode(w, r, http.StatusTooManyRequests, response)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// concurrencyLimitMiddleware caps in-flight requests per client IP
+// 上限を超えたリクエストは429を返し、スロットはハンドラーの完了時（パニック時も含む）に解放する
+// max <= 0 の場合はパススルー
+func concurrencyLimitMiddleware(max int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+		limiter := newConcurrencyLimiter(max)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := clientKey(r)
+			if !limiter.acquire(key) {
+				w.Header().Set("Retry-After", "1")
+				response := ErrorResponse{Error: "Too many concurrent requests"}
+				encode(w, r, http.StatusTooManyRequests, response)
+				return
+			}
+			defer limiter.release(key)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// adminAuthMiddleware guards admin endpoints with a shared bearer token
+// トークン未設定時は管理APIを無効とし、常に403を返す
+// タイミング攻撃を避けるため定数時間比較を使用
+func adminAuthMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				response := ErrorResponse{Error: "Admin API is disabled"}
+				encode(w, r, http.StatusForbidden, response)
+				return
+			}
+
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				response := ErrorResponse{Error: "Unauthorized"}
+				encode(w, r, http.StatusUnauthorized, response)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readOnlyMiddleware rejects write requests while the server is in read-only mode
+// メンテナンス中に参照系は動かしたまま、更新系のリクエストだけを503で拒否する
+// 無効時は何もせず次のハンドラーをそのまま返す
+func readOnlyMiddleware(readOnly bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !readOnly {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+			default:
+				response := ErrorResponse{Error: "Server is in read-only mode"}
+				encode(w, r, http.StatusServiceUnavailable, response)
+			}
+		})
+	}
+}
+
+// supportedMethods are the HTTP methods the API serves
+var supportedMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// disabledMethodsMiddleware rejects globally disabled HTTP methods with 405
+// 読み取り専用ミラーなどでルーティング前にメソッド単位で禁止する
+// Allowヘッダーには禁止されていないメソッドを列挙する
+func disabledMethodsMiddleware(disabled []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(disabled) == 0 {
+			return next
+		}
+
+		var allowed []string
+		for _, method := range supportedMethods {
+			if !slices.Contains(disabled, method) {
+				allowed = append(allowed, method)
+			}
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(disabled, r.Method) {
+				methodNotAllowed(w, r, allowed...)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// urlLimitMiddleware rejects requests with an overlong URL or too many query parameters
+// 巨大なクエリ文字列によるパース負荷を避けるため、パラメーター数は
+// ParseQueryを呼ばずに区切り文字を数えて判定する
+// それぞれ0以下の場合はその制限を無効にする
+func urlLimitMiddleware(maxLength, maxParams int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxLength <= 0 && maxParams <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxLength > 0 && len(r.RequestURI) > maxLength {
+				response := ErrorResponse{Error: "URI too long"}
+				encode(w, r, http.StatusRequestURITooLong, response)
+				return
+			}
+			if maxParams > 0 && countQueryParams(r.URL.RawQuery) > maxParams {
+				response := ErrorResponse{Error: "Too many query parameters"}
+				encode(w, r, http.StatusRequestURITooLong, response)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// countQueryParams counts the non-empty &-separated pairs in a raw query
+func countQueryParams(rawQuery string) int {
+	n := 0
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// bodyLimitMiddleware caps the size of request bodies
+// Content-Lengthが上限を超えている場合はボディを読まずに413を返し、
+// チャンク転送などContent-Lengthがない場合はMaxBytesReaderで読み取り時に制限する
+// maxBytesが0以下の場合は無効
+func bodyLimitMiddleware(maxBytes int64) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// シンプルなレート制限ロジックをここに実装
-			// 現在はパススルーだが、本番環境では以下のような実装が必要:
-			// - IPアドレス単位での制限
-			// - トークンバケットアルゴリズム
-			// - Redis等を使った分散対応
+			if r.ContentLength > maxBytes {
+				// 残りのボディを読まずに接続を閉じる
+				w.Header().Set("Connection", "close")
+				response := ErrorResponse{Error: "Request body too large"}
+				encode(w, r, http.StatusRequestEntityTooLarge, response)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			// gzipで送られたボディは展開後のサイズも同じ上限で制限する（requestBodyを参照）
+			ctx := context.WithValue(r.Context(), bodyLimitKey, maxBytes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bodyLimitFromContext returns the body size limit for the request, or 0 if unlimited
+func bodyLimitFromContext(ctx context.Context) int64 {
+	limit, _ := ctx.Value(bodyLimitKey).(int64)
+	return limit
+}
+
+// drainMiddleware rejects new requests once the server has started shutting down
+// http.Server.Shutdownは新規接続を受け付けなくなるが、keep-alive接続上では
+// 新しいリクエストが届くことがあるため、Connection: closeを付けて503を返し
+// クライアントに別のインスタンスへ再接続させる
+// フラグが立つ前に受け付けた処理中のリクエストはそのまま完了させる
+func drainMiddleware(draining *atomic.Bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if draining.Load() {
+				w.Header().Set("Connection", "close")
+				response := ErrorResponse{Error: "Server is shutting down"}
+				encode(w, r, http.StatusServiceUnavailable, response)
+				return
+			}
+
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// validationMiddleware makes the configured validation rules available to the Valid methods
+// Validatorインターフェースはctxのみを受け取るため、コンテキスト経由でルールを渡す
+func validationMiddleware(cfg domain.ValidationConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := domain.ContextWithValidationConfig(r.Context(), cfg)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// cleanPathMiddleware normalizes request paths before routing
+// 重複スラッシュや . / .. セグメントをpath.Cleanで解決する
+// 末尾スラッシュは /api/v1/blogs/ のようなプレフィックスルートのために保持する
+// redirectがtrueの場合は書き換えではなく正規化後のパスへリダイレクトする
+func cleanPathMiddleware(redirect bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cleaned := cleanPath(r.URL.Path)
+			if cleaned == r.URL.Path {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if redirect {
+				u := *r.URL
+				u.Path = cleaned
+				u.RawPath = ""
+				// GET/HEAD以外はメソッドとボディを維持するため308を使う
+				status := http.StatusPermanentRedirect
+				if r.Method == http.MethodGet || r.Method == http.MethodHead {
+					status = http.StatusMovedPermanently
+				}
+				http.Redirect(w, r, u.String(), status)
+				return
+			}
+
+			r2 := new(http.Request)
+			*r2 = *r
+			u := *r.URL
+			u.Path = cleaned
+			u.RawPath = ""
+			r2.URL = &u
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+// canonicalHostMiddleware redirects requests for any other Host to the canonical host
+// パスとクエリ、スキーム（TLSの有無）はそのまま維持する
+// ロードバランサーなどがIPアドレスで叩くヘルスチェックはリダイレクトしない
+// hostが空の場合はパススルー
+func canonicalHostMiddleware(host string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if host == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Host, host) || r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			u := *r.URL
+			u.Scheme = "http"
+			if r.TLS != nil {
+				u.Scheme = "https"
+			}
+			u.Host = host
+			// cleanPathMiddlewareと同様、GET/HEAD以外はメソッドとボディを維持するため308を使う
+			status := http.StatusPermanentRedirect
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			http.Redirect(w, r, u.String(), status)
+		})
+	}
+}
+
+// hstsMiddleware sets Strict-Transport-Security on every response
+// HTTPで返したレスポンスのヘッダーはブラウザが無視するため、スキームによる出し分けはしない
+// maxAgeが0の場合はパススルー
+func hstsMiddleware(maxAge time.Duration, includeSubdomains bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxAge <= 0 {
+			return next
+		}
+		value := "max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+		if includeSubdomains {
+			value += "; includeSubDomains"
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Strict-Transport-Security", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// httpsRedirectMiddleware redirects plain HTTP requests to the same URL over HTTPS
+// TLSを終端するプロキシの背後での利用を想定し、X-Forwarded-Proto: https のリクエストはHTTPSとみなす
+// canonicalHostMiddlewareと同様、ヘルスチェックはリダイレクトしない
+func httpsRedirectMiddleware(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			isHTTPS := r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+			if isHTTPS || r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			u := *r.URL
+			u.Scheme = "https"
+			u.Host = r.Host
+			// GET/HEAD以外はメソッドとボディを維持するため308を使う
+			status := http.StatusPermanentRedirect
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			http.Redirect(w, r, u.String(), status)
+		})
+	}
+}
+
+// requiredHeaderMiddleware rejects requests whose header name is not exactly value with 403
+// ゲートウェイが付与する共有シークレットを検査し、ゲートウェイを経由しない直接のアクセスを拒否する
+// 値の比較は定数時間で行う。ヘルスチェックはオーケストレーターから直接届くため対象外
+func requiredHeaderMiddleware(name, value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if name == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get(name)
+			if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" || subtle.ConstantTimeCompare([]byte(provided), []byte(value)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			response := ErrorResponse{Error: "Forbidden"}
+			encode(w, r, http.StatusForbidden, response)
+		})
+	}
+}
+
+// cleanPath returns the canonical form of p, keeping a trailing slash
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// defaultMediaTypes are the response media types of routes not listed in routeMediaTypes
+var defaultMediaTypes = []string{"application/json"}
+
+// routeMediaTypes are the response media types of routes that do not answer with JSON
+// パターンはaddRoutesで登録したものと同じ書式
+var routeMediaTypes = map[string][]string{
+	"/api/v1/blogs/export":       {"application/x-ndjson"},
+	"/api/v1/blogs/{id}/content": {"text/plain"},
+}
+
+// routeMediaTypesMux matches request paths against the patterns of routeMediaTypes
+var routeMediaTypesMux = func() *http.ServeMux {
+	mux := http.NewServeMux()
+	for pattern := range routeMediaTypes {
+		mux.Handle(pattern, http.NotFoundHandler())
+	}
+	return mux
+}()
+
+// supportedMediaTypes returns the response media types of the route matching r
+func supportedMediaTypes(r *http.Request) []string {
+	_, pattern := routeMediaTypesMux.Handler(r)
+	if mediaTypes, ok := routeMediaTypes[pattern]; ok {
+		return mediaTypes
+	}
+	return defaultMediaTypes
+}
+
+// acceptMiddleware answers 406 when the Accept header excludes every media type of the route
+// デフォルト（strict=false）では従来どおりAcceptに関わらずJSONを返す
+// Accept未指定とOPTIONS（CORSプリフライト）は常に通す
+func acceptMiddleware(strict bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !strict {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept := r.Header.Get("Accept")
+			mediaTypes := supportedMediaTypes(r)
+			if accept == "" || r.Method == http.MethodOptions || acceptable(accept, mediaTypes) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			response := ErrorResponse{
+				Error: "Not Acceptable",
+				Problems: map[string]string{
+					"accept": "supported media types: " + strings.Join(mediaTypes, ", "),
+				},
+			}
+			encode(w, r, http.StatusNotAcceptable, response)
+		})
+	}
+}
+
+// acceptable reports whether an Accept header admits any of mediaTypes
+// 解析できない要素と q=0 の要素は無視する。text/* のような範囲も解釈する
+func acceptable(accept string, mediaTypes []string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if weight, err := strconv.ParseFloat(q, 64); err != nil || weight <= 0 {
+				continue
+			}
+		}
+		if mediaType == "*/*" || slices.Contains(mediaTypes, mediaType) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(mediaType, "/*"); ok && slices.ContainsFunc(mediaTypes, func(t string) bool {
+			return strings.HasPrefix(t, prefix+"/")
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// logLevelMiddleware applies the X-Log-Level header to the request's context
+// 本番で特定のリクエストだけdebugログを出すための仕組みで、ログ量の増加を悪用されないよう
+// 信頼するプロキシからの接続か、管理トークンを持つリクエストでのみ受け付ける
+// それ以外から送られたヘッダーや、不明なレベルは黙って無視する
+func logLevelMiddleware(trusted []netip.Prefix, adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 && adminToken == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get("X-Log-Level")
+			if value == "" || !trustedRequest(r, trusted, adminToken) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			level, err := logger.ParseLevel(value)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(logger.WithLevel(r.Context(), level)))
+		})
+	}
+}
+
+// trustedRequest reports whether r comes from a trusted proxy or carries the admin token
+// X-Forwarded-Forは偽装できるため、直接の接続元アドレスのみで判定する
+func trustedRequest(r *http.Request, trusted []netip.Prefix, adminToken string) bool {
+	if adminToken != "" {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) == 1 {
+			return true
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
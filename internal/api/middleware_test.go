@@ -2,13 +2,22 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
 	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
 )
 
 func TestLoggingMiddleware(t *testing.T) {
@@ -16,12 +25,12 @@ func TestLoggingMiddleware(t *testing.T) {
 	log := logger.New(&logOutput, slog.LevelInfo)
 
 	middleware := loggingMiddleware(log)
-	
+
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusCreated)
 		w.Write([]byte("test response"))
 	})
-	
+
 	wrappedHandler := middleware(handler)
 
 	req := httptest.NewRequest(http.MethodPost, "/test", nil)
@@ -57,12 +66,12 @@ func TestLoggingMiddleware_DefaultStatus(t *testing.T) {
 	log := logger.New(&logOutput, slog.LevelInfo)
 
 	middleware := loggingMiddleware(log)
-	
+
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Don't explicitly set status code, should default to 200
 		w.Write([]byte("test response"))
 	})
-	
+
 	wrappedHandler := middleware(handler)
 
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -95,12 +104,12 @@ func TestResponseWriter_WriteHeader(t *testing.T) {
 }
 
 func TestCorsMiddleware(t *testing.T) {
-	middleware := corsMiddleware()
-	
+	middleware := corsMiddleware(false)
+
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
-	
+
 	wrappedHandler := middleware(handler)
 
 	t.Run("normal request", func(t *testing.T) {
@@ -140,11 +149,11 @@ func TestPanicRecoveryMiddleware(t *testing.T) {
 	log := logger.New(&logOutput, slog.LevelError)
 
 	middleware := panicRecoveryMiddleware(log)
-	
+
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		panic("test panic")
 	})
-	
+
 	wrappedHandler := middleware(handler)
 
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -181,12 +190,12 @@ func TestPanicRecoveryMiddleware_NoPanic(t *testing.T) {
 	log := logger.New(&logOutput, slog.LevelError)
 
 	middleware := panicRecoveryMiddleware(log)
-	
+
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("normal response"))
 	})
-	
+
 	wrappedHandler := middleware(handler)
 
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -210,13 +219,13 @@ func TestPanicRecoveryMiddleware_NoPanic(t *testing.T) {
 }
 
 func TestRatelimitMiddleware(t *testing.T) {
-	middleware := ratelimitMiddleware()
-	
+	middleware := ratelimitMiddleware(nil)
+
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("success"))
 	})
-	
+
 	wrappedHandler := middleware(handler)
 
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -224,7 +233,7 @@ func TestRatelimitMiddleware(t *testing.T) {
 
 	wrappedHandler.ServeHTTP(w, req)
 
-	// Currently rate limiting is a pass-through, so should work normally
+	// Without a configured limiter rate limiting is a pass-through
 	if w.Code != http.StatusOK {
 		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
 	}
@@ -232,4 +241,861 @@ func TestRatelimitMiddleware(t *testing.T) {
 	if w.Body.String() != "success" {
 		t.Errorf("expected success response, got %q", w.Body.String())
 	}
-}
\ No newline at end of file
+}
+func TestReadOnlyMiddleware(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	mux := http.NewServeMux()
+	addRoutes(mux, log, &config.Config{}, blogStore, nil, nil, nil)
+
+	wrappedHandler := readOnlyMiddleware(true)(mux)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "GET blogs allowed",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "GET stats allowed",
+			method:         http.MethodGet,
+			path:           "/api/v1/stats",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "POST blogs rejected",
+			method:         http.MethodPost,
+			path:           "/api/v1/blogs",
+			body:           `{"title":"Title","content":"Content","author":"Author"}`,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "PUT blog rejected",
+			method:         http.MethodPut,
+			path:           "/api/v1/blogs/some-id",
+			body:           `{"title":"Title"}`,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "DELETE blog rejected",
+			method:         http.MethodDelete,
+			path:           "/api/v1/blogs/some-id",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			wrappedHandler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusServiceUnavailable {
+				var resp ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to unmarshal error response: %v", err)
+				}
+				if resp.Error != "Server is in read-only mode" {
+					t.Errorf("expected read-only error, got %q", resp.Error)
+				}
+			}
+		})
+	}
+
+	// Verify nothing was written through the middleware
+	blogs, _ := blogStore.GetAll(context.Background())
+	if len(blogs) != 0 {
+		t.Errorf("expected no blogs to be created in read-only mode, got %d", len(blogs))
+	}
+}
+
+func TestReadOnlyMiddleware_Disabled(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	wrappedHandler := readOnlyMiddleware(false)(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	w := httptest.NewRecorder()
+
+	wrappedHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+}
+
+func TestDrainMiddleware(t *testing.T) {
+	draining := new(atomic.Bool)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("done"))
+	})
+
+	wrappedHandler := drainMiddleware(draining)(handler)
+
+	// In-flight request accepted before draining starts
+	inFlight := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		wrappedHandler.ServeHTTP(inFlight, req)
+	}()
+	<-started
+
+	draining.Store(true)
+
+	// New request after draining starts
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	wrappedHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d while draining, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if w.Header().Get("Connection") != "close" {
+		t.Errorf("expected Connection 'close', got %q", w.Header().Get("Connection"))
+	}
+
+	close(release)
+	<-finished
+
+	if inFlight.Code != http.StatusOK {
+		t.Errorf("expected in-flight request to complete with %d, got %d", http.StatusOK, inFlight.Code)
+	}
+	if inFlight.Body.String() != "done" {
+		t.Errorf("expected in-flight response 'done', got %q", inFlight.Body.String())
+	}
+}
+
+func TestDrainMiddleware_NotDraining(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrappedHandler := drainMiddleware(new(atomic.Bool))(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+
+	wrappedHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Header().Get("Connection") != "" {
+		t.Errorf("expected no Connection header, got %q", w.Header().Get("Connection"))
+	}
+}
+
+func TestValidationMiddleware(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{MaxTitleLen: 200, MaxContentLen: 5000, MaxAuthorLen: 50}
+	handler := validationMiddleware(validationConfig(cfg))(handleBlogsCreate(log, cfg, store.NewMemoryBlogStore(), nil))
+
+	body := `{"title":"` + strings.Repeat("a", 150) + `","content":"Content","author":"Author"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d with raised title limit, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+}
+
+func TestValidationMiddleware_MaxProblems(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{MaxTitleLen: 100, MaxContentLen: 5000, MaxAuthorLen: 50, MaxValidationProblems: 2}
+	handler := validationMiddleware(validationConfig(cfg))(handleBlogsCreate(log, cfg, store.NewMemoryBlogStore(), nil))
+
+	// id、title、content、author、tagsの5項目が全て不正
+	body := `{"id":"a/b","title":"","content":"","author":"","tags":["web api"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	// 上限の2件に、省略した件数の注記が加わる
+	if len(resp.Problems) != 3 {
+		t.Errorf("expected 2 problems and a note, got %v", resp.Problems)
+	}
+	if resp.Problems["author"] == "" || resp.Problems["content"] == "" {
+		t.Errorf("expected the first problems in field order to be kept, got %v", resp.Problems)
+	}
+	if got := resp.Problems[domain.TruncatedProblemsKey]; got != "3 more problems omitted" {
+		t.Errorf("expected a truncation note, got %q", got)
+	}
+}
+
+func TestCleanPathMiddleware(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.Create(context.Background(), domain.NewBlog(domain.CreateBlogRequest{Title: "T", Content: "C", Author: "A"}))
+	mux := http.NewServeMux()
+	addRoutes(mux, log, &config.Config{}, blogStore, nil, nil, nil)
+
+	wrappedHandler := cleanPathMiddleware(false)(mux)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		checkBody      func(t *testing.T, body []byte)
+	}{
+		{
+			name:           "doubled slash in blogs path",
+			path:           "/api/v1//blogs",
+			expectedStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var blogs []*domain.Blog
+				if err := json.Unmarshal(body, &blogs); err != nil {
+					t.Fatalf("expected blog list, got %s", body)
+				}
+				if len(blogs) != 1 {
+					t.Errorf("expected 1 blog, got %d", len(blogs))
+				}
+			},
+		},
+		{
+			name:           "leading doubled slash",
+			path:           "//healthz",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "dot segment",
+			path:           "/api/v1/./stats",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "dot-dot segment",
+			path:           "/api/v1/blogs/../stats",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "trailing slash prefix route is kept",
+			path:           "/api/v1/blogs/",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "doubled slash before ID",
+			path:           "/api/v1/blogs//non-existent",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			wrappedHandler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCanonicalHostMiddleware(t *testing.T) {
+	handler := canonicalHostMiddleware("blog.example.com")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name             string
+		method           string
+		target           string
+		host             string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name:             "wrong host is redirected",
+			method:           http.MethodGet,
+			target:           "/api/v1/blogs?author=a",
+			host:             "www.example.com",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "http://blog.example.com/api/v1/blogs?author=a",
+		},
+		{
+			name:             "TLS keeps https",
+			method:           http.MethodGet,
+			target:           "https://www.example.com/api/v1/stats",
+			host:             "www.example.com",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://blog.example.com/api/v1/stats",
+		},
+		{
+			name:             "POST keeps its method",
+			method:           http.MethodPost,
+			target:           "/api/v1/blogs",
+			host:             "www.example.com",
+			expectedStatus:   http.StatusPermanentRedirect,
+			expectedLocation: "http://blog.example.com/api/v1/blogs",
+		},
+		{
+			name:           "matching host passes through",
+			method:         http.MethodGet,
+			target:         "/api/v1/blogs",
+			host:           "Blog.Example.com",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "health check is not redirected",
+			method:         http.MethodGet,
+			target:         "/healthz",
+			host:           "10.0.0.5:8080",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != tt.expectedLocation {
+				t.Errorf("expected Location %q, got %q", tt.expectedLocation, loc)
+			}
+		})
+	}
+}
+
+func TestHSTSMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name              string
+		maxAge            time.Duration
+		includeSubdomains bool
+		expected          string
+	}{
+		{name: "disabled", maxAge: 0, expected: ""},
+		{name: "max-age only", maxAge: 365 * 24 * time.Hour, expected: "max-age=31536000"},
+		{name: "with subdomains", maxAge: time.Hour, includeSubdomains: true, expected: "max-age=3600; includeSubDomains"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := hstsMiddleware(tt.maxAge, tt.includeSubdomains)(ok)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil))
+
+			if got := w.Header().Get("Strict-Transport-Security"); got != tt.expected {
+				t.Errorf("expected Strict-Transport-Security %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRequiredHeaderMiddleware(t *testing.T) {
+	handler := requiredHeaderMiddleware("X-Gateway-Auth", "s3cret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		path           string
+		header         string
+		expectedStatus int
+	}{
+		{name: "correct header passes", path: "/api/v1/blogs", header: "s3cret", expectedStatus: http.StatusOK},
+		{name: "wrong header is forbidden", path: "/api/v1/blogs", header: "guess", expectedStatus: http.StatusForbidden},
+		{name: "prefix of the value is forbidden", path: "/api/v1/blogs", header: "s3c", expectedStatus: http.StatusForbidden},
+		{name: "missing header is forbidden", path: "/api/v1/blogs", expectedStatus: http.StatusForbidden},
+		{name: "health check is exempt", path: "/healthz", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("X-Gateway-Auth", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHTTPSRedirectMiddleware(t *testing.T) {
+	handler := httpsRedirectMiddleware(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name             string
+		method           string
+		target           string
+		forwardedProto   string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name:             "plain HTTP is redirected",
+			method:           http.MethodGet,
+			target:           "/api/v1/blogs?author=a",
+			forwardedProto:   "http",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://blog.example.com/api/v1/blogs?author=a",
+		},
+		{
+			name:           "forwarded HTTPS passes through",
+			method:         http.MethodGet,
+			target:         "/api/v1/blogs",
+			forwardedProto: "https",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "direct TLS passes through",
+			method:         http.MethodGet,
+			target:         "https://blog.example.com/api/v1/blogs",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:             "POST keeps its method",
+			method:           http.MethodPost,
+			target:           "/api/v1/blogs",
+			expectedStatus:   http.StatusPermanentRedirect,
+			expectedLocation: "https://blog.example.com/api/v1/blogs",
+		},
+		{
+			name:           "health check is not redirected",
+			method:         http.MethodGet,
+			target:         "/healthz",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Host = "blog.example.com"
+			if tt.forwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != tt.expectedLocation {
+				t.Errorf("expected Location %q, got %q", tt.expectedLocation, loc)
+			}
+		})
+	}
+}
+
+func TestCleanPathMiddleware_Redirect(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrappedHandler := cleanPathMiddleware(true)(handler)
+
+	t.Run("GET is redirected with 301", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1//blogs?author=a", nil)
+		w := httptest.NewRecorder()
+
+		wrappedHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusMovedPermanently {
+			t.Errorf("expected status %d, got %d", http.StatusMovedPermanently, w.Code)
+		}
+		if loc := w.Header().Get("Location"); loc != "/api/v1/blogs?author=a" {
+			t.Errorf("expected Location '/api/v1/blogs?author=a', got %q", loc)
+		}
+	})
+
+	t.Run("POST is redirected with 308", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/./v1/blogs", nil)
+		w := httptest.NewRecorder()
+
+		wrappedHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusPermanentRedirect {
+			t.Errorf("expected status %d, got %d", http.StatusPermanentRedirect, w.Code)
+		}
+	})
+
+	t.Run("clean path passes through", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
+		w := httptest.NewRecorder()
+
+		wrappedHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	var handlerCalled bool
+	create := handleBlogsCreate(log, &config.Config{}, store.NewMemoryBlogStore(), nil)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerCalled = true
+		create.ServeHTTP(w, r)
+	})
+
+	wrappedHandler := bodyLimitMiddleware(64)(handler)
+
+	validBody := `{"title":"T","content":"C","author":"A"}`
+	largeBody := `{"title":"T","content":"` + strings.Repeat("x", 100) + `","author":"A"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		contentLength  int64
+		expectedStatus int
+		expectHandler  bool
+	}{
+		{
+			name:           "body within limit",
+			body:           validBody,
+			contentLength:  int64(len(validBody)),
+			expectedStatus: http.StatusCreated,
+			expectHandler:  true,
+		},
+		{
+			name:           "declared Content-Length over limit is rejected before reading",
+			body:           validBody,
+			contentLength:  1 << 20,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectHandler:  false,
+		},
+		{
+			name:           "body without Content-Length is limited while reading",
+			body:           largeBody,
+			contentLength:  -1,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectHandler:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled = false
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/blogs", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+
+			wrappedHandler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if handlerCalled != tt.expectHandler {
+				t.Errorf("expected handler called=%v, got %v", tt.expectHandler, handlerCalled)
+			}
+		})
+	}
+}
+
+func TestDisabledMethodsMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrappedHandler := disabledMethodsMiddleware([]string{http.MethodDelete})(handler)
+
+	tests := []struct {
+		name           string
+		method         string
+		expectedStatus int
+	}{
+		{
+			name:           "disabled DELETE",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "GET still works",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "POST still works",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/blogs/some-id", nil)
+			w := httptest.NewRecorder()
+
+			wrappedHandler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus == http.StatusMethodNotAllowed {
+				expected := "GET, HEAD, POST, PUT, OPTIONS"
+				if allow := w.Header().Get("Allow"); allow != expected {
+					t.Errorf("expected Allow %q, got %q", expected, allow)
+				}
+			}
+		})
+	}
+}
+
+func TestURLLimitMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrappedHandler := urlLimitMiddleware(100, 3)(handler)
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+	}{
+		{
+			name:           "within limits",
+			target:         "/api/v1/blogs?author=a&limit=10",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "over-length query",
+			target:         "/api/v1/blogs?ids=" + strings.Repeat("x", 100),
+			expectedStatus: http.StatusRequestURITooLong,
+		},
+		{
+			name:           "too many params",
+			target:         "/api/v1/blogs?a=1&b=2&c=3&d=4",
+			expectedStatus: http.StatusRequestURITooLong,
+		},
+		{
+			name:           "empty pairs are not counted",
+			target:         "/api/v1/blogs?a=1&&b=2&c=3&",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			wrappedHandler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAcceptMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		strict         bool
+		method         string
+		path           string
+		accept         string
+		expectedStatus int
+	}{
+		{
+			name:           "json accepted",
+			strict:         true,
+			accept:         "application/json",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "json with profile and quality",
+			strict:         true,
+			accept:         `text/html, application/json; profile="envelope"; q=0.8`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wildcard accepted",
+			strict:         true,
+			accept:         "*/*",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing header accepted",
+			strict:         true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "html rejected",
+			strict:         true,
+			accept:         "text/html",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:           "json with q=0 rejected",
+			strict:         true,
+			accept:         "text/html, application/json;q=0",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:           "plain text rejected for json routes",
+			strict:         true,
+			accept:         "text/plain",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:           "plain text accepted for content",
+			strict:         true,
+			path:           "/api/v1/blogs/abc/content",
+			accept:         "text/plain",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "text range accepted for content",
+			strict:         true,
+			path:           "/api/v1/blogs/abc/content",
+			accept:         "text/*",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "ndjson accepted for export",
+			strict:         true,
+			path:           "/api/v1/blogs/export",
+			accept:         "application/x-ndjson",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "ndjson rejected for json routes",
+			strict:         true,
+			accept:         "application/x-ndjson",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:           "preflight passes",
+			strict:         true,
+			method:         http.MethodOptions,
+			accept:         "text/html",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "lenient by default",
+			strict:         false,
+			accept:         "text/html",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			path := tt.path
+			if path == "" {
+				path = "/api/v1/blogs"
+			}
+			req := httptest.NewRequest(method, path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			acceptMiddleware(tt.strict)(handler).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestLogLevelMiddleware(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		auth       string
+		wantDebug  bool
+	}{
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:5000", wantDebug: true},
+		{name: "untrusted address", remoteAddr: "192.0.2.1:5000"},
+		{name: "untrusted address with admin token", remoteAddr: "192.0.2.1:5000", auth: "Bearer secret", wantDebug: true},
+		{name: "untrusted address with wrong token", remoteAddr: "192.0.2.1:5000", auth: "Bearer wrong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, slog.LevelInfo)
+			handler := logLevelMiddleware(trusted, "secret")(loggingMiddleware(log)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Log-Level", "debug")
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got := strings.Contains(buf.String(), `"level":"DEBUG"`); got != tt.wantDebug {
+				t.Errorf("expected debug logs %v, got %q", tt.wantDebug, buf.String())
+			}
+			// 上書きは該当リクエストのみで、ロガー自体のレベルは変わらない
+			log.Debug(context.Background(), "after request")
+			if strings.Contains(buf.String(), "after request") {
+				t.Error("expected the override not to leak beyond the request")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/moko-poi/blog-api-server/internal/logger"
+)
+
+// countedMethods are the methods counted under their own name; any other is counted as "OTHER"
+// スキャナーが任意のメソッド名を送ってきても集計のキーが増え続けないよう、既知のメソッドに限る
+// パスも同じ理由で集計のキーにしない（個々のパスはアクセスログで確認する）
+var countedMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// notFoundCounter counts requests for routes that do not exist, by method
+// 存在しないルートへのアクセスが多い場合は、スキャナーやクライアントの不具合を疑う
+type notFoundCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newNotFoundCounter() *notFoundCounter {
+	return &notFoundCounter{counts: make(map[string]int)}
+}
+
+// inc counts one unmatched request with method
+func (c *notFoundCounter) inc(method string) {
+	if !slices.Contains(countedMethods, method) {
+		method = "OTHER"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[method]++
+}
+
+// notFoundStats is the body of GET /api/v1/admin/notfound
+type notFoundStats struct {
+	Total    int            `json:"total"`
+	ByMethod map[string]int `json:"by_method"`
+}
+
+// snapshot returns the counts so far
+func (c *notFoundCounter) snapshot() notFoundStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := notFoundStats{ByMethod: make(map[string]int, len(c.counts))}
+	for method, n := range c.counts {
+		stats.ByMethod[method] = n
+		stats.Total += n
+	}
+	return stats
+}
+
+// handleNotFound answers requests that match no route with a JSON 404 and counts them
+// http.ServeMuxの既定のテキストの404の代わりに、他のエラーと同じ形式で返す
+func handleNotFound(counter *notFoundCounter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// "/"はどのルートにも一致しなかったことを表すだけなので、ルートテンプレートは空のままにする
+		setRouteTemplate(r.Context(), "")
+		counter.inc(r.Method)
+		response := ErrorResponse{Error: "Not found"}
+		encode(w, r, http.StatusNotFound, response)
+	})
+}
+
+// handleAdminNotFound returns how many requests matched no route, by method
+func handleAdminNotFound(log *logger.Logger, counter *notFoundCounter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		if err := encode(w, r, http.StatusOK, counter.snapshot()); err != nil {
+			log.Error(r.Context(), "failed to encode not-found stats", "error", err)
+		}
+	})
+}
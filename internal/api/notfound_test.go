@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestNotFoundCounter(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	mux := http.NewServeMux()
+	addRoutes(mux, log, &config.Config{AdminToken: "secret"}, store.NewMemoryBlogStore(), nil, nil, nil)
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/wp-login.php"},
+		{method: http.MethodGet, path: "/api/v2/blogs"},
+		{method: http.MethodPost, path: "/.env"},
+		{method: "PROPFIND", path: "/"},
+		// 存在するルートは数えない
+		{method: http.MethodGet, path: "/api/v1/blogs"},
+		{method: http.MethodGet, path: "/healthz"},
+	}
+	for _, req := range requests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(req.method, req.path, nil))
+		if w.Code == http.StatusNotFound {
+			var response ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("expected a JSON 404 for %s %s, got %v", req.method, req.path, err)
+			}
+			if response.Error != "Not found" {
+				t.Errorf("expected error %q, got %q", "Not found", response.Error)
+			}
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/notfound", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var stats notFoundStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if stats.Total != 4 {
+		t.Errorf("expected 4 unmatched requests, got %d", stats.Total)
+	}
+	// 未知のメソッドはOTHERにまとめる
+	want := map[string]int{"GET": 2, "POST": 1, "OTHER": 1}
+	if !maps.Equal(stats.ByMethod, want) {
+		t.Errorf("expected counts %v, got %v", want, stats.ByMethod)
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+// page describes the slice of a collection requested via ?limit= and ?offset=
+type page struct {
+	// Limit is the maximum number of items returned; 0 means no limit
+	Limit  int
+	Offset int
+}
+
+// parsePage reads limit and offset from the query string
+// limitが省略された場合はDefaultPageSizeを使い、MaxPageSizeを超える値は
+// 黙って丸めずに問題として返す。offsetもMaxPageOffsetを超える値は問題とする
+// （一覧ではoffset+limit件を保持するため、巨大なoffsetでメモリを使わせない）
+func parsePage(r *http.Request, cfg *config.Config) (page, map[string]string) {
+	p := page{Limit: cfg.DefaultPageSize}
+	problems := make(map[string]string)
+	query := r.URL.Query()
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		switch {
+		case err != nil || limit < 1:
+			problems["limit"] = "limit must be a positive integer"
+		case cfg.MaxPageSize > 0 && limit > cfg.MaxPageSize:
+			problems["limit"] = fmt.Sprintf("limit must not exceed %d", cfg.MaxPageSize)
+		default:
+			p.Limit = limit
+		}
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		switch {
+		case err != nil || offset < 0:
+			problems["offset"] = "offset must be a non-negative integer"
+		case cfg.MaxPageOffset > 0 && offset > cfg.MaxPageOffset:
+			problems["offset"] = fmt.Sprintf("offset must not exceed %d", cfg.MaxPageOffset)
+		default:
+			p.Offset = offset
+		}
+	}
+
+	return p, problems
+}
+
+// apply returns the blogs within the page
+// ページ境界を安定させるため、呼び出し側で並べ替え済みであること
+func (p page) apply(blogs []*domain.Blog) []*domain.Blog {
+	if p.Offset >= len(blogs) {
+		return []*domain.Blog{}
+	}
+	blogs = blogs[p.Offset:]
+	if p.Limit > 0 && p.Limit < len(blogs) {
+		blogs = blogs[:p.Limit]
+	}
+	return blogs
+}
+
+// selectPage returns the page of blogs accepted by keep, in order, visiting the store with Each
+// GetAllで全件のコピーを作ってから並べ替える代わりに、ページの末尾までに入る
+// offset+limit件だけをヒープで保持し、最後に一度だけ並べ替える
+func selectPage(ctx context.Context, it store.Iterator, order sortOrder, p page, keep func(*domain.Blog) bool) ([]*domain.Blog, error) {
+	size := p.Offset + p.Limit
+	// 先頭が最も後ろに並ぶブログとなるヒープ。limitがない場合は全件を集める
+	selected := &blogHeap{order: order}
+	err := it.Each(ctx, func(blog *domain.Blog) error {
+		if !keep(blog) {
+			return nil
+		}
+		switch {
+		case p.Limit <= 0 || selected.Len() < size:
+			heap.Push(selected, blog)
+		case order.compare(blog, selected.blogs[0]) < 0:
+			selected.blogs[0] = blog
+			heap.Fix(selected, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(selected.blogs, order.compare)
+	return p.apply(selected.blogs), nil
+}
+
+// blogHeap is a container/heap of blogs with the last one in order at the root
+type blogHeap struct {
+	blogs []*domain.Blog
+	order sortOrder
+}
+
+func (h *blogHeap) Len() int           { return len(h.blogs) }
+func (h *blogHeap) Less(i, j int) bool { return h.order.compare(h.blogs[i], h.blogs[j]) > 0 }
+func (h *blogHeap) Swap(i, j int)      { h.blogs[i], h.blogs[j] = h.blogs[j], h.blogs[i] }
+func (h *blogHeap) Push(x any)         { h.blogs = append(h.blogs, x.(*domain.Blog)) }
+
+func (h *blogHeap) Pop() any {
+	last := h.blogs[len(h.blogs)-1]
+	h.blogs = h.blogs[:len(h.blogs)-1]
+	return last
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// blogsListParams are the query parameters understood by GET /api/v1/blogs
+// 一覧に新しいクエリパラメータを追加した場合はここにも追加すること
+var blogsListParams = []string{"author", "author_id", "category", "tag", "sort", "limit", "offset", "fields", "tz"}
+
+// blogsCountParams are the query parameters understood by GET /api/v1/blogs/count
+var blogsCountParams = []string{"author", "author_id", "category", "tag"}
+
+// blogFilter is the selection shared by the listing and the count
+// 一覧と件数で絞り込みの解釈がずれないよう、パラメータの解析と判定を一箇所にまとめる
+type blogFilter struct {
+	// Authorは保存時と同じ正規化をかけた作者名
+	Author string
+	// AuthorIDは表示名に関係なく一致させる作者ID（正規化しない）。Authorは表示名のみに一致する
+	AuthorID string
+	Category string
+	// TagsはNormalizeTags済みのタグ。MatchAnyの場合はいずれか一つを持てば一致とする
+	Tags     []string
+	MatchAny bool
+}
+
+// parseBlogFilter reads ?author=, ?author_id=, ?category= and ?tag= from r
+// ?tag= は複数指定でき、全件走査になるため個数を制限する
+func parseBlogFilter(r *http.Request, cfg *config.Config) (blogFilter, error) {
+	query := r.URL.Query()
+	filter := blogFilter{
+		AuthorID: query.Get("author_id"),
+		Category: query.Get("category"),
+		Tags:     domain.NormalizeTags(query["tag"]),
+		MatchAny: cfg.TagMatch == "any",
+	}
+	if author := query.Get("author"); author != "" {
+		filter.Author = authorNormalization(cfg).Apply(author)
+	}
+	if cfg.MaxTagsPerQuery > 0 && len(filter.Tags) > cfg.MaxTagsPerQuery {
+		return filter, fmt.Errorf("at most %d tag values are allowed", cfg.MaxTagsPerQuery)
+	}
+	return filter, nil
+}
+
+// matches reports whether blog passes every filter
+func (f blogFilter) matches(blog *domain.Blog) bool {
+	if f.Author != "" && blog.Author != f.Author {
+		return false
+	}
+	if f.AuthorID != "" && blog.AuthorID != f.AuthorID {
+		return false
+	}
+	if f.Category != "" && blog.Category != f.Category {
+		return false
+	}
+	return len(f.Tags) == 0 || hasTags(blog, f.Tags, f.MatchAny)
+}
+
+// unknownQueryParam returns the first query parameter of r not listed in known
+// 複数ある場合でも結果が安定するよう、名前順で最初のものを返す
+func unknownQueryParam(r *http.Request, known []string) (string, bool) {
+	names := make([]string, 0, len(r.URL.Query()))
+	for name := range r.URL.Query() {
+		if !slices.Contains(known, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return names[0], true
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/logger"
+)
+
+// rateLimiter is an in-memory token bucket rate limiter keyed by client
+// クライアントごとにバケットを持ち、rate（トークン/秒）で補充、最大burstまで貯められる
+// 単一プロセス内でのみ有効なため、複数インスタンス構成ではRedis等の外部ストアが必要
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time // テスト時に時刻を制御するため注入可能
+
+	// 前回のログ出力以降に拒否したリクエスト数（クライアントごと）
+	// 攻撃時にリクエストごとのログで溢れないよう、logRejectionsでまとめて出力する
+	// countRejectionsを呼ぶまではnilで、数えない（出力しない集計でマップが増え続けないように）
+	rejections map[string]int
+}
+
+// bucketSweepInterval is how often allow removes buckets that have refilled completely
+const bucketSweepInterval = time.Minute
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// rateLimitBucket is a point-in-time view of a client's bucket for observability
+type rateLimitBucket struct {
+	ClientKey       string    `json:"client_key"`
+	RemainingTokens float64   `json:"remaining_tokens"`
+	LastRefill      time.Time `json:"last_refill"`
+}
+
+// newRateLimiter creates a limiter allowing rate requests per second with the given burst
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// countRejections makes allow count rejections per client for logRejections
+// 集計を定期的に出力して空にする場合（RATE_LIMIT_LOG_INTERVAL > 0）のみ呼ぶこと
+func (l *rateLimiter) countRejections() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rejections = make(map[string]int)
+}
+
+// allow consumes a token for key, reporting whether the request may proceed
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= bucketSweepInterval {
+		l.sweepLocked(now)
+		l.lastSweep = now
+	}
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.burst, lastRefill: now}
+		l.buckets[key] = bucket
+	}
+
+	// 前回補充からの経過時間に応じてトークンを補充
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.rate)
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		if l.rejections != nil {
+			l.rejections[key]++
+		}
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// sweepLocked removes the buckets that would be full if refilled now
+// 満タンのバケットは新しく作るバケットと同じなので、削除しても制限は変わらない
+// 一度だけアクセスしたクライアントのバケットでマップが増え続けないようにする
+func (l *rateLimiter) sweepLocked(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.lastRefill).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// logRejections writes one Warn summarizing the requests rejected since the last call
+// 拒否がなければ何も出力しない。最も多く拒否されたクライアントも併せて記録する
+func (l *rateLimiter) logRejections(ctx context.Context, log *logger.Logger) {
+	l.mu.Lock()
+	rejections := l.rejections
+	if rejections != nil {
+		l.rejections = make(map[string]int)
+	}
+	l.mu.Unlock()
+
+	if len(rejections) == 0 {
+		return
+	}
+	total, topClient := 0, ""
+	for key, n := range rejections {
+		total += n
+		if n > rejections[topClient] || (n == rejections[topClient] && key < topClient) {
+			topClient = key
+		}
+	}
+	log.Warn(ctx, "rate limit rejections",
+		"count", total,
+		"clients", len(rejections),
+		"top_client", topClient,
+		"top_client_count", rejections[topClient],
+	)
+}
+
+// runRejectionLogger logs the aggregated rejections every interval until ctx is done
+// 停止時には残りの集計も出力する
+func (l *rateLimiter) runRejectionLogger(ctx context.Context, log *logger.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.logRejections(context.WithoutCancel(ctx), log)
+			return
+		case <-ticker.C:
+			l.logRejections(ctx, log)
+		}
+	}
+}
+
+// snapshot returns the current state of every bucket sorted by client key
+// 状態を変更しないよう、最後の補充時点の値をそのまま返す
+func (l *rateLimiter) snapshot() []rateLimitBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buckets := make([]rateLimitBucket, 0, len(l.buckets))
+	for key, bucket := range l.buckets {
+		buckets = append(buckets, rateLimitBucket{
+			ClientKey:       key,
+			RemainingTokens: bucket.tokens,
+			LastRefill:      bucket.lastRefill,
+		})
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].ClientKey < buckets[j].ClientKey
+	})
+	return buckets
+}
+
+// concurrencyLimiter caps the number of in-flight requests per client
+// トークンバケットは開始レートしか制限しないため、遅いリクエストを大量に同時保持する
+// クライアントには効かない。こちらは完了までスロットを占有させる
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	max      int
+	inFlight map[string]int
+}
+
+// newConcurrencyLimiter creates a limiter allowing max concurrent requests per client
+func newConcurrencyLimiter(max int) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		max:      max,
+		inFlight: make(map[string]int),
+	}
+}
+
+// acquire takes a slot for key, reporting whether the request may proceed
+// trueを返した場合、呼び出し側はリクエスト完了時に必ずreleaseする
+func (l *concurrencyLimiter) acquire(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[key] >= l.max {
+		return false
+	}
+	l.inFlight[key]++
+	return true
+}
+
+// release returns a slot taken by acquire
+// 使われなくなったクライアントのエントリは削除してマップの肥大化を防ぐ
+func (l *concurrencyLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[key] <= 1 {
+		delete(l.inFlight, key)
+		return
+	}
+	l.inFlight[key]--
+}
+
+// clientKey identifies the client of a request for rate limiting
+// RemoteAddrからポートを除いたIPアドレスを使用する
+func clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	if !limiter.allow("client-a") {
+		t.Error("expected first request to be allowed")
+	}
+	if !limiter.allow("client-a") {
+		t.Error("expected second request to be allowed within burst")
+	}
+	if limiter.allow("client-a") {
+		t.Error("expected third request to be rejected after burst")
+	}
+
+	// Other clients have their own bucket
+	if !limiter.allow("client-b") {
+		t.Error("expected different client to be allowed")
+	}
+
+	// Tokens are refilled over time
+	now = now.Add(1 * time.Second)
+	if !limiter.allow("client-a") {
+		t.Error("expected request to be allowed after refill")
+	}
+}
+
+func TestRateLimiter_Sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 2分で1トークン補充される
+	limiter := newRateLimiter(1.0/120, 1)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		limiter.allow("client-" + strconv.Itoa(i))
+	}
+	now = now.Add(bucketSweepInterval)
+	limiter.allow("busy")
+
+	// 満タンまで補充されたバケットだけが取り除かれ、補充中のバケットは残る
+	now = now.Add(bucketSweepInterval + time.Second)
+	limiter.allow("busy")
+	buckets := limiter.snapshot()
+	if len(buckets) != 1 || buckets[0].ClientKey != "busy" {
+		t.Errorf("expected only the refilling bucket to remain, got %+v", buckets)
+	}
+}
+
+func TestRateLimiter_Snapshot(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1, 5)
+	limiter.now = func() time.Time { return now }
+
+	if buckets := limiter.snapshot(); len(buckets) != 0 {
+		t.Errorf("expected no buckets, got %d", len(buckets))
+	}
+
+	limiter.allow("client-b")
+	limiter.allow("client-a")
+	limiter.allow("client-a")
+
+	buckets := limiter.snapshot()
+	if len(buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(buckets))
+	}
+	if buckets[0].ClientKey != "client-a" || buckets[0].RemainingTokens != 3 {
+		t.Errorf("expected client-a with 3 tokens, got %+v", buckets[0])
+	}
+	if buckets[1].ClientKey != "client-b" || buckets[1].RemainingTokens != 4 {
+		t.Errorf("expected client-b with 4 tokens, got %+v", buckets[1])
+	}
+	if !buckets[0].LastRefill.Equal(now) {
+		t.Errorf("expected last refill %v, got %v", now, buckets[0].LastRefill)
+	}
+}
+
+func TestRatelimitMiddleware_Limited(t *testing.T) {
+	limiter := newRateLimiter(0.001, 1)
+
+	handler := ratelimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+}
+
+func TestRateLimiter_LogRejections(t *testing.T) {
+	var logOutput bytes.Buffer
+	log := logger.New(&logOutput, slog.LevelInfo)
+	limiter := newRateLimiter(0.001, 1)
+	limiter.countRejections()
+
+	handler := ratelimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(remoteAddr string) {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	// 各クライアントの最初の1件はバーストで通り、残りが拒否される
+	for i := 0; i < 51; i++ {
+		request("198.51.100.7:1000")
+	}
+	for i := 0; i < 11; i++ {
+		request("203.0.113.9:2000")
+	}
+
+	// 拒否ごとにはログを出さない
+	if logOutput.Len() != 0 {
+		t.Fatalf("expected no log lines before the summary, got %s", logOutput.String())
+	}
+
+	limiter.logRejections(context.Background(), log)
+	lines := strings.Split(strings.TrimSpace(logOutput.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single summary line, got %d: %s", len(lines), logOutput.String())
+	}
+	var entry struct {
+		Msg            string `json:"msg"`
+		Level          string `json:"level"`
+		Count          int    `json:"count"`
+		Clients        int    `json:"clients"`
+		TopClient      string `json:"top_client"`
+		TopClientCount int    `json:"top_client_count"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("failed to parse log line: %v", err)
+	}
+	if entry.Msg != "rate limit rejections" || entry.Level != "WARN" {
+		t.Errorf("expected a WARN summary, got %+v", entry)
+	}
+	if entry.Count != 60 || entry.Clients != 2 {
+		t.Errorf("expected 60 rejections from 2 clients, got %d from %d", entry.Count, entry.Clients)
+	}
+	if entry.TopClient != "198.51.100.7" || entry.TopClientCount != 50 {
+		t.Errorf("expected top client 198.51.100.7 with 50, got %s with %d", entry.TopClient, entry.TopClientCount)
+	}
+
+	// 集計はリセットされ、拒否がなければ何も出力しない
+	logOutput.Reset()
+	limiter.logRejections(context.Background(), log)
+	if logOutput.Len() != 0 {
+		t.Errorf("expected no summary without new rejections, got %s", logOutput.String())
+	}
+}
+
+func TestRateLimiter_RejectionsNotCountedWithoutLogging(t *testing.T) {
+	// RATE_LIMIT_LOG_INTERVAL=0では集計を出力しないため、拒否を数えない
+	log := logger.New(io.Discard, slog.LevelError)
+	srv, err := NewServer(log, &config.Config{RateLimitRPS: 0.001, RateLimitBurst: 1}, store.NewMemoryBlogStore())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		srv.limiter.allow(fmt.Sprintf("198.51.100.%d", i%10))
+	}
+	if len(srv.limiter.rejections) != 0 {
+		t.Errorf("expected no rejection counts without a log interval, got %d clients", len(srv.limiter.rejections))
+	}
+}
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	handler := concurrencyLimitMiddleware(2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-unblock
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 同じIPから遅いリクエストで2スロットを占有する（ポートが違っても同じクライアント）
+	var wg sync.WaitGroup
+	for _, addr := range []string{"198.51.100.7:1001", "198.51.100.7:1002"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request("/slow", addr)
+		}()
+		<-started
+	}
+
+	if code := request("/fast", "198.51.100.7:1003"); code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d for overflow from same IP, got %d", http.StatusTooManyRequests, code)
+	}
+	if code := request("/fast", "203.0.113.9:2001"); code != http.StatusOK {
+		t.Errorf("expected status %d for another IP, got %d", http.StatusOK, code)
+	}
+
+	close(unblock)
+	wg.Wait()
+
+	if code := request("/fast", "198.51.100.7:1004"); code != http.StatusOK {
+		t.Errorf("expected status %d after slots were released, got %d", http.StatusOK, code)
+	}
+}
+
+func TestHandleAdminRateLimits(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	limiter := newRateLimiter(0.001, 10)
+
+	limited := ratelimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
+		req.RemoteAddr = "198.51.100.7:5555"
+		limited.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	handler := adminAuthMiddleware("secret")(handleAdminRateLimits(log, limiter))
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong token",
+			authorization:  "Bearer wrong",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "valid token",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/ratelimits", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var buckets []rateLimitBucket
+			if err := json.Unmarshal(w.Body.Bytes(), &buckets); err != nil {
+				t.Fatalf("failed to unmarshal buckets: %v", err)
+			}
+			if len(buckets) != 1 {
+				t.Fatalf("expected 1 bucket, got %d", len(buckets))
+			}
+			if buckets[0].ClientKey != "198.51.100.7" {
+				t.Errorf("expected client key '198.51.100.7', got %q", buckets[0].ClientKey)
+			}
+			if buckets[0].RemainingTokens > 7.1 {
+				t.Errorf("expected tokens to be decremented to ~7, got %v", buckets[0].RemainingTokens)
+			}
+		})
+	}
+}
+
+func TestAdminAuthMiddleware_Disabled(t *testing.T) {
+	handler := adminAuthMiddleware("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/ratelimits", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLen caps incoming request IDs so clients cannot bloat the logs
+const maxRequestIDLen = 128
+
+// requestIDMiddleware assigns every request an ID and echoes it in the response
+// 上流（ロードバランサーなど）が付与したIDがあればそれを引き継ぎ、なければ生成する
+// ヘッダー名はインフラに合わせて X-Correlation-ID などに変更できる
+func requestIDMiddleware(header string) func(http.Handler) http.Handler {
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = uuid.New().String()
+			}
+			w.Header().Set(header, id)
+
+			ctx := context.WithValue(r.Context(), requestIDKey, id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestIDFromContext returns the request ID assigned by requestIDMiddleware
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// validRequestID reports whether an incoming ID is safe to log and echo back
+// 表示可能なASCIIのみを許可し、ログやヘッダーへの不正な文字の混入を防ぐ
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
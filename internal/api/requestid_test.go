@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		sendHeader string
+		sendID     string
+		expectEcho bool
+	}{
+		{
+			name:       "default header is read and echoed",
+			header:     "",
+			sendHeader: "X-Request-ID",
+			sendID:     "abc-123",
+			expectEcho: true,
+		},
+		{
+			name:       "custom header is read and echoed",
+			header:     "X-Correlation-ID",
+			sendHeader: "X-Correlation-ID",
+			sendID:     "corr-456",
+			expectEcho: true,
+		},
+		{
+			name:       "default header is ignored when a custom one is configured",
+			header:     "X-Correlation-ID",
+			sendHeader: "X-Request-ID",
+			sendID:     "abc-123",
+			expectEcho: false,
+		},
+		{
+			name:       "overlong ID is replaced",
+			header:     "X-Request-ID",
+			sendHeader: "X-Request-ID",
+			sendID:     strings.Repeat("x", maxRequestIDLen+1),
+			expectEcho: false,
+		},
+		{
+			name:       "ID with spaces is replaced",
+			header:     "X-Request-ID",
+			sendHeader: "X-Request-ID",
+			sendID:     "abc 123",
+			expectEcho: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = requestIDFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs", nil)
+			req.Header.Set(tt.sendHeader, tt.sendID)
+			w := httptest.NewRecorder()
+
+			requestIDMiddleware(tt.header)(handler).ServeHTTP(w, req)
+
+			responseHeader := tt.header
+			if responseHeader == "" {
+				responseHeader = "X-Request-ID"
+			}
+			echoed := w.Header().Get(responseHeader)
+			if echoed == "" {
+				t.Fatalf("expected %s response header to be set", responseHeader)
+			}
+			if echoed != seen {
+				t.Errorf("expected context ID %q to match response header %q", seen, echoed)
+			}
+			if got := echoed == tt.sendID; got != tt.expectEcho {
+				t.Errorf("expected echo of incoming ID=%v, got header %q", tt.expectEcho, echoed)
+			}
+		})
+	}
+}
+
+func TestErrorResponse_RequestID(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	tests := []struct {
+		name          string
+		handler       http.Handler
+		wantStatus    int
+		wantRequestID string
+	}{
+		{
+			name:          "store failure",
+			handler:       handleBlogsGet(log, &config.Config{}, &mockBlogStore{getAllError: errors.New("store error")}),
+			wantStatus:    http.StatusInternalServerError,
+			wantRequestID: "req-500",
+		},
+		{
+			name: "recovered panic",
+			handler: panicRecoveryMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			})),
+			wantStatus:    http.StatusInternalServerError,
+			wantRequestID: "req-500",
+		},
+		{
+			// クライアント側の誤りにはリクエストIDを含めない
+			name:       "client error",
+			handler:    handleBlogsByID(log, &config.Config{}, store.NewMemoryBlogStore()),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := requestIDMiddleware("")(tt.handler)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/missing", nil)
+			req.Header.Set("X-Request-ID", "req-500")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			var response ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.RequestID != tt.wantRequestID {
+				t.Errorf("expected request_id %q, got %q", tt.wantRequestID, response.RequestID)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// レスポンスのJSONフィールド命名規則
+// ドメイン型のjsonタグはsnake_caseで定義されているため、camelCaseはエンコード時に変換する
+const (
+	fieldCaseSnake = "snake"
+	fieldCaseCamel = "camel"
+)
+
+// contextKey is the type for values stored in the request context by this package
+type contextKey int
+
+const (
+	fieldCaseKey contextKey = iota
+	requestIDKey
+	fieldLimitsKey
+	routeTemplateKey
+	jsonLimitsKey
+	serverTimingKey
+	escapeHTMLKey
+	bodyLimitKey
+)
+
+// fieldCaseMiddleware stores the configured JSON field naming strategy in the request context
+// encodeはリクエストを受け取るため、コンテキスト経由で命名規則を参照できる
+// snake（デフォルト）の場合は変換不要なので何もしない
+func fieldCaseMiddleware(fieldCase string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if fieldCase == "" || fieldCase == fieldCaseSnake {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), fieldCaseKey, fieldCase)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// fieldCaseFromContext returns the JSON field naming strategy for the request
+func fieldCaseFromContext(ctx context.Context) string {
+	if fieldCase, ok := ctx.Value(fieldCaseKey).(string); ok {
+		return fieldCase
+	}
+	return fieldCaseSnake
+}
+
+// escapeHTMLMiddleware stores whether JSON responses escape <, > and & in the request context
+// 既定（escape=true）はencoding/jsonと同じくエスケープするので何もしない
+// 無効にすると本文中のHTMLやMarkdownが \u003c などにならず、そのまま出力される
+func escapeHTMLMiddleware(escape bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if escape {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), escapeHTMLKey, false)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// escapeHTMLFromContext reports whether JSON responses for the request escape HTML characters
+func escapeHTMLFromContext(ctx context.Context) bool {
+	if escape, ok := ctx.Value(escapeHTMLKey).(bool); ok {
+		return escape
+	}
+	return true
+}
+
+// newJSONEncoder returns an encoder writing to w with the request's HTML escaping setting
+func newJSONEncoder(ctx context.Context, w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(escapeHTMLFromContext(ctx))
+	return enc
+}
+
+// marshalJSON is json.Marshal with the request's HTML escaping setting
+func marshalJSON(ctx context.Context, v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := newJSONEncoder(ctx, &buf).Encode(v); err != nil {
+		return nil, err
+	}
+	// Encodeが付ける末尾の改行を除く
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// camelCaseKeys converts v into a JSON-ready value whose struct field names are camelCase
+// 構造体のjsonタグ名のみを変換し、mapのキー（作者名など実データ）はそのまま残す
+// json.Marshalerを実装する型（time.Timeなど）は独自のエンコードに任せる
+func camelCaseKeys(v any) any {
+	return camelCaseValue(reflect.ValueOf(v))
+}
+
+// indirectType returns the type t points to, or t itself if it is not a pointer
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+func camelCaseValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return camelCaseValue(v.Elem())
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		// 埋め込まれた構造体のフィールドはencoding/jsonと同じく外側に展開し、外側のフィールドを優先する
+		var embedded []map[string]any
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+				if fields, ok := camelCaseValue(v.Field(i)).(map[string]any); ok {
+					embedded = append(embedded, fields)
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "-" && opts == "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if strings.Contains(opts, "omitempty") && isEmptyValue(v.Field(i)) {
+				continue
+			}
+			out[snakeToCamel(name)] = camelCaseValue(v.Field(i))
+		}
+		for _, fields := range embedded {
+			for name, value := range fields {
+				if _, ok := out[name]; !ok {
+					out[name] = value
+				}
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = camelCaseValue(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = camelCaseValue(iter.Value())
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// isEmptyValue mirrors encoding/json's definition of an empty value for omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// snakeToCamel converts a snake_case name such as created_at into createdAt
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// blogFields is the set of JSON field names a client may request via ?fields=
+// domain.Blogのjsonタグから導出するため、フィールド追加時に自動で追従する
+var blogFields = jsonFieldNames(reflect.TypeOf(domain.Blog{}))
+
+// jsonFieldNames returns the JSON names of the exported fields of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// parseFields parses the sparse fieldset requested via ?fields=id,title
+// 指定がない場合はnil（全フィールド）を返す
+// camelCaseで指定された場合もsnake_caseの正式名に正規化する
+func parseFields(r *http.Request) ([]string, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !blogFields[name] {
+			canonical, ok := camelToSnakeField(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown field: %s", name)
+			}
+			name = canonical
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// camelToSnakeField finds the known field whose camelCase form is name
+func camelToSnakeField(name string) (string, bool) {
+	for field := range blogFields {
+		if snakeToCamel(field) == name {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// selectFields marshals the blog and keeps only the requested fields
+// キーはリクエストの命名規則（snake/camel）に合わせて出力する
+func selectFields(ctx context.Context, blog *domain.Blog, fields []string) (map[string]json.RawMessage, error) {
+	// 値はRawMessageとしてそのまま出力されるため、ここではエスケープせず
+	// 最終的なエンコード時にJSON_ESCAPE_HTMLに従ってエスケープさせる
+	data, err := marshalJSON(context.WithValue(ctx, escapeHTMLKey, false), blog)
+	if err != nil {
+		return nil, fmt.Errorf("marshal blog: %w", err)
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("unmarshal blog: %w", err)
+	}
+
+	camel := fieldCaseFromContext(ctx) == fieldCaseCamel
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		value, ok := all[field]
+		if !ok {
+			continue // omitemptyで省略されたフィールド
+		}
+		if camel {
+			field = snakeToCamel(field)
+		}
+		selected[field] = value
+	}
+	return selected, nil
+}
+
+// envelopeProfile is the Accept profile parameter that requests an enveloped response
+const envelopeProfile = "envelope"
+
+// dataEnvelope wraps a single resource as {"data": ...}
+type dataEnvelope struct {
+	Data any `json:"data"`
+}
+
+// createResponse is the body of a successful create: the blog plus non-fatal warnings
+// 警告がない場合はブログ単体と同じJSONになる
+type createResponse struct {
+	*domain.Blog
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// deleteResponse is the body of a successful DELETE when DELETE_RESPONSE_BODY is set
+type deleteResponse struct {
+	Deleted bool   `json:"deleted"`
+	ID      string `json:"id"`
+}
+
+// countResponse is the body of GET /api/v1/blogs/count
+type countResponse struct {
+	Count int `json:"count"`
+}
+
+// batchResponse is the body of an NDJSON batch create
+// 全体のステータスだけでは部分的な成功がわからないため、件数と行ごとの結果を返す
+type batchResponse struct {
+	Created int           `json:"created"`
+	Failed  int           `json:"failed"`
+	Results []batchResult `json:"results"`
+}
+
+// batchResult is the outcome of one line of a batch create
+type batchResult struct {
+	Line     int               `json:"line"`
+	Status   int               `json:"status"`
+	Blog     *domain.Blog      `json:"blog,omitempty"`
+	Warnings []string          `json:"warnings,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Problems map[string]string `json:"problems,omitempty"`
+	// RetryAfterは429の行で、次に投稿可能になるまでの秒数（ヘッダーは行ごとに返せないため）
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// wantsEnvelope reports whether a single-resource response should be enveloped
+// RESPONSE_ENVELOPEが無効でも、Accept: application/json; profile="envelope"
+// を送ったクライアントには個別に包んで返す
+func wantsEnvelope(r *http.Request, cfg *config.Config) bool {
+	if cfg.ResponseEnvelope {
+		return true
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && params["profile"] == envelopeProfile {
+			return true
+		}
+	}
+	return false
+}
+
+// methodNotAllowed answers 405 with a JSON error and the Allow header
+// Allowヘッダーはクライアントにそのリソースで有効なメソッドを伝える（RFC 9110で必須）
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	response := ErrorResponse{Error: "Method not allowed"}
+	encode(w, r, http.StatusMethodNotAllowed, response)
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestEncode_FieldCase(t *testing.T) {
+	blog := &domain.Blog{
+		ID:        "test-id",
+		Title:     "Test Title",
+		Content:   "Test Content",
+		Author:    "Test Author",
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name        string
+		fieldCase   string
+		wantKeys    []string
+		notWantKeys []string
+	}{
+		{
+			name:        "snake case",
+			fieldCase:   fieldCaseSnake,
+			wantKeys:    []string{"id", "title", "content", "author", "created_at", "updated_at"},
+			notWantKeys: []string{"createdAt", "updatedAt"},
+		},
+		{
+			name:        "camel case",
+			fieldCase:   fieldCaseCamel,
+			wantKeys:    []string{"id", "title", "content", "author", "createdAt", "updatedAt"},
+			notWantKeys: []string{"created_at", "updated_at"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := fieldCaseMiddleware(tt.fieldCase)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encode(w, r, http.StatusOK, blog)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/test-id", nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			var result map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := result[key]; !ok {
+					t.Errorf("expected key %q in %v", key, result)
+				}
+			}
+			for _, key := range tt.notWantKeys {
+				if _, ok := result[key]; ok {
+					t.Errorf("expected no key %q in %v", key, result)
+				}
+			}
+			if result["createdAt"] != nil && result["createdAt"] != "2024-01-01T00:00:00Z" {
+				t.Errorf("expected createdAt in RFC3339, got %v", result["createdAt"])
+			}
+		})
+	}
+}
+
+func TestEscapeHTMLMiddleware(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	blogStore.Create(context.Background(), &domain.Blog{ID: "1", Title: "Title", Content: "<b>bold</b> & more", CreatedAt: time.Now()})
+	mux := http.NewServeMux()
+	addRoutes(mux, log, &config.Config{}, blogStore, nil, nil, nil)
+
+	const (
+		escaped   = `\u003cb\u003ebold\u003c/b\u003e \u0026 more`
+		unescaped = `<b>bold</b> & more`
+	)
+	tests := []struct {
+		name   string
+		escape bool
+		want   string
+	}{
+		{name: "escaped by default", escape: true, want: escaped},
+		{name: "unescaped when disabled", escape: false, want: unescaped},
+	}
+
+	// 単体取得・フィールド選択・一覧・エクスポートのいずれも設定に従うこと
+	// エクスポートは書き込み期限の設定に実際の接続が必要なため、httptest.Serverを使う
+	paths := []string{"/api/v1/blogs/1", "/api/v1/blogs/1?fields=content", "/api/v1/blogs", "/api/v1/blogs/export"}
+	for _, tt := range tests {
+		ts := httptest.NewServer(escapeHTMLMiddleware(tt.escape)(mux))
+		defer ts.Close()
+		for _, path := range paths {
+			t.Run(tt.name+" "+path, func(t *testing.T) {
+				resp, err := http.Get(ts.URL + path)
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				defer resp.Body.Close()
+
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+				}
+				data, _ := io.ReadAll(resp.Body)
+				body := string(data)
+				if !strings.Contains(body, tt.want) {
+					t.Errorf("expected content %s in %s", tt.want, body)
+				}
+				var decoded any
+				if err := json.NewDecoder(strings.NewReader(body)).Decode(&decoded); err != nil {
+					t.Errorf("expected valid JSON, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestCamelCaseKeys(t *testing.T) {
+	t.Run("map keys are preserved", func(t *testing.T) {
+		stats := domain.BlogStats{
+			TotalBlogs:     1,
+			BlogsPerAuthor: map[string]int{"john_doe": 1},
+		}
+
+		data, err := json.Marshal(camelCaseKeys(stats))
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		var result map[string]any
+		json.Unmarshal(data, &result)
+		perAuthor, ok := result["blogsPerAuthor"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected blogsPerAuthor object, got %v", result)
+		}
+		if _, ok := perAuthor["john_doe"]; !ok {
+			t.Errorf("expected author key 'john_doe' to be preserved, got %v", perAuthor)
+		}
+		if _, ok := result["newestPostAt"]; ok {
+			t.Error("expected omitempty nil field to be omitted")
+		}
+	})
+
+	t.Run("slices of structs", func(t *testing.T) {
+		blogs := []*domain.Blog{{ID: "1"}, {ID: "2"}}
+
+		data, err := json.Marshal(camelCaseKeys(blogs))
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		var result []map[string]any
+		json.Unmarshal(data, &result)
+		if len(result) != 2 {
+			t.Fatalf("expected 2 items, got %d", len(result))
+		}
+		if _, ok := result[0]["createdAt"]; !ok {
+			t.Errorf("expected createdAt key, got %v", result[0])
+		}
+	})
+
+	t.Run("embedded structs are flattened", func(t *testing.T) {
+		resp := createResponse{Blog: &domain.Blog{ID: "1", Title: "Title"}, Warnings: []string{"similar title"}}
+
+		data, err := json.Marshal(camelCaseKeys(resp))
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		var result map[string]any
+		json.Unmarshal(data, &result)
+		if result["id"] != "1" || result["title"] != "Title" {
+			t.Errorf("expected blog fields at the top level, got %v", result)
+		}
+		if _, ok := result["Blog"]; ok {
+			t.Errorf("expected no Blog key, got %v", result)
+		}
+		if _, ok := result["warnings"]; !ok {
+			t.Errorf("expected warnings key, got %v", result)
+		}
+	})
+
+	t.Run("nil slice stays null", func(t *testing.T) {
+		var blogs []*domain.Blog
+
+		data, _ := json.Marshal(camelCaseKeys(blogs))
+		if string(data) != "null" {
+			t.Errorf("expected null, got %s", data)
+		}
+	})
+}
+
+func TestSnakeToCamel(t *testing.T) {
+	tests := map[string]string{
+		"id":                     "id",
+		"created_at":             "createdAt",
+		"average_content_length": "averageContentLength",
+	}
+
+	for in, want := range tests {
+		if got := snakeToCamel(in); got != want {
+			t.Errorf("snakeToCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -2,37 +2,140 @@ package api
 
 import (
 	"net/http"
+	"slices"
+	"strings"
 
+	"github.com/moko-poi/blog-api-server/internal/config"
 	"github.com/moko-poi/blog-api-server/internal/logger"
 	"github.com/moko-poi/blog-api-server/internal/store"
 )
 
+// routeRegistrar is the subset of *http.ServeMux that addRoutes uses
+type routeRegistrar interface {
+	Handle(pattern string, handler http.Handler)
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// routeRecorder remembers the patterns registered on a ServeMux
+// http.ServeMuxは登録済みのパターンを列挙できないため、起動時のルート一覧の出力用に記録する
+// 併せて、一致したパターンをリクエストのルートテンプレートとして記録する
+type routeRecorder struct {
+	*http.ServeMux
+	patterns []string
+	// methodsはwithMethodsで宣言したルートごとのメソッド（宣言のないルートはキーなし）
+	methods map[string][]string
+}
+
+func (m *routeRecorder) Handle(pattern string, handler http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+	if route, ok := handler.(*methodsHandler); ok {
+		if m.methods == nil {
+			m.methods = make(map[string][]string)
+		}
+		m.methods[pattern] = route.methods
+	}
+	m.ServeMux.Handle(pattern, withRouteTemplate(pattern, handler))
+}
+
+func (m *routeRecorder) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// methodsHandler is a route's handler together with the methods it answers
+// ROUTE_OPTIONSが有効な場合、CORSミドルウェアはOPTIONSをルートに渡し、ここで応答する
+// 認証などルート固有のハンドラーより外側で応答するため、管理APIの認証や404の集計の対象にならない
+type methodsHandler struct {
+	methods []string
+	next    http.Handler
+}
+
+// withMethods declares the methods next answers, listed in the Allow header of OPTIONS
+// メソッドを指定しない場合（404のルートなど）はAllowなしの200を返す
+func withMethods(next http.Handler, methods ...string) http.Handler {
+	return &methodsHandler{methods: methods, next: next}
+}
+
+func (h *methodsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodOptions {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	if len(h.methods) > 0 {
+		w.Header().Set("Allow", strings.Join(append(slices.Clip(h.methods), http.MethodOptions), ", "))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 // routes.goでAPI全体の構造を一箇所で定義
 func addRoutes(
-	mux *http.ServeMux,
+	mux routeRegistrar,
 	log *logger.Logger,
+	cfg *config.Config,
 	blogStore store.BlogStore,
+	limiter *rateLimiter,
+	idempotency *idempotencyStore,
+	health *healthState,
 ) {
+	// 各ルートはwithMethodsで応答するメソッドを宣言する（OPTIONSのAllowヘッダーに使う）
+
 	// ヘルスチェックエンドポイント
-	mux.Handle("/healthz", handleHealthz(log))
-	mux.Handle("/readyz", handleHealthz(log))
+	mux.Handle("/healthz", withMethods(handleHealthz(log, health), http.MethodGet))
+	mux.Handle("/readyz", withMethods(handleHealthz(log, health), http.MethodGet))
 
 	// GET /api/v1/blogs (全ブログ取得) とPOST /api/v1/blogs (ブログ作成)
 	// Go標準のmuxでは同じパスで異なるHTTPメソッドを処理するために
 	// HandlerFuncで条件分岐する必要がある
-	mux.HandleFunc("/api/v1/blogs", func(w http.ResponseWriter, r *http.Request) {
+	// POSTはIdempotency-Keyが同じ再送に対して保存済みのレスポンスを返す
+	// Content-Typeで単体作成（JSON）と一括作成（NDJSON）を切り替え、ALLOWED_CONTENT_TYPES以外は415
+	authors := newAuthorLimiter(cfg.AuthorPostsPerMinute)
+	create := idempotencyMiddleware(idempotency)(byContentType(cfg.AllowedContentTypes, map[string]http.Handler{
+		contentTypeJSON:   handleBlogsCreate(log, cfg, blogStore, authors),
+		contentTypeNDJSON: handleBlogsCreateNDJSON(log, cfg, blogStore, authors),
+	}))
+	mux.Handle("/api/v1/blogs", withMethods(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
-			handleBlogsGet(log, blogStore).ServeHTTP(w, r)
+			handleBlogsGet(log, cfg, blogStore).ServeHTTP(w, r)
 			return
 		}
 		if r.Method == http.MethodPost {
-			handleBlogsCreate(log, blogStore).ServeHTTP(w, r)
+			create.ServeHTTP(w, r)
 			return
 		}
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-	})
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}), http.MethodGet, http.MethodPost))
+
+	// GET /api/v1/blogs/recent (最新n件)
+	// 完全一致のパターンはプレフィックスより優先されるため /api/v1/blogs/ より先に評価される
+	mux.Handle("/api/v1/blogs/recent", withMethods(handleBlogsRecent(log, cfg, blogStore), http.MethodGet))
+
+	// GET /api/v1/blogs/count (一覧と同じ絞り込みでの件数)
+	mux.Handle("/api/v1/blogs/count", withMethods(handleBlogsCount(log, cfg, blogStore), http.MethodGet))
+
+	// GET /api/v1/blogs/export (NDJSONでの全件エクスポート、?after=<id>で再開)
+	mux.Handle("/api/v1/blogs/export", withMethods(handleBlogsExport(log, cfg, blogStore), http.MethodGet))
+
+	// GET, PUT, DELETE /api/v1/blogs/{id} とそのサブリソース
+	// パスの分解はhandleBlogsByIDが行う。メソッドの宣言のためにサブリソースごとに登録し、
+	// それ以外のパスはプレフィックスマッチで同じハンドラーに渡す（未知のサブリソースは404）
+	byID := handleBlogsByID(log, cfg, blogStore)
+	mux.Handle("/api/v1/blogs/{id}", withMethods(byID, http.MethodGet, http.MethodPut, http.MethodDelete))
+	mux.Handle("/api/v1/blogs/{id}/revisions", withMethods(byID, http.MethodGet))
+	mux.Handle("/api/v1/blogs/{id}/slug/regenerate", withMethods(byID, http.MethodPost))
+	mux.Handle("/api/v1/blogs/{id}/content", withMethods(byID, http.MethodGet, http.MethodHead))
+	mux.Handle("/api/v1/blogs/{id}/tags", withMethods(byID, http.MethodPut))
+	mux.Handle("/api/v1/blogs/", withMethods(byID))
+
+	// GET /api/v1/stats (管理ダッシュボード向けの集計値)
+	mux.Handle("/api/v1/stats", withMethods(handleStats(log, blogStore), http.MethodGet))
+
+	// どのルートにも一致しないリクエストはJSONの404を返し、メソッドごとに件数を数える
+	notFound := newNotFoundCounter()
+	mux.Handle("/", withMethods(handleNotFound(notFound)))
 
-	// GET, PUT, DELETE /api/v1/blogs/{id}
-	// Go標準のmuxでは動的パスパラメータが限定的なので、プレフィックスマッチを使用
-	mux.Handle("/api/v1/blogs/", handleBlogsByID(log, blogStore))
+	// 管理API（ADMIN_TOKENによる認証が必要）
+	adminAuth := adminAuthMiddleware(cfg.AdminToken)
+	mux.Handle("/api/v1/admin/ratelimits", withMethods(adminAuth(handleAdminRateLimits(log, limiter)), http.MethodGet))
+	mux.Handle("/api/v1/admin/snapshot", withMethods(adminAuth(handleAdminSnapshot(log, blogStore)), http.MethodGet))
+	mux.Handle("/api/v1/admin/restore", withMethods(adminAuth(handleAdminRestore(log, blogStore)), http.MethodPost))
+	mux.Handle("/api/v1/admin/notfound", withMethods(adminAuth(handleAdminNotFound(log, notFound)), http.MethodGet))
 }
@@ -1,12 +1,17 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/moko-poi/blog-api-server/internal/config"
 	"github.com/moko-poi/blog-api-server/internal/logger"
 	"github.com/moko-poi/blog-api-server/internal/store"
 )
@@ -16,7 +21,7 @@ func TestAddRoutes(t *testing.T) {
 	blogStore := store.NewMemoryBlogStore()
 	mux := http.NewServeMux()
 
-	addRoutes(mux, log, blogStore)
+	addRoutes(mux, log, &config.Config{}, blogStore, nil, nil, nil)
 
 	tests := []struct {
 		name           string
@@ -54,6 +59,12 @@ func TestAddRoutes(t *testing.T) {
 			path:           "/api/v1/blogs",
 			expectedStatus: http.StatusMethodNotAllowed,
 		},
+		{
+			name:           "GET recent blogs endpoint",
+			method:         http.MethodGet,
+			path:           "/api/v1/blogs/recent",
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:           "GET specific blog endpoint",
 			method:         http.MethodGet,
@@ -93,7 +104,7 @@ func TestAddRoutes_BlogsEndpointMethodRouting(t *testing.T) {
 	blogStore := store.NewMemoryBlogStore()
 	mux := http.NewServeMux()
 
-	addRoutes(mux, log, blogStore)
+	addRoutes(mux, log, &config.Config{}, blogStore, nil, nil, nil)
 
 	// Test that the routing logic correctly delegates to the right handlers
 	tests := []struct {
@@ -152,4 +163,186 @@ func TestAddRoutes_BlogsEndpointMethodRouting(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAddRoutes_MethodNotAllowed(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	mux := http.NewServeMux()
+
+	addRoutes(mux, log, &config.Config{}, blogStore, nil, nil, nil)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		expectedAllow string
+	}{
+		{
+			name:          "blogs collection",
+			method:        http.MethodDelete,
+			path:          "/api/v1/blogs",
+			expectedAllow: "GET, POST",
+		},
+		{
+			name:          "single blog",
+			method:        http.MethodPost,
+			path:          "/api/v1/blogs/some-id",
+			expectedAllow: "GET, PUT, DELETE",
+		},
+		{
+			name:          "revisions",
+			method:        http.MethodDelete,
+			path:          "/api/v1/blogs/some-id/revisions",
+			expectedAllow: "GET",
+		},
+		{
+			name:          "slug regeneration",
+			method:        http.MethodGet,
+			path:          "/api/v1/blogs/some-id/slug/regenerate",
+			expectedAllow: "POST",
+		},
+		{
+			name:          "stats",
+			method:        http.MethodPost,
+			path:          "/api/v1/stats",
+			expectedAllow: "GET",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.expectedAllow {
+				t.Errorf("expected Allow %q, got %q", tt.expectedAllow, allow)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected JSON content type, got %q", ct)
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("expected JSON body, got %q", w.Body.String())
+			}
+			if resp.Error != "Method not allowed" {
+				t.Errorf("expected error 'Method not allowed', got %q", resp.Error)
+			}
+		})
+	}
+}
+
+func TestRouteTemplate(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantTemplate string
+	}{
+		{name: "blog by ID", method: http.MethodGet, path: "/api/v1/blogs/abc", wantTemplate: "/api/v1/blogs/{id}"},
+		{name: "blog subresource", method: http.MethodGet, path: "/api/v1/blogs/abc/revisions", wantTemplate: "/api/v1/blogs/{id}/revisions"},
+		{name: "exact route", method: http.MethodGet, path: "/api/v1/blogs", wantTemplate: "/api/v1/blogs"},
+		{name: "no route", method: http.MethodGet, path: "/unknown", wantTemplate: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			srv, err := NewServer(logger.New(&logs, slog.LevelInfo), &config.Config{}, store.NewMemoryBlogStore())
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			srv.server.Handler.ServeHTTP(w, req)
+
+			var entry struct {
+				Path  string `json:"path"`
+				Route string `json:"route"`
+			}
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				if strings.Contains(line, `"msg":"request completed"`) {
+					json.Unmarshal([]byte(line), &entry)
+				}
+			}
+			if entry.Path != tt.path {
+				t.Fatalf("expected request log for %s, got %q", tt.path, logs.String())
+			}
+			if entry.Route != tt.wantTemplate {
+				t.Errorf("expected route %q, got %q", tt.wantTemplate, entry.Route)
+			}
+		})
+	}
+}
+
+func TestRouteOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		routeOptions bool
+		path         string
+		wantAllow    string
+	}{
+		{name: "collection", routeOptions: true, path: "/api/v1/blogs", wantAllow: "GET, POST, OPTIONS"},
+		{name: "item", routeOptions: true, path: "/api/v1/blogs/abc", wantAllow: "GET, PUT, DELETE, OPTIONS"},
+		{name: "item subresource", routeOptions: true, path: "/api/v1/blogs/abc/tags", wantAllow: "PUT, OPTIONS"},
+		{name: "fixed path under items", routeOptions: true, path: "/api/v1/blogs/recent", wantAllow: "GET, OPTIONS"},
+		{name: "health check", routeOptions: true, path: "/healthz", wantAllow: "GET, OPTIONS"},
+		{name: "admin without token", routeOptions: true, path: "/api/v1/admin/restore", wantAllow: "POST, OPTIONS"},
+		{name: "unknown path", routeOptions: true, path: "/nope", wantAllow: ""},
+		{name: "unknown item subresource", routeOptions: true, path: "/api/v1/blogs/abc/nope", wantAllow: ""},
+		{name: "disabled", routeOptions: false, path: "/api/v1/blogs/abc", wantAllow: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New(io.Discard, slog.LevelError)
+			srv, err := NewServer(log, &config.Config{RouteOptions: tt.routeOptions}, store.NewMemoryBlogStore())
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			w := httptest.NewRecorder()
+			srv.server.Handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("expected Allow %q, got %q", tt.wantAllow, allow)
+			}
+			// CORSのヘッダーはどちらの場合も付く
+			if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+				t.Error("expected CORS headers on the OPTIONS response")
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("expected no body, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouteMethods_EveryRouteDeclared(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	mux := &routeRecorder{ServeMux: http.NewServeMux()}
+	addRoutes(mux, log, &config.Config{}, store.NewMemoryBlogStore(), nil, nil, nil)
+
+	if len(mux.patterns) == 0 {
+		t.Fatal("expected registered routes")
+	}
+	// OPTIONSに応答するため、すべてのルートがwithMethodsで登録されていること
+	for _, pattern := range mux.patterns {
+		if _, ok := mux.methods[pattern]; !ok {
+			t.Errorf("route %q is registered without withMethods", pattern)
+		}
+	}
+	if got := mux.methods["/api/v1/blogs"]; !slices.Equal(got, []string{http.MethodGet, http.MethodPost}) {
+		t.Errorf("expected GET and POST for the collection, got %v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// routeTemplate holds the route template matched for a request
+// ルーティングはミドルウェアより内側で行われるため、外側のミドルウェアが処理後に読めるよう
+// 書き込み先をコンテキストに入れておき、ルート側で値を設定する
+type routeTemplate struct {
+	template string
+}
+
+// routeTemplateMiddleware makes the matched route template available to the
+// middlewares it wraps once the request has been routed
+func routeTemplateMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), routeTemplateKey, &routeTemplate{})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// withRouteTemplate records pattern as the route template before calling next
+func withRouteTemplate(pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setRouteTemplate(r.Context(), pattern)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setRouteTemplate records the route template for the request
+// プレフィックスで登録したルートは、ハンドラー内で /api/v1/blogs/{id} のような形に置き換える
+func setRouteTemplate(ctx context.Context, template string) {
+	if rt, ok := ctx.Value(routeTemplateKey).(*routeTemplate); ok {
+		rt.template = template
+	}
+}
+
+// RouteTemplateFromContext returns the route template matched for the request,
+// e.g. /api/v1/blogs/{id}, or "" if no route matched
+// 生のパスと異なりIDを含まないため、ログやメトリクスのラベルに使っても値の種類が増えない
+func RouteTemplateFromContext(ctx context.Context) string {
+	rt, ok := ctx.Value(routeTemplateKey).(*routeTemplate)
+	if !ok {
+		return ""
+	}
+	return rt.template
+}
@@ -2,12 +2,18 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
 	"github.com/moko-poi/blog-api-server/internal/logger"
 	"github.com/moko-poi/blog-api-server/internal/store"
 )
@@ -19,6 +25,33 @@ type Server struct {
 	logger    *logger.Logger
 	blogStore store.BlogStore
 	server    *http.Server
+	// drainingはシャットダウン開始後にtrueとなり、新規リクエストを拒否する
+	draining *atomic.Bool
+	// routesは登録済みのルートパターン（登録順）
+	routes []string
+	// idempotencyは期限切れキーの掃除のためStartでjanitorを起動する（無効時はnil）
+	idempotency *idempotencyStore
+	// limiterは拒否の集計ログのためStartでロガーを起動する（無効時はnil）
+	limiter *rateLimiter
+	// healthは/healthzが報告する劣化状態で、Startで起動するヘルスチェックが更新する
+	health *healthState
+
+	hooksMu       sync.Mutex
+	shutdownHooks []shutdownHook
+
+	// componentsはHTTPの処理が終わってから停止するバックグラウンド処理（lifecycle.go）
+	componentsMu sync.Mutex
+	components   []namedComponent
+
+	// listenerはStartで確保したリスナー（PORT=0で割り当てられたポートをAddrで返すため）
+	listenerMu sync.Mutex
+	listener   net.Listener
+}
+
+// shutdownHook is a named cleanup step run during shutdown
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
 }
 
 // コストラクタでは全ての依存関係を引数として受け取る
@@ -28,20 +61,85 @@ func NewServer(
 	cfg *config.Config,
 	blogstore store.BlogStore,
 ) (*Server, error) {
+	// 不正なSORTABLE_FIELDSとDEFAULT_SORTはリクエスト時ではなく起動時に検出する
+	for _, field := range cfg.SortableFields {
+		if _, ok := sortableFields[field]; !ok {
+			return nil, fmt.Errorf("invalid SORTABLE_FIELDS: unknown sort field: %s", field)
+		}
+	}
+	defaultSort, err := parseSort(cfg.DefaultSort)
+	if err == nil {
+		err = checkSortable(defaultSort, cfg.SortableFields)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_SORT: %w", err)
+	}
+	// 作者ごとの既定タグもリクエストのタグと同じ規則で起動時に検証する
+	for author, tags := range cfg.AuthorDefaultTags {
+		if problems := (domain.SetTagsRequest{Tags: tags}).Valid(context.Background()); len(problems) > 0 {
+			return nil, fmt.Errorf("invalid AUTHOR_DEFAULT_TAGS for %q: %s", author, problems["tags"])
+		}
+	}
+
 	// http.NewServeMuxを使用してルーティングを設定
-	mux := http.NewServeMux()
+	// 登録したパターンはPreflightでの一覧出力のために記録しておく
+	mux := &routeRecorder{ServeMux: http.NewServeMux()}
+
+	// レート制限はRATE_LIMIT_RPSが設定された場合のみ有効
+	var limiter *rateLimiter
+	if cfg.RateLimitRPS > 0 {
+		limiter = newRateLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst)
+		// 拒否の集計は出力する場合のみ行う（RATE_LIMIT_LOG_INTERVAL=0ではログに出さない）
+		if cfg.RateLimitLogInterval > 0 {
+			limiter.countRejections()
+		}
+	}
+
+	// Idempotency-Keyの保存はIDEMPOTENCY_TTLが0より大きい場合のみ有効
+	idempotency := newIdempotencyStore(cfg.IdempotencyTTL, cfg.IdempotencyMaxKeys)
 
 	// routes.goでルート定義を一箇所に集約
 	// API全体の構造が一目でわかる
-	addRoutes(mux, log, blogstore)
+	// /healthzが報告する状態（HEALTH_CHECK_INTERVAL指定時にStartでチェックを起動する）
+	health := newHealthState()
+
+	addRoutes(mux, log, cfg, blogstore, limiter, idempotency, health)
+
+	// シャットダウン開始を示すフラグ（shutdownでtrueにする）
+	draining := new(atomic.Bool)
 
 	// ミドルウェアの設定（逆順で実行される）
 	// adapter patternを使用してミドをルウェア構成
 	var handler http.Handler = mux
-	handler = corsMiddleware()(handler)             // CORS対応
-	handler = ratelimitMiddleware()(handler)        // レート制限
-	handler = panicRecoveryMiddleware(log)(handler) // パニックリカバリー
-	handler = loggingMiddleware(log)(handler)       // ログ出力
+	handler = validationMiddleware(validationConfig(cfg))(handler)        // バリデーションルール
+	handler = jsonLimitsMiddleware(jsonLimitsConfig(cfg))(handler)        // JSONの構造の上限
+	handler = bodyLimitMiddleware(cfg.MaxBodyBytes)(handler)              // リクエストボディサイズ上限
+	handler = readOnlyMiddleware(cfg.ReadOnly)(handler)                   // 読み取り専用モード
+	handler = fieldCaseMiddleware(cfg.JSONFieldCase)(handler)             // JSONフィールド命名規則
+	handler = escapeHTMLMiddleware(cfg.JSONEscapeHTML)(handler)           // JSON中のHTML文字のエスケープ
+	handler = acceptMiddleware(cfg.StrictAccept)(handler)                 // Acceptヘッダーの検証
+	handler = corsMiddleware(cfg.RouteOptions)(handler)                   // CORS対応
+	handler = disabledMethodsMiddleware(cfg.DisabledMethods)(handler)     // メソッド単位の無効化（CORSのOPTIONS応答より前）
+	handler = ratelimitMiddleware(limiter)(handler)                       // レート制限
+	handler = concurrencyLimitMiddleware(cfg.MaxConcurrentPerIP)(handler) // IPごとの同時リクエスト数制限
+	handler = gzipMiddleware(cfg.GzipLevel)(handler)                      // レスポンス圧縮
+	if cfg.RejectWhileDraining {
+		handler = drainMiddleware(draining)(handler) // シャットダウン中の新規リクエスト拒否
+	}
+	// ゲートウェイの共有シークレットの検査（正規化後のパスでヘルスチェックを判定する）
+	handler = requiredHeaderMiddleware(cfg.RequiredHeaderName, cfg.RequiredHeaderValue)(handler)
+	// Server-Timingヘッダー（ストア呼び出しとハンドラー全体の処理時間）
+	handler = serverTimingMiddleware(cfg.ServerTiming)(handler)
+	handler = cleanPathMiddleware(cfg.CleanPathRedirect)(handler)                // パスの正規化
+	handler = canonicalHostMiddleware(cfg.CanonicalHost)(handler)                // 正規ホストへのリダイレクト
+	handler = httpsRedirectMiddleware(cfg.HTTPSRedirect)(handler)                // HTTPからHTTPSへのリダイレクト
+	handler = hstsMiddleware(cfg.HSTSMaxAge, cfg.HSTSIncludeSubdomains)(handler) // Strict-Transport-Security
+	handler = urlLimitMiddleware(cfg.MaxURLLength, cfg.MaxQueryParams)(handler)  // URL長とクエリ数の上限
+	handler = panicRecoveryMiddleware(log)(handler)                              // パニックリカバリー
+	handler = loggingMiddleware(log)(handler)                                    // ログ出力
+	handler = logLevelMiddleware(cfg.TrustedProxies, cfg.AdminToken)(handler)    // X-Log-Levelによるログレベルの上書き（ログより外側）
+	handler = routeTemplateMiddleware()(handler)                                 // ルートテンプレートの記録（ログより外側）
+	handler = requestIDMiddleware(cfg.RequestIDHeader)(handler)                  // リクエストID（ログより外側で付与）
 
 	// HTTPサーバーの設定
 	// タイムアウト設定
@@ -51,6 +149,7 @@ func NewServer(
 		ReadTimeout:  cfg.ReadTimeout,  // 読み取りタイムアウト
 		WriteTimeout: cfg.WriteTimeout, // 書き込みタイムアウト
 		IdleTimeout:  30 * time.Second, // アイドルタイムアウト
+		TLSConfig:    tlsConfig(cfg),   // TLS_CERT_FILE指定時のみ使用される
 	}
 
 	return &Server{
@@ -58,11 +157,52 @@ func NewServer(
 		logger:    log,
 		blogStore: blogstore,
 		server:    httpServer,
+		draining:  draining,
+		routes:    mux.patterns,
+
+		idempotency: idempotency,
+		limiter:     limiter,
+		health:      health,
 	}, nil
 }
 
 // コンテキストを受け取って、Graceful shutdownに対応
 func (s *Server) Start(ctx context.Context) error {
+	// ポートを確保する前に依存関係を確認し、不備があれば即座に失敗させる
+	if err := s.Preflight(ctx); err != nil {
+		return err
+	}
+
+	// バックグラウンド処理はコンポーネントとして登録し、シャットダウン時はHTTPの処理が終わってから停止させる
+	// 期限切れのIdempotency-Keyを定期的に取り除く
+	if s.idempotency != nil {
+		s.RegisterComponent("idempotency janitor", startBackground(ctx, func(ctx context.Context) {
+			s.idempotency.runJanitor(ctx, s.idempotency.janitorInterval())
+		}))
+	}
+
+	// レート制限で拒否したリクエストを一定間隔ごとにまとめてログに出力する
+	// 停止が排出の後になるため、シャットダウン中の拒否も最後の集計に含まれる
+	if s.limiter != nil && s.config.RateLimitLogInterval > 0 {
+		s.RegisterComponent("rate limit rejection logger", startBackground(ctx, func(ctx context.Context) {
+			s.limiter.runRejectionLogger(ctx, s.logger, s.config.RateLimitLogInterval)
+		}))
+	}
+
+	// ストアの応答を定期的に確認し、遅延や失敗を/healthzに劣化として反映する
+	if pinger, ok := s.blogStore.(store.Pinger); ok && s.config.HealthCheckInterval > 0 {
+		s.RegisterComponent("health checker", startBackground(ctx, func(ctx context.Context) {
+			s.health.runChecker(ctx, s.logger, pinger, s.config.HealthCheckInterval, s.config.HealthDegradedLatency)
+		}))
+	}
+
+	// 期限切れの投稿をストアから定期的に削除する
+	if sweeper, ok := s.blogStore.(store.ExpirySweeper); ok && s.config.ExpirySweepInterval > 0 {
+		s.RegisterComponent("expiry sweeper", startBackground(ctx, func(ctx context.Context) {
+			runExpirySweeper(ctx, s.logger, sweeper, s.config.ExpirySweepInterval)
+		}))
+	}
+
 	// サーバーエラーを受信するためのチャネル
 	serverErr := make(chan error, 1)
 
@@ -76,9 +216,29 @@ func (s *Server) Start(ctx context.Context) error {
 			serverErr <- fmt.Errorf("failed to create listener: %w", err)
 			return
 		}
+		s.listenerMu.Lock()
+		s.listener = listener
+		s.listenerMu.Unlock()
+		s.logger.Info(ctx, "listening", "address", listener.Addr().String())
+
+		// TCP_KEEPALIVE指定時は受け付けた接続のkeep-alive間隔を上書き
+		if tcpListener, ok := listener.(*net.TCPListener); ok && s.config.TCPKeepAlive > 0 {
+			listener = &keepAliveListener{TCPListener: tcpListener, period: s.config.TCPKeepAlive, logger: s.logger}
+		}
+
+		// MAX_ACCEPT_RATE指定時は新規接続の受け付けを毎秒n件に抑える
+		if s.config.MaxAcceptRate > 0 {
+			listener = newRateLimitedListener(listener, s.config.MaxAcceptRate)
+		}
+
+		// TLS_CERT_FILE指定時はHTTPSで待ち受ける
+		serve := func() error { return s.server.Serve(listener) }
+		if s.config.TLSCertFile != "" {
+			serve = func() error { return s.server.ServeTLS(listener, s.config.TLSCertFile, s.config.TLSKeyFile) }
+		}
 
 		// http.ErrServerClosedはサーバーが正常にシャットダウン時のエラーなので除外
-		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			serverErr <- fmt.Errorf("server error: %w", err)
 		}
 	}()
@@ -87,13 +247,115 @@ func (s *Server) Start(ctx context.Context) error {
 	// select文でシグナル待ちとエラー処理同時に行う
 	select {
 	case err := <-serverErr:
-		return err
+		// 起動に失敗した場合もバックグラウンド処理を残さない
+		stopCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+		defer cancel()
+		return errors.Join(err, s.stopComponents(stopCtx))
 	case <-ctx.Done():
 		s.logger.Info(ctx, "shutdown signal received")
 		return s.shutdown() // コンテキストを渡してシャットダウン
 	}
 }
 
+// Addr returns the address the server is listening on, or nil before Start has bound it
+// PORT=0で起動した場合に、OSが割り当てたポートを知るために使う（主にテスト用）
+func (s *Server) Addr() net.Addr {
+	s.listenerMu.Lock()
+	defer s.listenerMu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Preflight checks the server's dependencies before it starts serving
+// ストアの疎通確認とバックエンドに必要な設定の確認を行い、登録済みルートの一覧をログに出力する
+// Startから呼ばれるが、デプロイ前の確認用に単独でも呼び出せる
+func (s *Server) Preflight(ctx context.Context) error {
+	if s.blogStore == nil {
+		return errors.New("preflight: no blog store configured")
+	}
+	if err := checkStoreBackend(s.config); err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+	if pinger, ok := s.blogStore.(store.Pinger); ok {
+		if err := pinger.Ping(ctx); err != nil {
+			return fmt.Errorf("preflight: ping store: %w", err)
+		}
+	}
+	if s.config.TLSCertFile != "" {
+		if _, err := tls.LoadX509KeyPair(s.config.TLSCertFile, s.config.TLSKeyFile); err != nil {
+			return fmt.Errorf("preflight: load TLS certificate: %w", err)
+		}
+	}
+
+	s.logger.Info(ctx, "preflight passed",
+		"store_backend", s.config.StoreBackend,
+		"routes", s.routes,
+	)
+	return nil
+}
+
+// tlsConfig builds the TLS settings from the configuration
+// TLSCipherSuitesがnilの場合はGoの既定（安全なスイートのみ）が使われる
+func tlsConfig(cfg *config.Config) *tls.Config {
+	return &tls.Config{
+		MinVersion:   cfg.TLSMinVersion,
+		CipherSuites: cfg.TLSCipherSuites,
+	}
+}
+
+// checkStoreBackend verifies that the settings required by STORE_BACKEND are present
+func checkStoreBackend(cfg *config.Config) error {
+	switch cfg.StoreBackend {
+	case "file":
+		if cfg.FileStoreDir == "" {
+			return fmt.Errorf("store backend %q requires FILE_STORE_DIR", cfg.StoreBackend)
+		}
+	case "http":
+		if cfg.RemoteStoreURL == "" {
+			return fmt.Errorf("store backend %q requires REMOTE_STORE_URL", cfg.StoreBackend)
+		}
+	case "sqlite":
+		if cfg.SQLitePath == "" {
+			return fmt.Errorf("store backend %q requires SQLITE_PATH", cfg.StoreBackend)
+		}
+	case "postgres":
+		if cfg.DatabaseURL == "" {
+			return fmt.Errorf("store backend %q requires DATABASE_URL", cfg.StoreBackend)
+		}
+	}
+	return nil
+}
+
+// RegisterShutdownHook adds a cleanup step that runs after HTTP requests have drained
+// and the components registered with RegisterComponent have stopped
+// フックは登録順に実行され、失敗しても残りのフックは実行される
+// 各フックにはSHUTDOWN_TIMEOUTのコンテキストが渡される（全フックで共有）
+func (s *Server) RegisterShutdownHook(name string, fn func(ctx context.Context) error) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, shutdownHook{name: name, fn: fn})
+}
+
+// runShutdownHooks runs every registered hook in order and joins their errors
+func (s *Server) runShutdownHooks(ctx context.Context) error {
+	s.hooksMu.Lock()
+	hooks := slices.Clone(s.shutdownHooks)
+	s.hooksMu.Unlock()
+
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook.fn(ctx); err != nil {
+			s.logger.Error(ctx, "shutdown hook failed", "hook", hook.name, "error", err)
+			errs = append(errs, fmt.Errorf("shutdown hook %s: %w", hook.name, err))
+			continue
+		}
+		s.logger.Info(ctx, "shutdown hook completed", "hook", hook.name)
+	}
+	return errors.Join(errs...)
+}
+
 // グレースフルシャットダウンの実装
 // 進行中のリクエストを完了させてからサーバーを停止
 func (s *Server) shutdown() error {
@@ -103,22 +365,139 @@ func (s *Server) shutdown() error {
 
 	s.logger.Info(shutdownCtx, "shutting down server", "timeout", s.config.ShutdownTimeout)
 
+	// keep-alive接続上で届く新規リクエストを拒否し始める
+	s.draining.Store(true)
+
 	// Shutdownメソッドは進行中のリクエストを完了するまで待機する
+	// 失敗した場合もキャッシュのフラッシュなどの後片付けは行う
 	if err := s.server.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("failed to shutdown server: %w", err)
+		// コンテキストを無視するハンドラーが残っていると、接続が開いたままプロセスが終了してしまう
+		// SHUTDOWN_FORCE_CLOSE指定時は残りの接続を強制的に閉じる
+		if s.config.ShutdownForceClose {
+			s.logger.Warn(shutdownCtx, "graceful shutdown timed out, forcing close", "error", err)
+			if closeErr := s.server.Close(); closeErr != nil {
+				err = errors.Join(err, closeErr)
+			}
+			return errors.Join(
+				fmt.Errorf("shutdown forced after timeout: %w", err),
+				s.stopComponents(shutdownCtx),
+				s.runShutdownHooks(shutdownCtx),
+			)
+		}
+		return errors.Join(
+			fmt.Errorf("failed to shutdown server: %w", err),
+			s.stopComponents(shutdownCtx),
+			s.runShutdownHooks(shutdownCtx),
+		)
+	}
+
+	// バックグラウンド処理がストアを使い終えてから、ストアを閉じるなどのフックを実行する
+	if err := errors.Join(s.stopComponents(shutdownCtx), s.runShutdownHooks(shutdownCtx)); err != nil {
+		return err
 	}
 
 	s.logger.Info(shutdownCtx, "server shutdown complete")
 	return nil
 }
 
-// サーバーの準備完了を待つヘルパー関数
-// テスト時にサーバーが起動するまで待機するために使用
-func waitForReady(ctx context.Context, timeout time.Duration, endpoint string) error {
+// keepAliveListener sets the TCP keep-alive period on accepted connections
+// http.Serverが内部で使っていたtcpKeepAliveListenerと同様の役割
+// keep-aliveの設定に失敗した接続も閉じずにそのまま使う（Acceptがエラーを返すとServeが終了するため）
+type keepAliveListener struct {
+	*net.TCPListener
+	period time.Duration
+	logger *logger.Logger
+}
+
+func (l *keepAliveListener) Accept() (net.Conn, error) {
+	conn, err := l.AcceptTCP()
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetKeepAlive(true); err != nil {
+		l.logger.Warn(context.Background(), "failed to enable keep-alive", "remote_addr", conn.RemoteAddr().String(), "error", err)
+		return conn, nil
+	}
+	if err := conn.SetKeepAlivePeriod(l.period); err != nil {
+		l.logger.Warn(context.Background(), "failed to set keep-alive period", "remote_addr", conn.RemoteAddr().String(), "error", err)
+	}
+	return conn, nil
+}
+
+// DefaultReadyPollInterval is the poll interval WaitForReady uses when none is given
+const DefaultReadyPollInterval = 250 * time.Millisecond
+
+// rateLimitedListener limits how fast new connections are accepted
+// 接続フラッド対策として、超過分は拒否せずAcceptを遅らせる
+// 待っている接続はカーネルのバックログに留まり、溢れた分はOSが拒否する
+// 待機中にCloseされた場合はすぐにnet.ErrClosedを返し、シャットダウンを遅らせない
+type rateLimitedListener struct {
+	net.Listener
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// newRateLimitedListener wraps l to accept at most perSecond connections per second
+// perSecondはconfig.MinAcceptRate以上であること（設定の読み込み時に検証済み）
+func newRateLimitedListener(l net.Listener, perSecond float64) *rateLimitedListener {
+	return &rateLimitedListener{
+		Listener: l,
+		interval: time.Duration(float64(time.Second) / perSecond),
+		closed:   make(chan struct{}),
+	}
+}
+
+// Close closes the listener and wakes up a waiting Accept
+func (l *rateLimitedListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return l.Listener.Close()
+}
+
+func (l *rateLimitedListener) Accept() (net.Conn, error) {
+	// 次に受け付け可能な時刻を予約してから待つ（並行してAcceptされても間隔を保つ）
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-l.closed:
+			return nil, net.ErrClosed
+		}
+	}
+	return l.Listener.Accept()
+}
+
+// WaitForReady polls endpoint until it answers 200 OK, timeout elapses or ctx is done
+// 結合テストでサーバーが起動するまで待機するために使用する
+// interval が0以下の場合は DefaultReadyPollInterval でポーリングする
+func WaitForReady(ctx context.Context, timeout time.Duration, endpoint string, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultReadyPollInterval
+	}
+
+	// タイムアウトもコンテキストに載せ、待機中・リクエスト中のどちらでも即座に抜けられるようにする
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	client := &http.Client{
 		Timeout: 1 * time.Second,
 	}
-	startTime := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
 	// ポーリングによる準備完了チェック
 	for {
@@ -128,23 +507,21 @@ func waitForReady(ctx context.Context, timeout time.Duration, endpoint string) e
 		}
 
 		resp, err := client.Do(req)
-		if err == nil && resp.StatusCode == http.StatusOK {
-			resp.Body.Close()
-			return nil // 準備完了
-		}
-		if resp != nil {
+		if err == nil {
 			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil // 準備完了
+			}
 		}
 
 		// コンテキストキャンセルまたはタイムアウトをチェック
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			if time.Since(startTime) >= timeout {
-				return fmt.Errorf("timeout reached while waiting for endpoint")
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("timeout reached while waiting for %s: %w", endpoint, ctx.Err())
 			}
-			time.Sleep(250 * time.Millisecond) // 短い間隔でリトライ
+			return ctx.Err()
+		case <-ticker.C:
 		}
 	}
 }
@@ -0,0 +1,624 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestKeepAliveListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	listener := &keepAliveListener{TCPListener: ln.(*net.TCPListener), period: 45 * time.Second, logger: logger.New(io.Discard, slog.LevelError)}
+
+	accepted := make(chan net.Conn, 1)
+	acceptErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			acceptErr <- err
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case conn := <-accepted:
+		defer conn.Close()
+		if _, ok := conn.(*net.TCPConn); !ok {
+			t.Errorf("expected *net.TCPConn, got %T", conn)
+		}
+	case err := <-acceptErr:
+		t.Fatalf("accept failed: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for accept")
+	}
+}
+
+func TestRateLimitedListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	const (
+		rate  = 20.0 // 50ms間隔
+		conns = 5
+	)
+	listener := newRateLimitedListener(ln, rate)
+
+	for i := 0; i < conns; i++ {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		defer conn.Close()
+	}
+
+	// 接続は全てバックログに揃っているので、所要時間は受け付け間隔だけで決まる
+	start := time.Now()
+	for i := 0; i < conns; i++ {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("accept failed: %v", err)
+		}
+		conn.Close()
+	}
+	elapsed := time.Since(start)
+
+	// 1件目は即時、残り4件は50ms間隔
+	minimum := time.Duration(conns-1) * time.Second / rate
+	if elapsed < minimum {
+		t.Errorf("expected accepts to be throttled to at least %v, took %v", minimum, elapsed)
+	}
+	if elapsed > minimum+time.Second {
+		t.Errorf("expected accepts to finish near %v, took %v", minimum, elapsed)
+	}
+}
+
+func TestRateLimitedListener_Close(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	// 1時間に1件なので、2件目のAcceptは待機に入る
+	listener := newRateLimitedListener(ln, 1.0/3600)
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	accepted, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("accept failed: %v", err)
+	}
+	accepted.Close()
+
+	acceptErr := make(chan error, 1)
+	go func() {
+		_, err := listener.Accept()
+		acceptErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	listener.Close()
+
+	select {
+	case err := <-acceptErr:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("expected net.ErrClosed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Close to wake up the waiting Accept")
+	}
+}
+
+func TestNewServer_InvalidDefaultSort(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{DefaultSort: "popularity:desc"}
+
+	if _, err := NewServer(log, cfg, store.NewMemoryBlogStore()); err == nil {
+		t.Error("expected error for unknown DEFAULT_SORT field")
+	}
+}
+
+func TestNewServer_InvalidSortableFields(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{name: "unknown field", cfg: &config.Config{SortableFields: []string{"created_at", "popularity"}}},
+		{name: "default sort not allowed", cfg: &config.Config{DefaultSort: "created_at:asc", SortableFields: []string{"title"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewServer(log, tt.cfg, store.NewMemoryBlogStore()); err == nil {
+				t.Error("expected error for invalid SORTABLE_FIELDS")
+			}
+		})
+	}
+}
+
+func TestNewServer_InvalidAuthorDefaultTags(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	cfg := &config.Config{AuthorDefaultTags: map[string][]string{"alice": {"not a tag"}}}
+
+	if _, err := NewServer(log, cfg, store.NewMemoryBlogStore()); err == nil {
+		t.Error("expected error for an invalid default tag")
+	}
+}
+
+// pingFailStore is a memory store whose Ping always fails
+type pingFailStore struct {
+	*store.MemoryBlogStore
+	err error
+}
+
+func (s *pingFailStore) Ping(ctx context.Context) error {
+	return s.err
+}
+
+func TestServer_Preflight(t *testing.T) {
+	errUnreachable := errors.New("store unreachable")
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		blogStore store.BlogStore
+		wantErr   bool
+		wantIs    error
+	}{
+		{
+			name:      "healthy store",
+			cfg:       &config.Config{StoreBackend: "memory"},
+			blogStore: store.NewMemoryBlogStore(),
+		},
+		{
+			name:      "ping fails",
+			cfg:       &config.Config{StoreBackend: "memory"},
+			blogStore: &pingFailStore{MemoryBlogStore: store.NewMemoryBlogStore(), err: errUnreachable},
+			wantErr:   true,
+			wantIs:    errUnreachable,
+		},
+		{
+			name:      "backend missing its settings",
+			cfg:       &config.Config{StoreBackend: "postgres"},
+			blogStore: store.NewMemoryBlogStore(),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New(io.Discard, slog.LevelError)
+			srv, err := NewServer(log, tt.cfg, tt.blogStore)
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			err = srv.Preflight(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("expected %v, got %v", tt.wantIs, err)
+			}
+		})
+	}
+}
+
+func TestServer_StartFailsPreflightBeforeListening(t *testing.T) {
+	// ポートを占有したままにしておく。Startがリッスンを試みていればlistenerのエラーになる
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	env := map[string]string{"HOST": "127.0.0.1", "PORT": strconv.Itoa(port)}
+	cfg, err := config.Load(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	errUnreachable := errors.New("store unreachable")
+	log := logger.New(io.Discard, slog.LevelError)
+	srv, err := NewServer(log, cfg, &pingFailStore{MemoryBlogStore: store.NewMemoryBlogStore(), err: errUnreachable})
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	err = srv.Start(context.Background())
+	if !errors.Is(err, errUnreachable) {
+		t.Errorf("expected preflight ping error, got %v", err)
+	}
+}
+
+func TestServer_ShutdownHooks(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	srv, err := NewServer(log, &config.Config{ShutdownTimeout: time.Second}, store.NewMemoryBlogStore())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var ran []string
+	errFlush := errors.New("flush failed")
+	srv.RegisterShutdownHook("flush-cache", func(ctx context.Context) error {
+		ran = append(ran, "flush-cache")
+		return errFlush
+	})
+	srv.RegisterShutdownHook("close-webhooks", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected hook context to carry the shutdown deadline")
+		}
+		ran = append(ran, "close-webhooks")
+		return nil
+	})
+
+	err = srv.shutdown()
+	if !errors.Is(err, errFlush) {
+		t.Errorf("expected shutdown to report the failed hook, got %v", err)
+	}
+
+	// 失敗したフックの後も残りのフックが登録順に実行されること
+	want := []string{"flush-cache", "close-webhooks"}
+	if fmt.Sprint(ran) != fmt.Sprint(want) {
+		t.Errorf("expected hooks to run in order %v, got %v", want, ran)
+	}
+}
+
+// fakeComponent records when it is stopped
+type fakeComponent struct {
+	name    string
+	err     error
+	stopped *[]string
+	mu      *sync.Mutex
+}
+
+func (c fakeComponent) Stop(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("expected the stop context to carry the shutdown deadline")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.stopped = append(*c.stopped, c.name)
+	return c.err
+}
+
+func TestServer_Components(t *testing.T) {
+	env := map[string]string{"HOST": "127.0.0.1", "PORT": "0", "SHUTDOWN_TIMEOUT": "1s"}
+	cfg, err := config.Load(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	log := logger.New(io.Discard, slog.LevelError)
+	srv, err := NewServer(log, cfg, store.NewMemoryBlogStore())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	var (
+		mu      sync.Mutex
+		stopped []string
+	)
+	errDispatcher := errors.New("dispatcher stuck")
+	srv.RegisterComponent("sse hub", fakeComponent{name: "sse hub", stopped: &stopped, mu: &mu})
+	srv.RegisterComponent("webhook dispatcher", fakeComponent{name: "webhook dispatcher", err: errDispatcher, stopped: &stopped, mu: &mu})
+	// バックグラウンドのgoroutineはシャットダウン開始後もHTTPの処理が終わるまで動き続ける
+	ticks := make(chan struct{}, 1)
+	srv.RegisterComponent("janitor", startBackground(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		ticks <- struct{}{}
+	}))
+	srv.RegisterShutdownHook("close store", func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = append(stopped, "close store")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Addr() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the server to bind an address")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Start to return after shutdown")
+	}
+	if !errors.Is(err, errDispatcher) {
+		t.Errorf("expected shutdown to report the failed component, got %v", err)
+	}
+
+	select {
+	case <-ticks:
+	default:
+		t.Error("expected the background goroutine to be stopped")
+	}
+	// 失敗したコンポーネントの後も残りが後から登録した順に停止し、その後でフックが実行されること
+	want := []string{"webhook dispatcher", "sse hub", "close store"}
+	if fmt.Sprint(stopped) != fmt.Sprint(want) {
+		t.Errorf("expected stop order %v, got %v", want, stopped)
+	}
+}
+
+func TestServer_ShutdownForceClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	host, port, _ := net.SplitHostPort(addr)
+	env := map[string]string{
+		"HOST":                 host,
+		"PORT":                 port,
+		"SHUTDOWN_TIMEOUT":     "100ms",
+		"SHUTDOWN_FORCE_CLOSE": "true",
+	}
+	cfg, err := config.Load(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	var logs bytes.Buffer
+	srv, err := NewServer(logger.New(&logs, slog.LevelInfo), cfg, store.NewMemoryBlogStore())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	// コンテキストを無視してタイムアウトを超えてもブロックし続けるハンドラー
+	release := make(chan struct{})
+	defer close(release)
+	entered := make(chan struct{})
+	srv.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			close(entered)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	startErr := make(chan error, 1)
+	go func() { startErr <- srv.Start(ctx) }()
+
+	baseURL := "http://" + addr
+	if err := WaitForReady(ctx, 5*time.Second, baseURL, 10*time.Millisecond); err != nil {
+		t.Fatalf("server not ready: %v", err)
+	}
+
+	clientErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/block")
+		if err == nil {
+			resp.Body.Close()
+		}
+		clientErr <- err
+	}()
+	<-entered
+
+	cancel()
+	select {
+	case err := <-startErr:
+		if err == nil {
+			t.Error("expected forced shutdown to be reported as an error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not return after the timeout")
+	}
+
+	// Closeで接続が切断され、ブロック中のリクエストはエラーになる
+	select {
+	case err := <-clientErr:
+		if err == nil {
+			t.Error("expected the blocked request's connection to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked request was not terminated by Close")
+	}
+	if !strings.Contains(logs.String(), "forcing close") {
+		t.Errorf("expected forced close to be logged, got %s", logs.String())
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		wantMinVersion uint16
+		wantSuites     []uint16
+	}{
+		{
+			name:           "Go defaults",
+			env:            map[string]string{},
+			wantMinVersion: tls.VersionTLS12,
+			wantSuites:     nil,
+		},
+		{
+			name:           "modern suites and TLS 1.3",
+			env:            map[string]string{"TLS_CIPHER_SUITES": "modern", "TLS_MIN_VERSION": "1.3"},
+			wantMinVersion: tls.VersionTLS13,
+			wantSuites:     config.ModernCipherSuites,
+		},
+		{
+			name:           "explicit list",
+			env:            map[string]string{"TLS_CIPHER_SUITES": "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+			wantMinVersion: tls.VersionTLS12,
+			wantSuites:     []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Load(func(key string) string { return tt.env[key] })
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			srv, err := NewServer(logger.New(io.Discard, slog.LevelError), cfg, store.NewMemoryBlogStore())
+			if err != nil {
+				t.Fatalf("failed to create server: %v", err)
+			}
+
+			got := srv.server.TLSConfig
+			if got.MinVersion != tt.wantMinVersion {
+				t.Errorf("expected min version %x, got %x", tt.wantMinVersion, got.MinVersion)
+			}
+			if !slices.Equal(got.CipherSuites, tt.wantSuites) {
+				t.Errorf("expected cipher suites %v, got %v", tt.wantSuites, got.CipherSuites)
+			}
+		})
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	// 空いているポートを確保してからサーバーを起動する
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	env := map[string]string{"HOST": "127.0.0.1", "PORT": strconv.Itoa(port)}
+	cfg, err := config.Load(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	log := logger.New(io.Discard, slog.LevelError)
+	srv, err := NewServer(log, cfg, store.NewMemoryBlogStore())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	endpoint := fmt.Sprintf("http://%s/healthz", cfg.Address())
+	if err := WaitForReady(context.Background(), 5*time.Second, endpoint, 10*time.Millisecond); err != nil {
+		t.Fatalf("expected server to become ready, got %v", err)
+	}
+}
+
+func TestServer_Addr(t *testing.T) {
+	env := map[string]string{"HOST": "127.0.0.1", "PORT": "0"}
+	cfg, err := config.Load(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	log := logger.New(io.Discard, slog.LevelError)
+	srv, err := NewServer(log, cfg, store.NewMemoryBlogStore())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if addr := srv.Addr(); addr != nil {
+		t.Fatalf("expected no address before Start, got %v", addr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Startはリスナーをgoroutineで確保するため、割り当てられるまで待つ
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Addr() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the server to bind an address")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	addr := srv.Addr().(*net.TCPAddr)
+	if addr.Port == 0 {
+		t.Fatal("expected an assigned port, got 0")
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/healthz", addr))
+	if err != nil {
+		t.Fatalf("failed to reach the discovered address: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestWaitForReady_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	err := WaitForReady(context.Background(), 100*time.Millisecond, ts.URL, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestWaitForReady_Cancelled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// ポーリング間隔が長くてもキャンセルで即座に戻ること
+	start := time.Now()
+	err := WaitForReady(ctx, time.Minute, ts.URL, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected prompt return after cancel, took %v", elapsed)
+	}
+}
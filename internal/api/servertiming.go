@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// serverTiming accumulates the durations reported in the Server-Timing header
+// NDJSONの一括作成などでストアを複数回呼ぶため、storeは合計時間とする
+type serverTiming struct {
+	start time.Time
+	mu    sync.Mutex
+	store time.Duration
+}
+
+// timeStore starts timing a store call and returns the function that stops it
+// SERVER_TIMINGが無効な場合は何もしない関数を返す
+func timeStore(ctx context.Context) func() {
+	timing, ok := ctx.Value(serverTimingKey).(*serverTiming)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		timing.mu.Lock()
+		timing.store += elapsed
+		timing.mu.Unlock()
+	}
+}
+
+// header returns the Server-Timing header value, in milliseconds
+func (t *serverTiming) header() string {
+	t.mu.Lock()
+	store := t.store
+	t.mu.Unlock()
+	return fmt.Sprintf("store;dur=%.2f, total;dur=%.2f", milliseconds(store), milliseconds(time.Since(t.start)))
+}
+
+// milliseconds converts d to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// serverTimingMiddleware adds a Server-Timing header with the store and total handler durations
+// ヘッダーはレスポンスヘッダーの送信直前に付けるため、totalはボディの書き込み時間を含まない
+func serverTimingMiddleware(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timing := &serverTiming{start: time.Now()}
+			ctx := context.WithValue(r.Context(), serverTimingKey, timing)
+			next.ServeHTTP(&serverTimingWriter{ResponseWriter: w, timing: timing}, r.WithContext(ctx))
+		})
+	}
+}
+
+// serverTimingWriter sets the Server-Timing header when the response headers are written
+type serverTimingWriter struct {
+	http.ResponseWriter
+	timing      *serverTiming
+	wroteHeader bool
+}
+
+func (w *serverTimingWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Server-Timing", w.timing.header())
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *serverTimingWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *serverTimingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+// slowGetStore delays GetByID so the store duration is measurable
+type slowGetStore struct {
+	*store.MemoryBlogStore
+	delay time.Duration
+}
+
+func (s slowGetStore) GetByID(ctx context.Context, id string) (*domain.Blog, error) {
+	time.Sleep(s.delay)
+	return s.MemoryBlogStore.GetByID(ctx, id)
+}
+
+// parseServerTiming returns the durations in a Server-Timing header by metric name
+func parseServerTiming(t *testing.T, header string) map[string]float64 {
+	t.Helper()
+	metrics := make(map[string]float64)
+	for _, metric := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(metric), ";")
+		durStr, ok := strings.CutPrefix(params, "dur=")
+		if !ok {
+			t.Fatalf("expected a dur parameter in %q", metric)
+		}
+		dur, err := strconv.ParseFloat(durStr, 64)
+		if err != nil {
+			t.Fatalf("failed to parse duration in %q: %v", metric, err)
+		}
+		metrics[name] = dur
+	}
+	return metrics
+}
+
+func TestServerTimingMiddleware(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	memStore := store.NewMemoryBlogStore()
+	memStore.Create(context.Background(), &domain.Blog{ID: "1", Title: "Title", CreatedAt: time.Now()})
+	blogStore := slowGetStore{MemoryBlogStore: memStore, delay: 5 * time.Millisecond}
+
+	newHandler := func(enabled bool) http.Handler {
+		mux := http.NewServeMux()
+		addRoutes(mux, log, &config.Config{}, blogStore, nil, nil, nil)
+		return serverTimingMiddleware(enabled)(mux)
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/1", nil)
+		w := httptest.NewRecorder()
+		newHandler(true).ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		header := w.Header().Get("Server-Timing")
+		metrics := parseServerTiming(t, header)
+		storeDur, ok := metrics["store"]
+		if !ok {
+			t.Fatalf("expected a store metric, got %q", header)
+		}
+		total, ok := metrics["total"]
+		if !ok {
+			t.Fatalf("expected a total metric, got %q", header)
+		}
+		if storeDur < 5 {
+			t.Errorf("expected store duration of at least 5ms, got %v", storeDur)
+		}
+		if total < storeDur {
+			t.Errorf("expected total %v to include store %v", total, storeDur)
+		}
+	})
+
+	t.Run("no store call", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		w := httptest.NewRecorder()
+		newHandler(true).ServeHTTP(w, req)
+
+		if metrics := parseServerTiming(t, w.Header().Get("Server-Timing")); metrics["store"] != 0 {
+			t.Errorf("expected zero store duration, got %v", metrics["store"])
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/1", nil)
+		w := httptest.NewRecorder()
+		newHandler(false).ServeHTTP(w, req)
+
+		if header := w.Header().Get("Server-Timing"); header != "" {
+			t.Errorf("expected no Server-Timing header, got %q", header)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package api
+
+import "sync"
+
+// flightGroup shares the result of one call among concurrent calls with the same key
+// golang.org/x/sync/singleflightと同じ考え方だが、依存を増やさないよう必要な分だけ実装する
+// 結果を共有するのは実行中の呼び出しのみで、完了後の呼び出しは改めてfnを実行する（キャッシュではない）
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+// flightCall is a call in progress or completed
+type flightCall[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// newFlightGroup returns a group, or nil (calls are not shared) if disabled
+func newFlightGroup[T any](enabled bool) *flightGroup[T] {
+	if !enabled {
+		return nil
+	}
+	return &flightGroup[T]{calls: make(map[string]*flightCall[T])}
+}
+
+// do calls fn, or waits for the call already running for key and returns its result
+// shared reports whether the result came from another caller's call
+// 無効な場合（nil）は常にfnを呼び出す
+func (g *flightGroup[T]) do(key string, fn func() (T, error)) (value T, err error, shared bool) {
+	if g == nil {
+		value, err = fn()
+		return value, err, false
+	}
+
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.value, call.err, true
+	}
+	call := &flightCall[T]{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	// fnがpanicしても待っている呼び出しを解放する
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = fn()
+	return call.value, call.err, false
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+// countingGetStore counts GetByID calls, each taking delay, to make concurrent calls overlap
+type countingGetStore struct {
+	*store.MemoryBlogStore
+	delay time.Duration
+	calls atomic.Int64
+}
+
+func (s *countingGetStore) GetByID(ctx context.Context, id string) (*domain.Blog, error) {
+	s.calls.Add(1)
+	time.Sleep(s.delay)
+	return s.MemoryBlogStore.GetByID(ctx, id)
+}
+
+func TestHandleBlogGet_Singleflight(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		maxCalls int64
+	}{
+		{name: "enabled", enabled: true, maxCalls: 5},
+		{name: "disabled", enabled: false, maxCalls: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memStore := store.NewMemoryBlogStore()
+			memStore.Create(context.Background(), &domain.Blog{ID: "1", Title: "Title", CreatedAt: time.Now()})
+			blogStore := &countingGetStore{MemoryBlogStore: memStore, delay: 50 * time.Millisecond}
+			handler := handleBlogsByID(log, &config.Config{SingleflightReads: tt.enabled}, blogStore)
+
+			const n = 50
+			start := make(chan struct{})
+			var wg sync.WaitGroup
+			codes := make([]int, n)
+			for i := range n {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/blogs/1", nil))
+					codes[i] = w.Code
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			for i, code := range codes {
+				if code != http.StatusOK {
+					t.Fatalf("request %d: expected status %d, got %d", i, http.StatusOK, code)
+				}
+			}
+			calls := blogStore.calls.Load()
+			if calls > tt.maxCalls {
+				t.Errorf("expected at most %d store calls for %d reads, got %d", tt.maxCalls, n, calls)
+			}
+			if !tt.enabled && calls != n {
+				t.Errorf("expected one store call per read when disabled, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestFlightGroup_SequentialCallsAreNotShared(t *testing.T) {
+	group := newFlightGroup[int](true)
+	calls := 0
+	for range 3 {
+		_, _, shared := group.do("key", func() (int, error) {
+			calls++
+			return calls, nil
+		})
+		if shared {
+			t.Error("expected a completed call not to be shared")
+		}
+	}
+	// 完了した呼び出しの結果はキャッシュしない
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// sortOrder is a listing order parsed from "field:asc" or "field:desc"
+type sortOrder struct {
+	Field string
+	Desc  bool
+}
+
+// defaultSortOrder is used when neither DEFAULT_SORT nor ?sort= is given
+var defaultSortOrder = sortOrder{Field: "created_at"}
+
+// sortableFields maps each field the API knows how to sort by to its comparison function
+// 実際に ?sort= で指定できるのはSORTABLE_FIELDSで許可したフィールドのみ
+var sortableFields = map[string]func(a, b *domain.Blog) int{
+	"created_at": func(a, b *domain.Blog) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *domain.Blog) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	"title":      func(a, b *domain.Blog) int { return strings.Compare(a.Title, b.Title) },
+	"author":     func(a, b *domain.Blog) int { return strings.Compare(a.Author, b.Author) },
+	"category":   func(a, b *domain.Blog) int { return strings.Compare(a.Category, b.Category) },
+}
+
+// defaultSortableFields are the fields listings may be sorted by when SORTABLE_FIELDS is unset
+var defaultSortableFields = []string{"created_at", "updated_at", "title"}
+
+// checkSortable reports an error if o sorts by a field outside allowed
+// allowedがnilの場合はdefaultSortableFieldsを使う
+func checkSortable(o sortOrder, allowed []string) error {
+	if allowed == nil {
+		allowed = defaultSortableFields
+	}
+	if !slices.Contains(allowed, o.Field) {
+		return fmt.Errorf("sorting by %s is not allowed", o.Field)
+	}
+	return nil
+}
+
+// parseSort parses a sort specification such as "created_at:desc"
+// 方向を省略した場合は昇順、空文字の場合はdefaultSortOrderを返す
+func parseSort(s string) (sortOrder, error) {
+	if s == "" {
+		return defaultSortOrder, nil
+	}
+
+	field, dir, _ := strings.Cut(s, ":")
+	if _, ok := sortableFields[field]; !ok {
+		return sortOrder{}, fmt.Errorf("unknown sort field: %s", field)
+	}
+
+	switch dir {
+	case "", "asc":
+		return sortOrder{Field: field}, nil
+	case "desc":
+		return sortOrder{Field: field, Desc: true}, nil
+	default:
+		return sortOrder{}, fmt.Errorf("unknown sort direction: %s", dir)
+	}
+}
+
+// apply sorts blogs in place
+func (o sortOrder) apply(blogs []*domain.Blog) {
+	slices.SortFunc(blogs, o.compare)
+}
+
+// compare orders a before b according to o
+// 主キーが同じ値の場合でも順序が毎回同じになるよう、常にIDの昇順で比較を打ち切る
+func (o sortOrder) compare(a, b *domain.Blog) int {
+	c := sortableFields[o.Field](a, b)
+	if o.Desc {
+		c = -c
+	}
+	if c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    sortOrder
+		expectError bool
+	}{
+		{
+			name:     "empty uses default",
+			spec:     "",
+			expected: defaultSortOrder,
+		},
+		{
+			name:     "descending",
+			spec:     "created_at:desc",
+			expected: sortOrder{Field: "created_at", Desc: true},
+		},
+		{
+			name:     "direction defaults to ascending",
+			spec:     "title",
+			expected: sortOrder{Field: "title"},
+		},
+		{
+			name:     "field outside the default allow-list",
+			spec:     "author:asc",
+			expected: sortOrder{Field: "author"},
+		},
+		{
+			name:        "unknown field",
+			spec:        "popularity:asc",
+			expectError: true,
+		},
+		{
+			name:        "unknown direction",
+			spec:        "updated_at:sideways",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := parseSort(tt.spec)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if order != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, order)
+			}
+		})
+	}
+}
+
+func TestSortOrder_Apply_TieBreaker(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newer := &domain.Blog{ID: "c", Title: "Newer", CreatedAt: createdAt.Add(time.Second)}
+	tiedA := &domain.Blog{ID: "a", Title: "Tied A", CreatedAt: createdAt}
+	tiedB := &domain.Blog{ID: "b", Title: "Tied B", CreatedAt: createdAt}
+
+	tests := []struct {
+		name     string
+		order    sortOrder
+		expected []string
+	}{
+		{
+			name:     "ascending",
+			order:    sortOrder{Field: "created_at"},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "descending keeps ID tiebreaker ascending",
+			order:    sortOrder{Field: "created_at", Desc: true},
+			expected: []string{"c", "a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 入力順に関わらず同じ結果になることを確認
+			inputs := [][]*domain.Blog{
+				{tiedA, tiedB, newer},
+				{tiedB, newer, tiedA},
+				{newer, tiedB, tiedA},
+			}
+			for _, blogs := range inputs {
+				tt.order.apply(blogs)
+				for i, id := range tt.expected {
+					if blogs[i].ID != id {
+						t.Fatalf("expected order %v, got %s at %d", tt.expected, blogs[i].ID, i)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// streamWriter writes a long-running response that outlives the server WriteTimeout
+// WriteTimeoutはハンドラーの処理時間を含むレスポンス全体に適用されるため、
+// SSEやエクスポートのようなストリーミングではhttp.ResponseControllerで
+// 書き込み期限を書き込みごとに延長する
+type streamWriter struct {
+	rc   *http.ResponseController
+	w    http.ResponseWriter
+	idle time.Duration
+}
+
+// startStream opts the response out of the server WriteTimeout
+// idleは書き込み間の最大待ち時間で、0の場合は書き込み期限を完全に無効化する
+// ストリーミングハンドラーはヘッダー送信前にこれを呼び出す
+func startStream(w http.ResponseWriter, idle time.Duration) (*streamWriter, error) {
+	s := &streamWriter{
+		rc:   http.NewResponseController(w),
+		w:    w,
+		idle: idle,
+	}
+	if err := s.extendDeadline(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write writes p, flushes it to the client and pushes the deadline forward
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if err := s.extendDeadline(); err != nil {
+		return 0, err
+	}
+	n, err := s.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, s.rc.Flush()
+}
+
+func (s *streamWriter) extendDeadline() error {
+	if s.idle <= 0 {
+		return s.rc.SetWriteDeadline(time.Time{})
+	}
+	return s.rc.SetWriteDeadline(time.Now().Add(s.idle))
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/logger"
+)
+
+func TestStartStream_OutlivesWriteTimeout(t *testing.T) {
+	const (
+		chunks   = 5
+		interval = 40 * time.Millisecond
+	)
+
+	streamHandler := func(useStream bool) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var out io.Writer = w
+			if useStream {
+				s, err := startStream(w, time.Second)
+				if err != nil {
+					t.Errorf("startStream: %v", err)
+					return
+				}
+				out = s
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			for i := 0; i < chunks; i++ {
+				if _, err := fmt.Fprintf(out, "chunk %d\n", i); err != nil {
+					return
+				}
+				if !useStream {
+					http.NewResponseController(w).Flush()
+				}
+				time.Sleep(interval)
+			}
+		})
+	}
+
+	tests := []struct {
+		name       string
+		useStream  bool
+		wantChunks bool
+	}{
+		{
+			name:       "stream is not cut off by WriteTimeout",
+			useStream:  true,
+			wantChunks: true,
+		},
+		{
+			name:       "plain handler is cut off by WriteTimeout",
+			useStream:  false,
+			wantChunks: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New(io.Discard, slog.LevelError)
+			srv := httptest.NewUnstartedServer(loggingMiddleware(log)(streamHandler(tt.useStream)))
+			srv.Config.WriteTimeout = 50 * time.Millisecond
+			srv.Start()
+			defer srv.Close()
+
+			resp, err := http.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, readErr := io.ReadAll(resp.Body)
+			complete := readErr == nil && strings.Count(string(body), "chunk") == chunks
+
+			if complete != tt.wantChunks {
+				t.Errorf("expected complete=%v, got %v (body %q, err %v)", tt.wantChunks, complete, body, readErr)
+			}
+		})
+	}
+}
+
+func TestStartStream_UnsupportedWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	// ResponseRecorderは書き込み期限をサポートしないためエラーになる
+	if _, err := startStream(w, 0); err == nil {
+		t.Error("expected error for writer without deadline support")
+	}
+}
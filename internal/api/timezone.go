@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// locationOrError returns the zone for the response of r, answering 400 if ?tz= is invalid
+// falseを返した場合はエラー応答を書き込み済みなので、呼び出し側はそのまま戻る
+func locationOrError(w http.ResponseWriter, r *http.Request, cfg *config.Config) (*time.Location, bool) {
+	loc, err := responseLocation(r, cfg)
+	if err != nil {
+		response := ErrorResponse{
+			Error:    "Invalid tz parameter",
+			Problems: map[string]string{"tz": err.Error()},
+		}
+		encode(w, r, http.StatusBadRequest, response)
+		return nil, false
+	}
+	return loc, true
+}
+
+// responseLocation resolves the time zone timestamps are rendered in
+// ?tz=（IANAタイムゾーン名）がDEFAULT_TIMEZONEより優先される
+// nilの場合は保存されているUTCのまま返す
+func responseLocation(r *http.Request, cfg *config.Config) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return cfg.DefaultTimezone, nil
+	}
+	// "Local"はサーバーの設定に依存するため受け付けない
+	if tz == "Local" {
+		return nil, errors.New("unknown time zone Local")
+	}
+	return time.LoadLocation(tz)
+}
+
+// inZone returns a copy of blog whose timestamps are expressed in loc
+// ストアのデータはUTCのまま変更せず、レスポンス用のコピーだけを変換する
+func inZone(blog *domain.Blog, loc *time.Location) *domain.Blog {
+	if loc == nil {
+		return blog
+	}
+	local := *blog
+	local.CreatedAt = blog.CreatedAt.In(loc)
+	local.UpdatedAt = blog.UpdatedAt.In(loc)
+	return &local
+}
+
+// revisionsInZone returns a copy of revisions whose timestamps are expressed in loc
+func revisionsInZone(revisions []domain.BlogRevision, loc *time.Location) []domain.BlogRevision {
+	if loc == nil {
+		return revisions
+	}
+	local := make([]domain.BlogRevision, len(revisions))
+	for i, rev := range revisions {
+		rev.ChangedAt = rev.ChangedAt.In(loc)
+		local[i] = rev
+	}
+	return local
+}
+
+// inZoneAll converts every blog with inZone
+func inZoneAll(blogs []*domain.Blog, loc *time.Location) []*domain.Blog {
+	if loc == nil {
+		return blogs
+	}
+	local := make([]*domain.Blog, len(blogs))
+	for i, blog := range blogs {
+		local[i] = inZone(blog, loc)
+	}
+	return local
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/config"
+	"github.com/moko-poi/blog-api-server/internal/domain"
+	"github.com/moko-poi/blog-api-server/internal/logger"
+	"github.com/moko-poi/blog-api-server/internal/store"
+)
+
+func TestHandleBlogGet_Timezone(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	createdAt := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	blogStore.Create(context.Background(), &domain.Blog{ID: "tz-blog", Title: "Title", CreatedAt: createdAt, UpdatedAt: createdAt})
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name              string
+		cfg               *config.Config
+		query             string
+		expectedStatus    int
+		expectedCreatedAt string
+	}{
+		{
+			name:              "UTC by default",
+			cfg:               &config.Config{},
+			expectedStatus:    http.StatusOK,
+			expectedCreatedAt: "2024-01-02T15:04:05Z",
+		},
+		{
+			name:              "named zone from query",
+			cfg:               &config.Config{},
+			query:             "?tz=Asia/Tokyo",
+			expectedStatus:    http.StatusOK,
+			expectedCreatedAt: "2024-01-03T00:04:05+09:00",
+		},
+		{
+			name:              "configured default zone",
+			cfg:               &config.Config{DefaultTimezone: tokyo},
+			expectedStatus:    http.StatusOK,
+			expectedCreatedAt: "2024-01-03T00:04:05+09:00",
+		},
+		{
+			name:              "query overrides configured default",
+			cfg:               &config.Config{DefaultTimezone: tokyo},
+			query:             "?tz=UTC",
+			expectedStatus:    http.StatusOK,
+			expectedCreatedAt: "2024-01-02T15:04:05Z",
+		},
+		{
+			name:           "unknown zone",
+			cfg:            &config.Config{},
+			query:          "?tz=Mars/Olympus_Mons",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "server-local zone is rejected",
+			cfg:            &config.Config{},
+			query:          "?tz=Local",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handleBlogsByID(log, tt.cfg, blogStore)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/blogs/tz-blog"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if body["created_at"] != tt.expectedCreatedAt {
+				t.Errorf("expected created_at %q, got %v", tt.expectedCreatedAt, body["created_at"])
+			}
+		})
+	}
+
+	// ストアのデータはUTCのまま
+	stored, _ := blogStore.GetByID(context.Background(), "tz-blog")
+	if stored.CreatedAt.Location() != time.UTC {
+		t.Errorf("expected stored timestamp to stay UTC, got %v", stored.CreatedAt.Location())
+	}
+}
+
+func TestHandleBlogWrites_Timezone(t *testing.T) {
+	log := logger.New(io.Discard, slog.LevelError)
+	blogStore := store.NewMemoryBlogStore()
+	cfg := &config.Config{}
+	create := handleBlogsCreate(log, cfg, blogStore, nil)
+	byID := handleBlogsByID(log, cfg, blogStore)
+
+	if _, err := time.LoadLocation("Asia/Tokyo"); err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	send := func(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	assertTokyo := func(t *testing.T, w *httptest.ResponseRecorder) {
+		t.Helper()
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		createdAt, _ := body["created_at"].(string)
+		if !strings.HasSuffix(createdAt, "+09:00") {
+			t.Errorf("expected created_at in +09:00, got %q", createdAt)
+		}
+	}
+
+	// ?tz= の誤りは作成する前に400となる
+	w := send(create, http.MethodPost, "/api/v1/blogs?tz=Mars/Olympus_Mons", `{"title":"Title","content":"Content","author":"Author"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if blogs, _ := blogStore.GetAll(context.Background()); len(blogs) != 0 {
+		t.Fatalf("expected nothing to be created, got %d blogs", len(blogs))
+	}
+
+	w = send(create, http.MethodPost, "/api/v1/blogs?tz=Asia/Tokyo", `{"id":"tz-write","title":"Title","content":"Content","author":"Author"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	assertTokyo(t, w)
+
+	w = send(byID, http.MethodPut, "/api/v1/blogs/tz-write?tz=Asia/Tokyo", `{"title":"Updated","content":"Content"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	assertTokyo(t, w)
+
+	w = send(byID, http.MethodPut, "/api/v1/blogs/tz-write/tags?tz=Asia/Tokyo", `{"tags":["go"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	assertTokyo(t, w)
+}
@@ -1,12 +1,31 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
 )
 
+// errInvalidGzip is returned when a gzip-encoded request body cannot be decompressed
+var errInvalidGzip = errors.New("invalid gzip body")
+
+// errEmptyBody is returned when the request body is empty or only whitespace
+// 不正なJSONと区別して、クライアントがボディの送り忘れに気付けるようにする
+var errEmptyBody = errors.New("request body is empty")
+
+// errTrailingData is returned when the body continues after the JSON value
+// 末尾のゴミを黙って無視するとクライアント側のバグに気付けないため拒否する
+var errTrailingData = errors.New("unexpected data after JSON value")
+
 // シンプルな単一メソッドのインターフェース
 // 実装が用で、オブジェクト自身がバリデーション責任を持つ
 type Validator interface {
@@ -17,9 +36,53 @@ type Validator interface {
 // ジェネリクスを使用してタイプセーフにレスポンスをエンコード
 // 将来的にXML対応など、別フォーマットが必要になった場合の変更点を最小化
 func encode[T any](w http.ResponseWriter, r *http.Request, status int, v T) error {
+	// 命名規則の変換はドメイン型を変えずにエンコード直前で行う
+	var body any = v
+	// サーバー側の失敗にはリクエストIDを含め、問い合わせの際にログと突き合わせられるようにする
+	if resp, ok := body.(ErrorResponse); ok && status >= http.StatusInternalServerError && resp.RequestID == "" {
+		resp.RequestID = requestIDFromContext(r.Context())
+		body = resp
+	}
+	if fieldCaseFromContext(r.Context()) == fieldCaseCamel {
+		body = camelCaseKeys(body)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
+	if err := newJSONEncoder(r.Context(), w).Encode(body); err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+	return nil
+}
+
+// encodeArray writes items as a JSON array, encoding one element at a time
+// encodeと同じバイト列を出力するが、配列全体をまとめてエンコードしたバッファを作らない
+// ヘッダー送信後のエラーはステータスを変更できないため、呼び出し側はログに残すのみとする
+func encodeArray[T any](w http.ResponseWriter, r *http.Request, status int, items []T) error {
+	camel := fieldCaseFromContext(r.Context()) == fieldCaseCamel
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := io.WriteString(w, "["); err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+	for i, item := range items {
+		var element any = item
+		if camel {
+			element = camelCaseKeys(item)
+		}
+		data, err := marshalJSON(r.Context(), element)
+		if err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+		if i > 0 {
+			data = append([]byte(","), data...)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+	}
+	if _, err := io.WriteString(w, "]\n"); err != nil {
 		return fmt.Errorf("encode json: %w", err)
 	}
 	return nil
@@ -29,8 +92,40 @@ func encode[T any](w http.ResponseWriter, r *http.Request, status int, v T) erro
 // ジェネリクスにより型安全性を確保しつつ、コンパイラが型推論してくれる
 func decode[T any](r *http.Request) (T, error) {
 	var v T
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		return v, fmt.Errorf("decode json: %w", err)
+	body, err := requestBody(r)
+	if err != nil {
+		return v, err
+	}
+	reader := limitFields(r, body)
+	// 構造の上限が設定されている場合は、デコード前にトークンを走査して検査する
+	// 走査で読んだバイトだけを保持し、上限を超えた時点で残りのボディを読まずに拒否する
+	if limits, ok := jsonLimitsFromContext(r.Context()); ok {
+		var scanned bytes.Buffer
+		if err := limits.check(io.TeeReader(reader, &scanned)); err != nil {
+			return v, err
+		}
+		reader = io.MultiReader(&scanned, reader)
+		// UTF-8の検査はフィールド単位で報告するためボディ全体が必要（大きさはMAX_BODY_BYTESで制限される）
+		if limits.RejectInvalidUTF8 {
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return v, decodeError(err)
+			}
+			if problems := invalidUTF8Problems(data); problems != nil {
+				return v, &invalidUTF8Error{Problems: problems}
+			}
+			reader = bytes.NewReader(data)
+		}
+	}
+	dec := json.NewDecoder(reader)
+	if err := dec.Decode(&v); err != nil {
+		return v, decodeError(err)
+	}
+	if err := expectEOF(dec); err != nil {
+		return v, err
+	}
+	if err := verifyBody(body); err != nil {
+		return v, err
 	}
 	return v, nil
 }
@@ -39,21 +134,194 @@ func decode[T any](r *http.Request) (T, error) {
 // Validatorインターフェースを実装する型のみ受け付けるよう型制約
 // バリデーションエラーは別途map[string]stringで返すことで、フィールド単位のエラーメッセージをクライアントに提供可能
 func decodeValid[T Validator](r *http.Request) (T, map[string]string, error) {
-	var v T
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		return v, nil, fmt.Errorf("decode json: %w", err)
+	v, err := decode[T](r)
+	if err != nil {
+		return v, nil, err
 	}
 
 	// バリデーション実行
 	if problems := v.Valid(r.Context()); len(problems) > 0 {
-		return v, problems, fmt.Errorf("invalid %T: %d problems", v, len(problems))
+		return v, domain.TruncateProblems(r.Context(), problems), fmt.Errorf("invalid %T: %d problems", v, len(problems))
 	}
 	return v, nil, nil
 }
 
+// ndjsonRecord is one decoded and validated line of an NDJSON body
+type ndjsonRecord[T any] struct {
+	Line  int
+	Value T
+	// Errは行のJSONが不正な場合、Problemsは検証エラーがある場合に設定される
+	Err      error
+	Problems map[string]string
+}
+
+// tooManyRecordsError is returned when an NDJSON body has more lines than allowed
+type tooManyRecordsError struct {
+	Max int
+}
+
+func (e *tooManyRecordsError) Error() string {
+	return fmt.Sprintf("body has more than %d records", e.Max)
+}
+
+// decodeNDJSON decodes and validates one T per line of an NDJSON body
+// 行ごとの失敗は各レコードに記録し、ボディ全体の読み込みに失敗した場合のみエラーを返す
+// 空行は読み飛ばす。ボディは1行ずつ読み、maxRecords（0は無制限）を超えた時点で残りを読まずに打ち切る
+// 各行にはJSONの構造の上限とフィールドごとの上限を単一のJSONボディと同様に適用する
+func decodeNDJSON[T Validator](r *http.Request, maxRecords int) ([]ndjsonRecord[T], error) {
+	body, err := requestBody(r)
+	if err != nil {
+		return nil, err
+	}
+	limits, _ := jsonLimitsFromContext(r.Context())
+
+	var records []ndjsonRecord[T]
+	br := bufio.NewReader(body)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, decodeError(err)
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			if maxRecords > 0 && len(records) == maxRecords {
+				return nil, &tooManyRecordsError{Max: maxRecords}
+			}
+			records = append(records, decodeNDJSONLine[T](r, limits, lineNo, line))
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if len(records) == 0 {
+		return nil, errEmptyBody
+	}
+	if err := verifyBody(body); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// decodeNDJSONLine decodes and validates one line of an NDJSON body
+func decodeNDJSONLine[T Validator](r *http.Request, limits jsonLimits, lineNo int, line []byte) ndjsonRecord[T] {
+	record := ndjsonRecord[T]{Line: lineNo}
+	if err := limits.check(bytes.NewReader(line)); err != nil {
+		record.Err = err
+		return record
+	}
+	if limits.RejectInvalidUTF8 {
+		if problems := invalidUTF8Problems(line); problems != nil {
+			record.Problems = problems
+			return record
+		}
+	}
+	dec := json.NewDecoder(limitFields(r, bytes.NewReader(line)))
+	if err := dec.Decode(&record.Value); err != nil {
+		record.Err = decodeError(err)
+		return record
+	}
+	if err := expectEOF(dec); err != nil {
+		record.Err = err
+		return record
+	}
+	if problems := record.Value.Valid(r.Context()); len(problems) > 0 {
+		record.Problems = domain.TruncateProblems(r.Context(), problems)
+	}
+	return record
+}
+
+// requestBody returns the request body, transparently decompressing it
+// when the client sent Content-Encoding: gzip
+// 展開後のサイズはMAX_BODY_BYTESで制限する（圧縮率の高いボディでメモリを使い切らないよう）
+func requestBody(r *http.Request) (io.Reader, error) {
+	if !strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		return r.Body, nil
+	}
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidGzip, err)
+	}
+	return &gzipBody{Reader: zr, limit: bodyLimitFromContext(r.Context())}, nil
+}
+
+// decodeError wraps a json.Decoder error
+// 値が始まる前にEOFとなった場合（空または空白のみのボディ）はerrEmptyBodyとする
+// 途中で途切れたJSONはio.ErrUnexpectedEOFとなるため区別できる
+func decodeError(err error) error {
+	if err == io.EOF {
+		return errEmptyBody
+	}
+	return fmt.Errorf("decode json: %w", err)
+}
+
+// expectEOF reports errTrailingData if anything other than whitespace follows the decoded value
+// 読み込み自体の失敗（サイズ超過やgzipの破損など）はそのまま返し、既存のエラー応答に任せる
+func expectEOF(dec *json.Decoder) error {
+	_, err := dec.Token()
+	if err == io.EOF {
+		return nil
+	}
+	var syntaxErr *json.SyntaxError
+	if err == nil || errors.As(err, &syntaxErr) {
+		return errTrailingData
+	}
+	return decodeError(err)
+}
+
+// limitFields applies the per-field limits set by withFieldLimits, if any
+func limitFields(r *http.Request, body io.Reader) io.Reader {
+	if limits := fieldLimitsFromContext(r.Context()); limits != nil {
+		return newFieldLimitReader(body, limits)
+	}
+	return body
+}
+
+// gzipBody marks decompression failures with errInvalidGzip so that a
+// corrupt stream can be told apart from malformed JSON
+// limitを超えて展開されると*http.MaxBytesErrorを返し、圧縮前のボディと同じく413になる
+type gzipBody struct {
+	*gzip.Reader
+	limit int64 // 展開後のサイズの上限（0は無制限）
+	read  int64
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.limit > 0 {
+		if g.read > g.limit {
+			return 0, &http.MaxBytesError{Limit: g.limit}
+		}
+		// 上限を1バイト超えるところまで読み、超過を検出する
+		if remaining := g.limit - g.read + 1; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+	n, err := g.Reader.Read(p)
+	g.read += int64(n)
+	if g.limit > 0 && g.read > g.limit {
+		return n - int(g.read-g.limit), &http.MaxBytesError{Limit: g.limit}
+	}
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %v", errInvalidGzip, err)
+	}
+	return n, err
+}
+
+// verifyBody reads a gzip body to the end so that a truncated stream or
+// checksum mismatch after the JSON value is still reported
+func verifyBody(body io.Reader) error {
+	if _, ok := body.(*gzipBody); !ok {
+		return nil
+	}
+	_, err := io.Copy(io.Discard, body)
+	return err
+}
+
 // 一貫したエラーレスポンス形式を提供
 // Problemsフィールドでフィールドレベルのエラーをクライアントに伝達
 type ErrorResponse struct {
-	Error    string            `json:"error"`
+	Error string `json:"error"`
+	// Code is a stable machine-readable identifier for errors clients may branch on
+	Code     string            `json:"code,omitempty"`
 	Problems map[string]string `json:"problems,omitempty"`
+	// RequestID is filled in by encode for 5xx responses so users can quote it when reporting the failure
+	RequestID string `json:"request_id,omitempty"`
 }
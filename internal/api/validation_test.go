@@ -1,7 +1,10 @@
 package api
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -314,4 +317,74 @@ func TestErrorResponse_NoProblems(t *testing.T) {
 	if strings.Contains(jsonStr, "problems") {
 		t.Error("expected problems field to be omitted when empty")
 	}
-}
\ No newline at end of file
+}
+
+// gzipBytes compresses s for gzip request body tests
+func gzipBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatalf("failed to gzip body: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to gzip body: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeValid_Gzip(t *testing.T) {
+	compressed := gzipBytes(t, `{"title":"Test","content":"Content","author":"Author"}`)
+
+	tests := []struct {
+		name        string
+		body        []byte
+		encoding    string
+		expectGzErr bool
+	}{
+		{
+			name:     "gzip-compressed body",
+			body:     compressed,
+			encoding: "gzip",
+		},
+		{
+			name:     "encoding header is case-insensitive",
+			body:     compressed,
+			encoding: "GZIP",
+		},
+		{
+			name:        "body is not gzip",
+			body:        []byte(`{"title":"Test","content":"Content","author":"Author"}`),
+			encoding:    "gzip",
+			expectGzErr: true,
+		},
+		{
+			name:        "truncated gzip stream",
+			body:        compressed[:len(compressed)-10],
+			encoding:    "gzip",
+			expectGzErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Encoding", tt.encoding)
+
+			result, problems, err := decodeValid[domain.CreateBlogRequest](req)
+
+			if tt.expectGzErr {
+				if !errors.Is(err, errInvalidGzip) {
+					t.Errorf("expected errInvalidGzip, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got: %v (problems %v)", err, problems)
+			}
+			if result.Title != "Test" || result.Content != "Content" || result.Author != "Author" {
+				t.Errorf("unexpected decoded request: %+v", result)
+			}
+		})
+	}
+}
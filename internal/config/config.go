@@ -1,12 +1,22 @@
 package config
 
 import (
+	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"log/slog"
+	"math"
+	"net/http"
+	"net/netip"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// MinAcceptRate is the smallest non-zero MAX_ACCEPT_RATE (one connection per 100 seconds)
+const MinAcceptRate = 0.01
+
 // Config holds the application configuration
 // Following Mat Ryer's pattern of using environment variables for configuration
 type Config struct {
@@ -16,6 +26,232 @@ type Config struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	ReadOnly        bool
+	// LogSource adds the source file and line to each log line
+	LogSource bool
+	// DeduplicateContent rejects a create whose title, content and author
+	// exactly match an existing post
+	DeduplicateContent bool
+	// RejectWhileDraining answers requests arriving during shutdown with
+	// 503 and Connection: close so clients reconnect elsewhere
+	RejectWhileDraining bool
+	// JSONFieldCase selects the response field naming: "snake" (created_at)
+	// or "camel" (createdAt)
+	JSONFieldCase string
+	// RateLimitRPS is the per-client request rate; 0 disables rate limiting
+	RateLimitRPS   float64
+	RateLimitBurst int
+	// AdminToken is the bearer token for admin endpoints; empty disables them
+	AdminToken string
+	// NormalizeContent trims trailing whitespace per line and collapses
+	// excessive blank lines in blog content
+	NormalizeContent bool
+	// StoreBackend selects the storage implementation: memory, file, http, sqlite or postgres
+	StoreBackend string
+	SQLitePath   string
+	DatabaseURL  string
+	// FileStoreDir is the directory the file backend keeps one JSON file per blog in
+	FileStoreDir string
+	// PersistInterval makes the file backend write changes out at most this
+	// often instead of on every mutation (0 = write through)
+	PersistInterval time.Duration
+	// MemoryStoreCapacity caps the number of blogs the memory store holds (0 = unlimited)
+	MemoryStoreCapacity int
+	// RemoteStoreURL is the base URL of the blog-api-server the http backend proxies to
+	RemoteStoreURL string
+	// RemoteStoreTimeout bounds each request the http backend makes
+	RemoteStoreTimeout time.Duration
+	// EncryptionKey enables AES-GCM encryption of blog content at rest when set;
+	// EncryptAuthor also encrypts author names
+	EncryptionKey []byte
+	EncryptAuthor bool
+	// MaxRevisions caps the revision history kept per blog
+	MaxRevisions int
+	// Field length limits enforced by request validation
+	MaxTitleLen   int
+	MaxContentLen int
+	MaxAuthorLen  int
+	// TCPKeepAlive is the keep-alive period for accepted connections;
+	// 0 keeps the Go default
+	TCPKeepAlive time.Duration
+	// MaxAcceptRate caps new TCP connections accepted per second; 0 means unlimited
+	MaxAcceptRate float64
+	// CleanPathRedirect redirects non-canonical paths (//, ., ..) instead of
+	// rewriting them in place
+	CleanPathRedirect bool
+	// DefaultPageSize is the list limit applied when ?limit= is absent;
+	// MaxPageSize is the largest limit a client may request
+	DefaultPageSize int
+	MaxPageSize     int
+	// MaxPageOffset is the largest ?offset= a client may request (0 = unlimited)
+	MaxPageOffset int
+	// StreamIdleTimeout is the longest a streaming response may go without a
+	// write; streaming endpoints are exempt from WriteTimeout. 0 disables it
+	StreamIdleTimeout time.Duration
+	// AuthorAllowlist restricts posting to the listed authors;
+	// AuthorDenylist blocks the listed authors. At most one may be set
+	AuthorAllowlist []string
+	AuthorDenylist  []string
+	// AuthorPostsPerMinute caps how many posts one author may create per
+	// minute; 0 disables the limit
+	AuthorPostsPerMinute int
+	// EmptyResultStatus is the status for an author-filtered listing with
+	// no matches: 200 (empty array) or 404
+	EmptyResultStatus int
+	// MaxBodyBytes caps request body size; larger bodies get 413. 0 disables it
+	MaxBodyBytes int64
+	// AllowedCategories restricts blog categories to the listed values;
+	// empty allows any category
+	AllowedCategories []string
+	// RequireCategory rejects posts without a category
+	RequireCategory bool
+	// DefaultSort is the listing order when ?sort= is absent, e.g.
+	// "created_at:desc"; ties are always broken by ID
+	DefaultSort string
+	// GzipLevel is the response compression level, 1 (fastest) to 9 (smallest)
+	GzipLevel int
+	// DisabledMethods are HTTP methods rejected with 405 for every route
+	DisabledMethods []string
+	// MaxURLLength and MaxQueryParams bound the request URI; requests over
+	// either limit get 414. 0 disables the respective check
+	MaxURLLength   int
+	MaxQueryParams int
+	// ResponseEnvelope wraps single-resource GET responses as {"data": ...}
+	ResponseEnvelope bool
+	// NormalizeAuthor collapses internal whitespace in author names;
+	// AuthorTitleCase additionally title-cases them. Both apply when storing
+	// and when filtering by author
+	NormalizeAuthor bool
+	AuthorTitleCase bool
+	// StrictAccept answers 406 when the Accept header excludes JSON
+	StrictAccept bool
+	// RequestIDHeader is the header the request ID is read from and echoed in
+	RequestIDHeader string
+	// EdgeFieldLimitFactor rejects a title/content/author with 413 while it is
+	// still streaming once it exceeds this multiple of its validation limit (0 = off)
+	EdgeFieldLimitFactor int
+	// DefaultTimezone renders response timestamps in this zone unless ?tz= is
+	// given; nil keeps them in UTC as stored
+	DefaultTimezone *time.Location
+	// MaxConcurrentPerIP caps in-flight requests per client IP; 0 means unlimited
+	MaxConcurrentPerIP int
+	// IdempotencyTTL is how long a response is replayed for a repeated
+	// Idempotency-Key on POST /api/v1/blogs; 0 disables idempotency keys
+	IdempotencyTTL time.Duration
+	// IdempotencyMaxKeys caps the stored Idempotency-Key responses; the oldest
+	// is dropped when full (0 = unlimited)
+	IdempotencyMaxKeys int
+	// CanonicalHost, when set, redirects requests for any other Host to it
+	// (host or host:port, without scheme)
+	CanonicalHost string
+	// ShutdownForceClose closes remaining connections with http.Server.Close
+	// when graceful shutdown does not finish within ShutdownTimeout
+	ShutdownForceClose bool
+	// StrictQueryParams answers 400 for query parameters a listing endpoint
+	// does not understand instead of ignoring them
+	StrictQueryParams bool
+	// MaxTagsPerQuery caps the ?tag= values accepted by the blog list;
+	// TagMatch is "all" (blogs carrying every tag) or "any" (at least one)
+	MaxTagsPerQuery int
+	TagMatch        string
+	// TLSCertFile and TLSKeyFile make the server speak HTTPS; both must be set
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSMinVersion is the oldest TLS version accepted (TLS 1.2 by default)
+	TLSMinVersion uint16
+	// TLSCipherSuites restricts the TLS 1.2 cipher suites; nil keeps Go's
+	// defaults. TLS 1.3 suites are not configurable
+	TLSCipherSuites []uint16
+	// HSTSMaxAge enables the Strict-Transport-Security header with this
+	// max-age (0 = off); HSTSIncludeSubdomains adds includeSubDomains
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	// HTTPSRedirect redirects plain HTTP requests to HTTPS, trusting
+	// X-Forwarded-Proto from a TLS-terminating proxy
+	HTTPSRedirect bool
+	// AuthorDefaultTags maps an author to tags added to each of their new posts
+	AuthorDefaultTags map[string][]string
+	// MaxJSONDepth, MaxJSONArrayLen and MaxJSONTokens bound the nesting,
+	// array length and token count of JSON request bodies (0 = unlimited)
+	MaxJSONDepth    int
+	MaxJSONArrayLen int
+	MaxJSONTokens   int
+	// TrustedProxies lists the peer addresses allowed to raise the log level
+	// of a request with the X-Log-Level header
+	TrustedProxies []netip.Prefix
+	// UniqueSlugs makes the store itself reject duplicate slugs on create and
+	// update, so concurrent creates with the same title get distinct slugs
+	UniqueSlugs bool
+	// HealthCheckInterval is how often the store is pinged in the background
+	// to detect degradation reported by /healthz (0 = disabled)
+	HealthCheckInterval time.Duration
+	// HealthDegradedLatency is the ping latency above which the server reports degraded
+	HealthDegradedLatency time.Duration
+	// DeleteResponseBody answers a successful DELETE with 200 and
+	// {"deleted":true,"id":...} instead of 204 with no body
+	DeleteResponseBody bool
+	// AllowedContentTypes are the request Content-Types POST /api/v1/blogs
+	// accepts: application/json creates one blog, application/x-ndjson one per line
+	AllowedContentTypes []string
+	// NDJSONMaxRecords caps the lines of an application/x-ndjson create; larger
+	// bodies are rejected with 413 (0 = unlimited)
+	NDJSONMaxRecords int
+	// TrimContent removes leading and trailing whitespace from blog content;
+	// disable it to keep significant indentation such as code snippets
+	TrimContent bool
+	// InvalidUTF8 is how request bodies containing malformed UTF-8 are handled:
+	// "reject" answers 400 naming each affected field, "replace" accepts them
+	// with the bad bytes replaced by U+FFFD as encoding/json does
+	InvalidUTF8 string
+	// BlogTTL makes new blogs expire this long after creation unless the
+	// request sets expires_at (0 = never)
+	BlogTTL time.Duration
+	// ExpirySweepInterval is how often expired blogs are deleted from the
+	// store; they are hidden from reads as soon as they expire (0 = never delete)
+	ExpirySweepInterval time.Duration
+	// MaxValidationProblems caps the field problems returned for one invalid
+	// request; the rest are summarized in a note (0 = unlimited)
+	MaxValidationProblems int
+	// RequiredHeaderName and RequiredHeaderValue make the server answer 403 to
+	// requests without that exact header, such as a gateway's shared secret;
+	// both must be set (health checks are exempt)
+	RequiredHeaderName  string
+	RequiredHeaderValue string
+	// ServerTiming adds a Server-Timing header reporting the time spent in
+	// store calls and in the whole handler (exposes timing to clients)
+	ServerTiming bool
+	// IDStrategy is how IDs of new blogs are generated: "uuid" (random) or
+	// "ulid" (sortable by creation time)
+	IDStrategy string
+	// MaxAuthorContentBytes caps the total content size, in bytes, of one
+	// author's blogs; creates and updates beyond it return 507 (0 = unlimited)
+	MaxAuthorContentBytes int
+	// WarnDuplicateTitles adds a warning to the create response when the
+	// author already has a post with the same title (the post is still created)
+	WarnDuplicateTitles bool
+	// RateLimitLogInterval is how often a single summary of the requests
+	// rejected by the rate limiter is logged (0 = never log rejections)
+	RateLimitLogInterval time.Duration
+	// SortableFields are the fields ?sort= may order listings by, e.g.
+	// "created_at,title,author" (empty = created_at, updated_at and title)
+	SortableFields []string
+	// JSONEscapeHTML escapes <, > and & in JSON responses as \u003c etc.;
+	// disable it for clients that render the raw content
+	JSONEscapeHTML bool
+	// AuthorIDHeader is the request header carrying the authenticated subject
+	// set by a gateway, e.g. "X-Auth-Subject"; it becomes the author_id of new
+	// blogs, overriding the request body (empty = take author_id from the body)
+	AuthorIDHeader string
+	// UpdateNotModified answers an update that changes no field (including a
+	// tags update with the same tags) with 304 and no body, without saving it
+	UpdateNotModified bool
+	// SingleflightReads makes concurrent GET /api/v1/blogs/{id} requests for
+	// the same ID share one store call (useful with a networked store)
+	SingleflightReads bool
+	// RouteOptions passes OPTIONS requests to the routes, which answer with an
+	// Allow header listing their methods, instead of a bare 200 from the CORS
+	// middleware
+	RouteOptions bool
 }
 
 // Load creates a new Config from environment variables
@@ -29,6 +265,47 @@ func Load(getenv func(string) string) (*Config, error) {
 		ReadTimeout:     30 * time.Second,
 		WriteTimeout:    30 * time.Second,
 		ShutdownTimeout: 15 * time.Second,
+
+		RejectWhileDraining:   true,
+		JSONFieldCase:         "snake",
+		RateLimitBurst:        10,
+		StoreBackend:          "memory",
+		RequestIDHeader:       "X-Request-ID",
+		EdgeFieldLimitFactor:  4,
+		MaxRevisions:          20,
+		MaxTitleLen:           100,
+		MaxContentLen:         5000,
+		MaxAuthorLen:          50,
+		DefaultPageSize:       20,
+		MaxPageSize:           100,
+		MaxPageOffset:         10000,
+		StreamIdleTimeout:     60 * time.Second,
+		EmptyResultStatus:     200,
+		MaxBodyBytes:          1 << 20,
+		DefaultSort:           "created_at:asc",
+		GzipLevel:             5,
+		MaxURLLength:          4096,
+		MaxQueryParams:        50,
+		IdempotencyTTL:        24 * time.Hour,
+		IdempotencyMaxKeys:    10000,
+		MaxTagsPerQuery:       5,
+		TagMatch:              "all",
+		TLSMinVersion:         tls.VersionTLS12,
+		MaxJSONDepth:          32,
+		MaxJSONArrayLen:       1000,
+		MaxJSONTokens:         100000,
+		UniqueSlugs:           true,
+		HealthDegradedLatency: 500 * time.Millisecond,
+		RemoteStoreTimeout:    10 * time.Second,
+		AllowedContentTypes:   []string{"application/json", "application/x-ndjson"},
+		NDJSONMaxRecords:      1000,
+		TrimContent:           true,
+		InvalidUTF8:           "reject",
+		ExpirySweepInterval:   time.Minute,
+		MaxValidationProblems: 50,
+		IDStrategy:            "uuid",
+		RateLimitLogInterval:  10 * time.Second,
+		JSONEscapeHTML:        true,
 	}
 
 	// Override with environment variables if provided
@@ -52,6 +329,22 @@ func Load(getenv func(string) string) (*Config, error) {
 		cfg.LogLevel = level
 	}
 
+	if logSourceStr := getenv("LOG_SOURCE"); logSourceStr != "" {
+		logSource, err := strconv.ParseBool(logSourceStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LOG_SOURCE: %w", err)
+		}
+		cfg.LogSource = logSource
+	}
+
+	if trustedStr := getenv("TRUSTED_PROXIES"); trustedStr != "" {
+		trusted, err := parseTrustedProxies(trustedStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+		}
+		cfg.TrustedProxies = trusted
+	}
+
 	if readTimeoutStr := getenv("READ_TIMEOUT"); readTimeoutStr != "" {
 		timeout, err := time.ParseDuration(readTimeoutStr)
 		if err != nil {
@@ -76,6 +369,805 @@ func Load(getenv func(string) string) (*Config, error) {
 		cfg.ShutdownTimeout = timeout
 	}
 
+	if readOnlyStr := getenv("READ_ONLY"); readOnlyStr != "" {
+		readOnly, err := strconv.ParseBool(readOnlyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid READ_ONLY: %w", err)
+		}
+		cfg.ReadOnly = readOnly
+	}
+
+	if dedupStr := getenv("DEDUPLICATE_CONTENT"); dedupStr != "" {
+		dedup, err := strconv.ParseBool(dedupStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEDUPLICATE_CONTENT: %w", err)
+		}
+		cfg.DeduplicateContent = dedup
+	}
+
+	if warnStr := getenv("WARN_DUPLICATE_TITLES"); warnStr != "" {
+		warn, err := strconv.ParseBool(warnStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WARN_DUPLICATE_TITLES: %w", err)
+		}
+		cfg.WarnDuplicateTitles = warn
+	}
+
+	if rejectStr := getenv("REJECT_WHILE_DRAINING"); rejectStr != "" {
+		reject, err := strconv.ParseBool(rejectStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REJECT_WHILE_DRAINING: %w", err)
+		}
+		cfg.RejectWhileDraining = reject
+	}
+
+	if fieldCase := getenv("JSON_FIELD_CASE"); fieldCase != "" {
+		switch fieldCase {
+		case "snake", "camel":
+			cfg.JSONFieldCase = fieldCase
+		default:
+			return nil, fmt.Errorf("invalid JSON_FIELD_CASE: unknown case: %s", fieldCase)
+		}
+	}
+
+	if escapeHTMLStr := getenv("JSON_ESCAPE_HTML"); escapeHTMLStr != "" {
+		escapeHTML, err := strconv.ParseBool(escapeHTMLStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON_ESCAPE_HTML: %w", err)
+		}
+		cfg.JSONEscapeHTML = escapeHTML
+	}
+
+	if rpsStr := getenv("RATE_LIMIT_RPS"); rpsStr != "" {
+		rps, err := strconv.ParseFloat(rpsStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RPS: %w", err)
+		}
+		// NaNや無限大ではトークンの補充の計算が成り立たない
+		if math.IsNaN(rps) || math.IsInf(rps, 0) {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RPS: must be a finite number")
+		}
+		if rps < 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RPS: must not be negative")
+		}
+		cfg.RateLimitRPS = rps
+	}
+
+	if burstStr := getenv("RATE_LIMIT_BURST"); burstStr != "" {
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_BURST: %w", err)
+		}
+		if burst < 1 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_BURST: must be at least 1")
+		}
+		cfg.RateLimitBurst = burst
+	}
+
+	if logIntervalStr := getenv("RATE_LIMIT_LOG_INTERVAL"); logIntervalStr != "" {
+		logInterval, err := time.ParseDuration(logIntervalStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_LOG_INTERVAL: %w", err)
+		}
+		if logInterval < 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_LOG_INTERVAL: must not be negative")
+		}
+		cfg.RateLimitLogInterval = logInterval
+	}
+
+	cfg.AdminToken = getenv("ADMIN_TOKEN")
+
+	if normalizeStr := getenv("NORMALIZE_CONTENT"); normalizeStr != "" {
+		normalize, err := strconv.ParseBool(normalizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NORMALIZE_CONTENT: %w", err)
+		}
+		cfg.NormalizeContent = normalize
+	}
+
+	if trimContentStr := getenv("TRIM_CONTENT"); trimContentStr != "" {
+		trimContent, err := strconv.ParseBool(trimContentStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRIM_CONTENT: %w", err)
+		}
+		cfg.TrimContent = trimContent
+	}
+
+	if invalidUTF8 := getenv("INVALID_UTF8"); invalidUTF8 != "" {
+		switch invalidUTF8 {
+		case "reject", "replace":
+			cfg.InvalidUTF8 = invalidUTF8
+		default:
+			return nil, fmt.Errorf("invalid INVALID_UTF8: unknown mode: %s", invalidUTF8)
+		}
+	}
+
+	if idStrategy := getenv("ID_STRATEGY"); idStrategy != "" {
+		switch idStrategy {
+		case "uuid", "ulid":
+			cfg.IDStrategy = idStrategy
+		default:
+			return nil, fmt.Errorf("invalid ID_STRATEGY: unknown strategy: %s", idStrategy)
+		}
+	}
+
+	if ttlStr := getenv("BLOG_TTL"); ttlStr != "" {
+		ttl, err := time.ParseDuration(ttlStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BLOG_TTL: %w", err)
+		}
+		if ttl < 0 {
+			return nil, fmt.Errorf("invalid BLOG_TTL: must not be negative")
+		}
+		cfg.BlogTTL = ttl
+	}
+
+	if sweepStr := getenv("EXPIRY_SWEEP_INTERVAL"); sweepStr != "" {
+		interval, err := time.ParseDuration(sweepStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EXPIRY_SWEEP_INTERVAL: %w", err)
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("invalid EXPIRY_SWEEP_INTERVAL: must not be negative")
+		}
+		cfg.ExpirySweepInterval = interval
+	}
+
+	if backend := getenv("STORE_BACKEND"); backend != "" {
+		switch backend {
+		case "memory", "file", "http", "sqlite", "postgres":
+			cfg.StoreBackend = backend
+		default:
+			return nil, fmt.Errorf("invalid STORE_BACKEND %q: must be memory, file, http, sqlite or postgres", backend)
+		}
+	}
+	if capacityStr := getenv("MEMORY_STORE_CAPACITY"); capacityStr != "" {
+		capacity, err := strconv.Atoi(capacityStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MEMORY_STORE_CAPACITY: %w", err)
+		}
+		if capacity < 0 {
+			return nil, fmt.Errorf("invalid MEMORY_STORE_CAPACITY: must not be negative")
+		}
+		cfg.MemoryStoreCapacity = capacity
+	}
+	if keyStr := getenv("ENCRYPTION_KEY"); keyStr != "" {
+		key, err := base64.StdEncoding.DecodeString(keyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEY: %w", err)
+		}
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEY: must decode to 16, 24 or 32 bytes")
+		}
+		cfg.EncryptionKey = key
+	}
+	if encryptAuthorStr := getenv("ENCRYPT_AUTHOR"); encryptAuthorStr != "" {
+		encryptAuthor, err := strconv.ParseBool(encryptAuthorStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENCRYPT_AUTHOR: %w", err)
+		}
+		if encryptAuthor && cfg.EncryptionKey == nil {
+			return nil, fmt.Errorf("invalid ENCRYPT_AUTHOR: requires ENCRYPTION_KEY")
+		}
+		cfg.EncryptAuthor = encryptAuthor
+	}
+	cfg.SQLitePath = getenv("SQLITE_PATH")
+	cfg.DatabaseURL = getenv("DATABASE_URL")
+	cfg.FileStoreDir = getenv("FILE_STORE_DIR")
+
+	if remoteURL := getenv("REMOTE_STORE_URL"); remoteURL != "" {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REMOTE_STORE_URL: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid REMOTE_STORE_URL: must be an absolute http or https URL")
+		}
+		cfg.RemoteStoreURL = remoteURL
+	}
+
+	if remoteTimeoutStr := getenv("REMOTE_STORE_TIMEOUT"); remoteTimeoutStr != "" {
+		timeout, err := time.ParseDuration(remoteTimeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REMOTE_STORE_TIMEOUT: %w", err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid REMOTE_STORE_TIMEOUT: must be positive")
+		}
+		cfg.RemoteStoreTimeout = timeout
+	}
+
+	if persistIntervalStr := getenv("PERSIST_INTERVAL"); persistIntervalStr != "" {
+		interval, err := time.ParseDuration(persistIntervalStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PERSIST_INTERVAL: %w", err)
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("invalid PERSIST_INTERVAL: must not be negative")
+		}
+		cfg.PersistInterval = interval
+	}
+
+	if healthIntervalStr := getenv("HEALTH_CHECK_INTERVAL"); healthIntervalStr != "" {
+		interval, err := time.ParseDuration(healthIntervalStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HEALTH_CHECK_INTERVAL: %w", err)
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("invalid HEALTH_CHECK_INTERVAL: must not be negative")
+		}
+		cfg.HealthCheckInterval = interval
+	}
+
+	if degradedLatencyStr := getenv("HEALTH_DEGRADED_LATENCY"); degradedLatencyStr != "" {
+		latency, err := time.ParseDuration(degradedLatencyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HEALTH_DEGRADED_LATENCY: %w", err)
+		}
+		if latency < 0 {
+			return nil, fmt.Errorf("invalid HEALTH_DEGRADED_LATENCY: must not be negative")
+		}
+		cfg.HealthDegradedLatency = latency
+	}
+
+	if uniqueSlugsStr := getenv("UNIQUE_SLUGS"); uniqueSlugsStr != "" {
+		uniqueSlugs, err := strconv.ParseBool(uniqueSlugsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UNIQUE_SLUGS: %w", err)
+		}
+		cfg.UniqueSlugs = uniqueSlugs
+	}
+
+	if maxRevisionsStr := getenv("MAX_REVISIONS"); maxRevisionsStr != "" {
+		maxRevisions, err := strconv.Atoi(maxRevisionsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_REVISIONS: %w", err)
+		}
+		if maxRevisions < 0 {
+			return nil, fmt.Errorf("invalid MAX_REVISIONS: must not be negative")
+		}
+		cfg.MaxRevisions = maxRevisions
+	}
+
+	if maxTitleLenStr := getenv("MAX_TITLE_LEN"); maxTitleLenStr != "" {
+		maxTitleLen, err := strconv.Atoi(maxTitleLenStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_TITLE_LEN: %w", err)
+		}
+		if maxTitleLen < 1 {
+			return nil, fmt.Errorf("invalid MAX_TITLE_LEN: must be at least 1")
+		}
+		cfg.MaxTitleLen = maxTitleLen
+	}
+
+	if maxContentLenStr := getenv("MAX_CONTENT_LEN"); maxContentLenStr != "" {
+		maxContentLen, err := strconv.Atoi(maxContentLenStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_CONTENT_LEN: %w", err)
+		}
+		if maxContentLen < 1 {
+			return nil, fmt.Errorf("invalid MAX_CONTENT_LEN: must be at least 1")
+		}
+		cfg.MaxContentLen = maxContentLen
+	}
+
+	if maxAuthorLenStr := getenv("MAX_AUTHOR_LEN"); maxAuthorLenStr != "" {
+		maxAuthorLen, err := strconv.Atoi(maxAuthorLenStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_AUTHOR_LEN: %w", err)
+		}
+		if maxAuthorLen < 1 {
+			return nil, fmt.Errorf("invalid MAX_AUTHOR_LEN: must be at least 1")
+		}
+		cfg.MaxAuthorLen = maxAuthorLen
+	}
+
+	if maxProblemsStr := getenv("MAX_VALIDATION_PROBLEMS"); maxProblemsStr != "" {
+		maxProblems, err := strconv.Atoi(maxProblemsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_VALIDATION_PROBLEMS: %w", err)
+		}
+		if maxProblems < 0 {
+			return nil, fmt.Errorf("invalid MAX_VALIDATION_PROBLEMS: must not be negative")
+		}
+		cfg.MaxValidationProblems = maxProblems
+	}
+
+	if maxAuthorBytesStr := getenv("MAX_AUTHOR_CONTENT_BYTES"); maxAuthorBytesStr != "" {
+		maxAuthorBytes, err := strconv.Atoi(maxAuthorBytesStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_AUTHOR_CONTENT_BYTES: %w", err)
+		}
+		if maxAuthorBytes < 0 {
+			return nil, fmt.Errorf("invalid MAX_AUTHOR_CONTENT_BYTES: must not be negative")
+		}
+		cfg.MaxAuthorContentBytes = maxAuthorBytes
+	}
+
+	if keepAliveStr := getenv("TCP_KEEPALIVE"); keepAliveStr != "" {
+		keepAlive, err := time.ParseDuration(keepAliveStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TCP_KEEPALIVE: %w", err)
+		}
+		if keepAlive < 0 {
+			return nil, fmt.Errorf("invalid TCP_KEEPALIVE: must not be negative")
+		}
+		cfg.TCPKeepAlive = keepAlive
+	}
+
+	if acceptRateStr := getenv("MAX_ACCEPT_RATE"); acceptRateStr != "" {
+		acceptRate, err := strconv.ParseFloat(acceptRateStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_ACCEPT_RATE: %w", err)
+		}
+		// NaNや無限大、受け付け間隔がtime.Durationに収まらないほど小さいレートは拒否する
+		if math.IsNaN(acceptRate) || math.IsInf(acceptRate, 0) {
+			return nil, fmt.Errorf("invalid MAX_ACCEPT_RATE: must be a finite number")
+		}
+		if acceptRate < 0 {
+			return nil, fmt.Errorf("invalid MAX_ACCEPT_RATE: must not be negative")
+		}
+		if acceptRate > 0 && acceptRate < MinAcceptRate {
+			return nil, fmt.Errorf("invalid MAX_ACCEPT_RATE: must be 0 or at least %g", MinAcceptRate)
+		}
+		cfg.MaxAcceptRate = acceptRate
+	}
+
+	if maxConcurrentStr := getenv("MAX_CONCURRENT_PER_IP"); maxConcurrentStr != "" {
+		maxConcurrent, err := strconv.Atoi(maxConcurrentStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_CONCURRENT_PER_IP: %w", err)
+		}
+		if maxConcurrent < 0 {
+			return nil, fmt.Errorf("invalid MAX_CONCURRENT_PER_IP: must not be negative")
+		}
+		cfg.MaxConcurrentPerIP = maxConcurrent
+	}
+
+	if idempotencyTTLStr := getenv("IDEMPOTENCY_TTL"); idempotencyTTLStr != "" {
+		ttl, err := time.ParseDuration(idempotencyTTLStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IDEMPOTENCY_TTL: %w", err)
+		}
+		if ttl < 0 {
+			return nil, fmt.Errorf("invalid IDEMPOTENCY_TTL: must not be negative")
+		}
+		cfg.IdempotencyTTL = ttl
+	}
+
+	if maxKeysStr := getenv("IDEMPOTENCY_MAX_KEYS"); maxKeysStr != "" {
+		maxKeys, err := strconv.Atoi(maxKeysStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IDEMPOTENCY_MAX_KEYS: %w", err)
+		}
+		if maxKeys < 0 {
+			return nil, fmt.Errorf("invalid IDEMPOTENCY_MAX_KEYS: must not be negative")
+		}
+		cfg.IdempotencyMaxKeys = maxKeys
+	}
+
+	if defaultPageSizeStr := getenv("DEFAULT_PAGE_SIZE"); defaultPageSizeStr != "" {
+		defaultPageSize, err := strconv.Atoi(defaultPageSizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEFAULT_PAGE_SIZE: %w", err)
+		}
+		if defaultPageSize < 1 {
+			return nil, fmt.Errorf("invalid DEFAULT_PAGE_SIZE: must be at least 1")
+		}
+		cfg.DefaultPageSize = defaultPageSize
+	}
+
+	if maxPageSizeStr := getenv("MAX_PAGE_SIZE"); maxPageSizeStr != "" {
+		maxPageSize, err := strconv.Atoi(maxPageSizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_PAGE_SIZE: %w", err)
+		}
+		if maxPageSize < 1 {
+			return nil, fmt.Errorf("invalid MAX_PAGE_SIZE: must be at least 1")
+		}
+		cfg.MaxPageSize = maxPageSize
+	}
+
+	if cfg.DefaultPageSize > cfg.MaxPageSize {
+		return nil, fmt.Errorf("invalid DEFAULT_PAGE_SIZE: must not exceed MAX_PAGE_SIZE (%d)", cfg.MaxPageSize)
+	}
+
+	if maxPageOffsetStr := getenv("MAX_PAGE_OFFSET"); maxPageOffsetStr != "" {
+		maxPageOffset, err := strconv.Atoi(maxPageOffsetStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_PAGE_OFFSET: %w", err)
+		}
+		if maxPageOffset < 0 {
+			return nil, fmt.Errorf("invalid MAX_PAGE_OFFSET: must not be negative")
+		}
+		cfg.MaxPageOffset = maxPageOffset
+	}
+
+	if streamIdleStr := getenv("STREAM_IDLE_TIMEOUT"); streamIdleStr != "" {
+		streamIdle, err := time.ParseDuration(streamIdleStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STREAM_IDLE_TIMEOUT: %w", err)
+		}
+		if streamIdle < 0 {
+			return nil, fmt.Errorf("invalid STREAM_IDLE_TIMEOUT: must not be negative")
+		}
+		cfg.StreamIdleTimeout = streamIdle
+	}
+
+	cfg.AuthorAllowlist = splitList(getenv("AUTHOR_ALLOWLIST"))
+	cfg.AuthorDenylist = splitList(getenv("AUTHOR_DENYLIST"))
+	if len(cfg.AuthorAllowlist) > 0 && len(cfg.AuthorDenylist) > 0 {
+		return nil, fmt.Errorf("invalid AUTHOR_ALLOWLIST: cannot be combined with AUTHOR_DENYLIST")
+	}
+
+	if defaultTagsStr := getenv("AUTHOR_DEFAULT_TAGS"); defaultTagsStr != "" {
+		defaultTags, err := parseAuthorTags(defaultTagsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTHOR_DEFAULT_TAGS: %w", err)
+		}
+		cfg.AuthorDefaultTags = defaultTags
+	}
+
+	if postsPerMinuteStr := getenv("AUTHOR_POSTS_PER_MINUTE"); postsPerMinuteStr != "" {
+		postsPerMinute, err := strconv.Atoi(postsPerMinuteStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTHOR_POSTS_PER_MINUTE: %w", err)
+		}
+		if postsPerMinute < 0 {
+			return nil, fmt.Errorf("invalid AUTHOR_POSTS_PER_MINUTE: must not be negative")
+		}
+		cfg.AuthorPostsPerMinute = postsPerMinute
+	}
+
+	if maxTagsStr := getenv("MAX_TAGS_PER_QUERY"); maxTagsStr != "" {
+		maxTags, err := strconv.Atoi(maxTagsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_TAGS_PER_QUERY: %w", err)
+		}
+		if maxTags < 1 {
+			return nil, fmt.Errorf("invalid MAX_TAGS_PER_QUERY: must be at least 1")
+		}
+		cfg.MaxTagsPerQuery = maxTags
+	}
+
+	if tagMatch := getenv("TAG_MATCH"); tagMatch != "" {
+		switch tagMatch {
+		case "all", "any":
+			cfg.TagMatch = tagMatch
+		default:
+			return nil, fmt.Errorf("invalid TAG_MATCH: must be all or any")
+		}
+	}
+
+	if emptyStatusStr := getenv("EMPTY_RESULT_STATUS"); emptyStatusStr != "" {
+		switch emptyStatusStr {
+		case "200":
+			cfg.EmptyResultStatus = 200
+		case "404":
+			cfg.EmptyResultStatus = 404
+		default:
+			return nil, fmt.Errorf("invalid EMPTY_RESULT_STATUS: must be 200 or 404")
+		}
+	}
+
+	if depthStr := getenv("MAX_JSON_DEPTH"); depthStr != "" {
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_JSON_DEPTH: %w", err)
+		}
+		if depth < 0 {
+			return nil, fmt.Errorf("invalid MAX_JSON_DEPTH: must not be negative")
+		}
+		cfg.MaxJSONDepth = depth
+	}
+
+	if arrayLenStr := getenv("MAX_JSON_ARRAY_LEN"); arrayLenStr != "" {
+		arrayLen, err := strconv.Atoi(arrayLenStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_JSON_ARRAY_LEN: %w", err)
+		}
+		if arrayLen < 0 {
+			return nil, fmt.Errorf("invalid MAX_JSON_ARRAY_LEN: must not be negative")
+		}
+		cfg.MaxJSONArrayLen = arrayLen
+	}
+
+	if tokensStr := getenv("MAX_JSON_TOKENS"); tokensStr != "" {
+		tokens, err := strconv.Atoi(tokensStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_JSON_TOKENS: %w", err)
+		}
+		if tokens < 0 {
+			return nil, fmt.Errorf("invalid MAX_JSON_TOKENS: must not be negative")
+		}
+		cfg.MaxJSONTokens = tokens
+	}
+
+	if maxBodyStr := getenv("MAX_BODY_BYTES"); maxBodyStr != "" {
+		maxBody, err := strconv.ParseInt(maxBodyStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_BODY_BYTES: %w", err)
+		}
+		if maxBody < 0 {
+			return nil, fmt.Errorf("invalid MAX_BODY_BYTES: must not be negative")
+		}
+		cfg.MaxBodyBytes = maxBody
+	}
+
+	cfg.AllowedCategories = splitList(getenv("ALLOWED_CATEGORIES"))
+
+	if requireCategoryStr := getenv("REQUIRE_CATEGORY"); requireCategoryStr != "" {
+		requireCategory, err := strconv.ParseBool(requireCategoryStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REQUIRE_CATEGORY: %w", err)
+		}
+		cfg.RequireCategory = requireCategory
+	}
+
+	if defaultSort := getenv("DEFAULT_SORT"); defaultSort != "" {
+		cfg.DefaultSort = defaultSort
+	}
+
+	cfg.SortableFields = splitList(getenv("SORTABLE_FIELDS"))
+
+	if gzipLevelStr := getenv("GZIP_LEVEL"); gzipLevelStr != "" {
+		gzipLevel, err := strconv.Atoi(gzipLevelStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GZIP_LEVEL: %w", err)
+		}
+		if gzipLevel < 1 || gzipLevel > 9 {
+			return nil, fmt.Errorf("invalid GZIP_LEVEL: must be between 1 and 9")
+		}
+		cfg.GzipLevel = gzipLevel
+	}
+
+	for _, method := range splitList(getenv("DISABLED_METHODS")) {
+		method = strings.ToUpper(method)
+		switch method {
+		case "GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS":
+			cfg.DisabledMethods = append(cfg.DisabledMethods, method)
+		default:
+			return nil, fmt.Errorf("invalid DISABLED_METHODS: unknown method: %s", method)
+		}
+	}
+
+	if maxURLLengthStr := getenv("MAX_URL_LENGTH"); maxURLLengthStr != "" {
+		maxURLLength, err := strconv.Atoi(maxURLLengthStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_URL_LENGTH: %w", err)
+		}
+		if maxURLLength < 0 {
+			return nil, fmt.Errorf("invalid MAX_URL_LENGTH: must not be negative")
+		}
+		cfg.MaxURLLength = maxURLLength
+	}
+
+	if maxQueryParamsStr := getenv("MAX_QUERY_PARAMS"); maxQueryParamsStr != "" {
+		maxQueryParams, err := strconv.Atoi(maxQueryParamsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAX_QUERY_PARAMS: %w", err)
+		}
+		if maxQueryParams < 0 {
+			return nil, fmt.Errorf("invalid MAX_QUERY_PARAMS: must not be negative")
+		}
+		cfg.MaxQueryParams = maxQueryParams
+	}
+
+	if envelopeStr := getenv("RESPONSE_ENVELOPE"); envelopeStr != "" {
+		envelope, err := strconv.ParseBool(envelopeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RESPONSE_ENVELOPE: %w", err)
+		}
+		cfg.ResponseEnvelope = envelope
+	}
+
+	if contentTypesStr := getenv("ALLOWED_CONTENT_TYPES"); contentTypesStr != "" {
+		contentTypes := splitList(contentTypesStr)
+		if len(contentTypes) == 0 {
+			return nil, fmt.Errorf("invalid ALLOWED_CONTENT_TYPES: must not be empty")
+		}
+		for _, contentType := range contentTypes {
+			switch contentType {
+			case "application/json", "application/x-ndjson":
+			default:
+				return nil, fmt.Errorf("invalid ALLOWED_CONTENT_TYPES: unsupported content type %q", contentType)
+			}
+		}
+		cfg.AllowedContentTypes = contentTypes
+	}
+
+	if maxRecordsStr := getenv("NDJSON_MAX_RECORDS"); maxRecordsStr != "" {
+		maxRecords, err := strconv.Atoi(maxRecordsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NDJSON_MAX_RECORDS: %w", err)
+		}
+		if maxRecords < 0 {
+			return nil, fmt.Errorf("invalid NDJSON_MAX_RECORDS: must not be negative")
+		}
+		cfg.NDJSONMaxRecords = maxRecords
+	}
+
+	if routeOptionsStr := getenv("ROUTE_OPTIONS"); routeOptionsStr != "" {
+		routeOptions, err := strconv.ParseBool(routeOptionsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ROUTE_OPTIONS: %w", err)
+		}
+		cfg.RouteOptions = routeOptions
+	}
+
+	if singleflightStr := getenv("SINGLEFLIGHT_READS"); singleflightStr != "" {
+		singleflight, err := strconv.ParseBool(singleflightStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SINGLEFLIGHT_READS: %w", err)
+		}
+		cfg.SingleflightReads = singleflight
+	}
+
+	if notModifiedStr := getenv("UPDATE_NOT_MODIFIED"); notModifiedStr != "" {
+		notModified, err := strconv.ParseBool(notModifiedStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UPDATE_NOT_MODIFIED: %w", err)
+		}
+		cfg.UpdateNotModified = notModified
+	}
+
+	if deleteBodyStr := getenv("DELETE_RESPONSE_BODY"); deleteBodyStr != "" {
+		deleteBody, err := strconv.ParseBool(deleteBodyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DELETE_RESPONSE_BODY: %w", err)
+		}
+		cfg.DeleteResponseBody = deleteBody
+	}
+
+	if tz := getenv("DEFAULT_TIMEZONE"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEFAULT_TIMEZONE: %w", err)
+		}
+		cfg.DefaultTimezone = loc
+	}
+
+	if factorStr := getenv("EDGE_FIELD_LIMIT_FACTOR"); factorStr != "" {
+		factor, err := strconv.Atoi(factorStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EDGE_FIELD_LIMIT_FACTOR: %w", err)
+		}
+		if factor < 0 {
+			return nil, fmt.Errorf("invalid EDGE_FIELD_LIMIT_FACTOR: must not be negative")
+		}
+		cfg.EdgeFieldLimitFactor = factor
+	}
+
+	if header := getenv("REQUEST_ID_HEADER"); header != "" {
+		if !validHeaderName(header) {
+			return nil, fmt.Errorf("invalid REQUEST_ID_HEADER: %q is not a valid header name", header)
+		}
+		cfg.RequestIDHeader = http.CanonicalHeaderKey(header)
+	}
+
+	if forceCloseStr := getenv("SHUTDOWN_FORCE_CLOSE"); forceCloseStr != "" {
+		forceClose, err := strconv.ParseBool(forceCloseStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SHUTDOWN_FORCE_CLOSE: %w", err)
+		}
+		cfg.ShutdownForceClose = forceClose
+	}
+
+	if canonicalHost := getenv("CANONICAL_HOST"); canonicalHost != "" {
+		if strings.ContainsAny(canonicalHost, "/?#@ ") {
+			return nil, fmt.Errorf("invalid CANONICAL_HOST: %q must be a host or host:port without scheme or path", canonicalHost)
+		}
+		cfg.CanonicalHost = canonicalHost
+	}
+
+	if hstsMaxAgeStr := getenv("HSTS_MAX_AGE"); hstsMaxAgeStr != "" {
+		maxAge, err := time.ParseDuration(hstsMaxAgeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HSTS_MAX_AGE: %w", err)
+		}
+		if maxAge < 0 {
+			return nil, fmt.Errorf("invalid HSTS_MAX_AGE: must not be negative")
+		}
+		cfg.HSTSMaxAge = maxAge
+	}
+
+	if includeSubdomainsStr := getenv("HSTS_INCLUDE_SUBDOMAINS"); includeSubdomainsStr != "" {
+		includeSubdomains, err := strconv.ParseBool(includeSubdomainsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HSTS_INCLUDE_SUBDOMAINS: %w", err)
+		}
+		cfg.HSTSIncludeSubdomains = includeSubdomains
+	}
+
+	if httpsRedirectStr := getenv("HTTPS_REDIRECT"); httpsRedirectStr != "" {
+		httpsRedirect, err := strconv.ParseBool(httpsRedirectStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTPS_REDIRECT: %w", err)
+		}
+		cfg.HTTPSRedirect = httpsRedirect
+	}
+
+	if strictQueryStr := getenv("STRICT_QUERY_PARAMS"); strictQueryStr != "" {
+		strictQuery, err := strconv.ParseBool(strictQueryStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STRICT_QUERY_PARAMS: %w", err)
+		}
+		cfg.StrictQueryParams = strictQuery
+	}
+
+	if strictAcceptStr := getenv("STRICT_ACCEPT"); strictAcceptStr != "" {
+		strictAccept, err := strconv.ParseBool(strictAcceptStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STRICT_ACCEPT: %w", err)
+		}
+		cfg.StrictAccept = strictAccept
+	}
+
+	if normalizeAuthorStr := getenv("NORMALIZE_AUTHOR"); normalizeAuthorStr != "" {
+		normalizeAuthor, err := strconv.ParseBool(normalizeAuthorStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NORMALIZE_AUTHOR: %w", err)
+		}
+		cfg.NormalizeAuthor = normalizeAuthor
+	}
+
+	if titleCaseStr := getenv("AUTHOR_TITLE_CASE"); titleCaseStr != "" {
+		titleCase, err := strconv.ParseBool(titleCaseStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTHOR_TITLE_CASE: %w", err)
+		}
+		cfg.AuthorTitleCase = titleCase
+	}
+
+	if redirectStr := getenv("CLEAN_PATH_REDIRECT"); redirectStr != "" {
+		redirect, err := strconv.ParseBool(redirectStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLEAN_PATH_REDIRECT: %w", err)
+		}
+		cfg.CleanPathRedirect = redirect
+	}
+
+	cfg.TLSCertFile = getenv("TLS_CERT_FILE")
+	cfg.TLSKeyFile = getenv("TLS_KEY_FILE")
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("invalid TLS_CERT_FILE: TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	cfg.RequiredHeaderName = getenv("REQUIRED_HEADER_NAME")
+	cfg.RequiredHeaderValue = getenv("REQUIRED_HEADER_VALUE")
+	if (cfg.RequiredHeaderName == "") != (cfg.RequiredHeaderValue == "") {
+		return nil, fmt.Errorf("invalid REQUIRED_HEADER_NAME: REQUIRED_HEADER_NAME and REQUIRED_HEADER_VALUE must be set together")
+	}
+
+	cfg.AuthorIDHeader = getenv("AUTHOR_ID_HEADER")
+
+	if serverTimingStr := getenv("SERVER_TIMING"); serverTimingStr != "" {
+		serverTiming, err := strconv.ParseBool(serverTimingStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SERVER_TIMING: %w", err)
+		}
+		cfg.ServerTiming = serverTiming
+	}
+
+	if minVersionStr := getenv("TLS_MIN_VERSION"); minVersionStr != "" {
+		switch minVersionStr {
+		case "1.2":
+			cfg.TLSMinVersion = tls.VersionTLS12
+		case "1.3":
+			cfg.TLSMinVersion = tls.VersionTLS13
+		default:
+			return nil, fmt.Errorf("invalid TLS_MIN_VERSION: must be 1.2 or 1.3")
+		}
+	}
+
+	if cipherSuitesStr := getenv("TLS_CIPHER_SUITES"); cipherSuitesStr != "" {
+		suites, err := parseCipherSuites(cipherSuitesStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS_CIPHER_SUITES: %w", err)
+		}
+		cfg.TLSCipherSuites = suites
+	}
+
 	return cfg, nil
 }
 
@@ -98,4 +1190,103 @@ func parseLogLevel(level string) (slog.Level, error) {
 	default:
 		return slog.LevelInfo, fmt.Errorf("unknown level: %s", level)
 	}
-}
\ No newline at end of file
+}
+
+// ModernCipherSuites is the curated set selected by TLS_CIPHER_SUITES=modern:
+// forward-secret ECDHE key exchange with AEAD ciphers only
+var ModernCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// parseCipherSuites parses "modern" or a comma-separated list of cipher suite names
+// 脆弱とされるスイート（tls.InsecureCipherSuites）は名前が正しくても受け付けない
+func parseCipherSuites(s string) ([]uint16, error) {
+	if s == "modern" {
+		return ModernCipherSuites, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	var suites []uint16
+	for _, name := range splitList(s) {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		suites = append(suites, id)
+	}
+	if len(suites) == 0 {
+		return nil, fmt.Errorf("no cipher suites listed")
+	}
+	return suites, nil
+}
+
+// parseAuthorTags parses "alice=go,api;bob=web" into a map of author to tags
+// タグ自体の妥当性はdomainのルールに従うため、NewServerで検証する
+func parseAuthorTags(s string) (map[string][]string, error) {
+	authorTags := make(map[string][]string)
+	for _, entry := range strings.Split(s, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		author, tags, ok := strings.Cut(entry, "=")
+		author = strings.TrimSpace(author)
+		if !ok || author == "" {
+			return nil, fmt.Errorf("%q must be author=tag,tag", entry)
+		}
+		if _, dup := authorTags[author]; dup {
+			return nil, fmt.Errorf("author %q is listed more than once", author)
+		}
+		authorTags[author] = splitList(tags)
+	}
+	return authorTags, nil
+}
+
+// splitList parses a comma-separated list, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validHeaderName reports whether name consists only of letters, digits and hyphens
+func validHeaderName(name string) bool {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// parseTrustedProxies parses a comma-separated list of CIDRs or single IP addresses
+// 単一のアドレスはそのアドレスだけを含むプレフィックスとして扱う
+func parseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range splitList(s) {
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,516 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// envGetter returns a getenv function backed by a map
+func envGetter(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load(envGetter(nil))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.Address() != "localhost:8080" {
+		t.Errorf("expected address 'localhost:8080', got %q", cfg.Address())
+	}
+	if cfg.MaxTitleLen != 100 {
+		t.Errorf("expected MaxTitleLen 100, got %d", cfg.MaxTitleLen)
+	}
+	if cfg.MaxContentLen != 5000 {
+		t.Errorf("expected MaxContentLen 5000, got %d", cfg.MaxContentLen)
+	}
+	if cfg.MaxAuthorLen != 50 {
+		t.Errorf("expected MaxAuthorLen 50, got %d", cfg.MaxAuthorLen)
+	}
+	if cfg.DefaultPageSize != 20 {
+		t.Errorf("expected DefaultPageSize 20, got %d", cfg.DefaultPageSize)
+	}
+	if cfg.MaxPageSize != 100 {
+		t.Errorf("expected MaxPageSize 100, got %d", cfg.MaxPageSize)
+	}
+	if cfg.EmptyResultStatus != 200 {
+		t.Errorf("expected EmptyResultStatus 200, got %d", cfg.EmptyResultStatus)
+	}
+}
+
+func TestLoad_FieldLimits(t *testing.T) {
+	cfg, err := Load(envGetter(map[string]string{
+		"MAX_TITLE_LEN":   "200",
+		"MAX_CONTENT_LEN": "10000",
+		"MAX_AUTHOR_LEN":  "80",
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.MaxTitleLen != 200 {
+		t.Errorf("expected MaxTitleLen 200, got %d", cfg.MaxTitleLen)
+	}
+	if cfg.MaxContentLen != 10000 {
+		t.Errorf("expected MaxContentLen 10000, got %d", cfg.MaxContentLen)
+	}
+	if cfg.MaxAuthorLen != 80 {
+		t.Errorf("expected MaxAuthorLen 80, got %d", cfg.MaxAuthorLen)
+	}
+}
+
+func TestLoad_RequestIDHeader(t *testing.T) {
+	cfg, err := Load(envGetter(map[string]string{}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.RequestIDHeader != "X-Request-ID" {
+		t.Errorf("expected default X-Request-ID, got %q", cfg.RequestIDHeader)
+	}
+
+	cfg, err = Load(envGetter(map[string]string{"REQUEST_ID_HEADER": "x-correlation-id"}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.RequestIDHeader != "X-Correlation-Id" {
+		t.Errorf("expected canonical X-Correlation-Id, got %q", cfg.RequestIDHeader)
+	}
+}
+
+func TestLoad_AuthorLists(t *testing.T) {
+	cfg, err := Load(envGetter(map[string]string{
+		"AUTHOR_ALLOWLIST": " alice, bob ,,",
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(cfg.AuthorAllowlist) != 2 || cfg.AuthorAllowlist[0] != "alice" || cfg.AuthorAllowlist[1] != "bob" {
+		t.Errorf("expected allowlist [alice bob], got %v", cfg.AuthorAllowlist)
+	}
+	if cfg.AuthorDenylist != nil {
+		t.Errorf("expected empty denylist, got %v", cfg.AuthorDenylist)
+	}
+}
+
+func TestLoad_AuthorDefaultTags(t *testing.T) {
+	cfg, err := Load(envGetter(map[string]string{
+		"AUTHOR_DEFAULT_TAGS": " alice = go, api ; bob=web;",
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string][]string{"alice": {"go", "api"}, "bob": {"web"}}
+	if !maps.EqualFunc(cfg.AuthorDefaultTags, want, slices.Equal) {
+		t.Errorf("expected %v, got %v", want, cfg.AuthorDefaultTags)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "invalid PORT",
+			env:     map[string]string{"PORT": "abc"},
+			wantErr: "invalid PORT",
+		},
+		{
+			name:    "non-numeric MAX_TITLE_LEN",
+			env:     map[string]string{"MAX_TITLE_LEN": "long"},
+			wantErr: "invalid MAX_TITLE_LEN",
+		},
+		{
+			name:    "zero MAX_CONTENT_LEN",
+			env:     map[string]string{"MAX_CONTENT_LEN": "0"},
+			wantErr: "invalid MAX_CONTENT_LEN",
+		},
+		{
+			name:    "negative MAX_AUTHOR_LEN",
+			env:     map[string]string{"MAX_AUTHOR_LEN": "-1"},
+			wantErr: "invalid MAX_AUTHOR_LEN",
+		},
+		{
+			name:    "zero MAX_PAGE_SIZE",
+			env:     map[string]string{"MAX_PAGE_SIZE": "0"},
+			wantErr: "invalid MAX_PAGE_SIZE",
+		},
+		{
+			name:    "negative MAX_PAGE_OFFSET",
+			env:     map[string]string{"MAX_PAGE_OFFSET": "-1"},
+			wantErr: "invalid MAX_PAGE_OFFSET",
+		},
+		{
+			name:    "DEFAULT_PAGE_SIZE above MAX_PAGE_SIZE",
+			env:     map[string]string{"DEFAULT_PAGE_SIZE": "50", "MAX_PAGE_SIZE": "10"},
+			wantErr: "invalid DEFAULT_PAGE_SIZE",
+		},
+		{
+			name:    "negative STREAM_IDLE_TIMEOUT",
+			env:     map[string]string{"STREAM_IDLE_TIMEOUT": "-1s"},
+			wantErr: "invalid STREAM_IDLE_TIMEOUT",
+		},
+		{
+			name:    "both AUTHOR_ALLOWLIST and AUTHOR_DENYLIST",
+			env:     map[string]string{"AUTHOR_ALLOWLIST": "alice", "AUTHOR_DENYLIST": "bob"},
+			wantErr: "invalid AUTHOR_ALLOWLIST",
+		},
+		{
+			name:    "negative AUTHOR_POSTS_PER_MINUTE",
+			env:     map[string]string{"AUTHOR_POSTS_PER_MINUTE": "-5"},
+			wantErr: "invalid AUTHOR_POSTS_PER_MINUTE",
+		},
+		{
+			name:    "unsupported EMPTY_RESULT_STATUS",
+			env:     map[string]string{"EMPTY_RESULT_STATUS": "204"},
+			wantErr: "invalid EMPTY_RESULT_STATUS",
+		},
+		{
+			name:    "non-numeric MAX_BODY_BYTES",
+			env:     map[string]string{"MAX_BODY_BYTES": "1MB"},
+			wantErr: "invalid MAX_BODY_BYTES",
+		},
+		{
+			name:    "negative HEALTH_CHECK_INTERVAL",
+			env:     map[string]string{"HEALTH_CHECK_INTERVAL": "-1s"},
+			wantErr: "invalid HEALTH_CHECK_INTERVAL",
+		},
+		{
+			name:    "non-duration HEALTH_DEGRADED_LATENCY",
+			env:     map[string]string{"HEALTH_DEGRADED_LATENCY": "fast"},
+			wantErr: "invalid HEALTH_DEGRADED_LATENCY",
+		},
+		{
+			name:    "unsupported ALLOWED_CONTENT_TYPES",
+			env:     map[string]string{"ALLOWED_CONTENT_TYPES": "application/json,text/csv"},
+			wantErr: "invalid ALLOWED_CONTENT_TYPES",
+		},
+		{
+			name:    "non-boolean TRIM_CONTENT",
+			env:     map[string]string{"TRIM_CONTENT": "maybe"},
+			wantErr: "invalid TRIM_CONTENT",
+		},
+		{
+			name:    "relative REMOTE_STORE_URL",
+			env:     map[string]string{"REMOTE_STORE_URL": "blog.internal:8080"},
+			wantErr: "invalid REMOTE_STORE_URL",
+		},
+		{
+			name:    "zero REMOTE_STORE_TIMEOUT",
+			env:     map[string]string{"REMOTE_STORE_TIMEOUT": "0s"},
+			wantErr: "invalid REMOTE_STORE_TIMEOUT",
+		},
+		{
+			name:    "negative MAX_VALIDATION_PROBLEMS",
+			env:     map[string]string{"MAX_VALIDATION_PROBLEMS": "-1"},
+			wantErr: "invalid MAX_VALIDATION_PROBLEMS",
+		},
+		{
+			name:    "negative BLOG_TTL",
+			env:     map[string]string{"BLOG_TTL": "-1h"},
+			wantErr: "invalid BLOG_TTL",
+		},
+		{
+			name:    "malformed EXPIRY_SWEEP_INTERVAL",
+			env:     map[string]string{"EXPIRY_SWEEP_INTERVAL": "often"},
+			wantErr: "invalid EXPIRY_SWEEP_INTERVAL",
+		},
+		{
+			name:    "unknown INVALID_UTF8",
+			env:     map[string]string{"INVALID_UTF8": "ignore"},
+			wantErr: "invalid INVALID_UTF8",
+		},
+		{
+			name:    "non-boolean DELETE_RESPONSE_BODY",
+			env:     map[string]string{"DELETE_RESPONSE_BODY": "yes please"},
+			wantErr: "invalid DELETE_RESPONSE_BODY",
+		},
+		{
+			name:    "non-boolean UNIQUE_SLUGS",
+			env:     map[string]string{"UNIQUE_SLUGS": "sometimes"},
+			wantErr: "invalid UNIQUE_SLUGS",
+		},
+		{
+			name:    "malformed TRUSTED_PROXIES",
+			env:     map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,not-an-ip"},
+			wantErr: "invalid TRUSTED_PROXIES",
+		},
+		{
+			name:    "negative MAX_JSON_ARRAY_LEN",
+			env:     map[string]string{"MAX_JSON_ARRAY_LEN": "-1"},
+			wantErr: "invalid MAX_JSON_ARRAY_LEN",
+		},
+		{
+			name:    "non-numeric MAX_JSON_DEPTH",
+			env:     map[string]string{"MAX_JSON_DEPTH": "deep"},
+			wantErr: "invalid MAX_JSON_DEPTH",
+		},
+		{
+			name:    "GZIP_LEVEL out of range",
+			env:     map[string]string{"GZIP_LEVEL": "10"},
+			wantErr: "invalid GZIP_LEVEL",
+		},
+		{
+			name:    "NaN RATE_LIMIT_RPS",
+			env:     map[string]string{"RATE_LIMIT_RPS": "NaN"},
+			wantErr: "invalid RATE_LIMIT_RPS",
+		},
+		{
+			name:    "infinite RATE_LIMIT_RPS",
+			env:     map[string]string{"RATE_LIMIT_RPS": "Inf"},
+			wantErr: "invalid RATE_LIMIT_RPS",
+		},
+		{
+			name:    "negative MAX_ACCEPT_RATE",
+			env:     map[string]string{"MAX_ACCEPT_RATE": "-5"},
+			wantErr: "invalid MAX_ACCEPT_RATE",
+		},
+		{
+			name:    "NaN MAX_ACCEPT_RATE",
+			env:     map[string]string{"MAX_ACCEPT_RATE": "NaN"},
+			wantErr: "invalid MAX_ACCEPT_RATE",
+		},
+		{
+			name:    "infinite MAX_ACCEPT_RATE",
+			env:     map[string]string{"MAX_ACCEPT_RATE": "+Inf"},
+			wantErr: "invalid MAX_ACCEPT_RATE",
+		},
+		{
+			name:    "MAX_ACCEPT_RATE below minimum",
+			env:     map[string]string{"MAX_ACCEPT_RATE": "1e-12"},
+			wantErr: "invalid MAX_ACCEPT_RATE",
+		},
+		{
+			name:    "negative MAX_CONCURRENT_PER_IP",
+			env:     map[string]string{"MAX_CONCURRENT_PER_IP": "-1"},
+			wantErr: "invalid MAX_CONCURRENT_PER_IP",
+		},
+		{
+			name:    "negative IDEMPOTENCY_TTL",
+			env:     map[string]string{"IDEMPOTENCY_TTL": "-1h"},
+			wantErr: "invalid IDEMPOTENCY_TTL",
+		},
+		{
+			name:    "negative IDEMPOTENCY_MAX_KEYS",
+			env:     map[string]string{"IDEMPOTENCY_MAX_KEYS": "-1"},
+			wantErr: "invalid IDEMPOTENCY_MAX_KEYS",
+		},
+		{
+			name:    "negative NDJSON_MAX_RECORDS",
+			env:     map[string]string{"NDJSON_MAX_RECORDS": "-1"},
+			wantErr: "invalid NDJSON_MAX_RECORDS",
+		},
+		{
+			name:    "invalid SHUTDOWN_FORCE_CLOSE",
+			env:     map[string]string{"SHUTDOWN_FORCE_CLOSE": "sometimes"},
+			wantErr: "invalid SHUTDOWN_FORCE_CLOSE",
+		},
+		{
+			name:    "CANONICAL_HOST with scheme",
+			env:     map[string]string{"CANONICAL_HOST": "https://example.com"},
+			wantErr: "invalid CANONICAL_HOST",
+		},
+		{
+			name:    "unknown DEFAULT_TIMEZONE",
+			env:     map[string]string{"DEFAULT_TIMEZONE": "Mars/Olympus_Mons"},
+			wantErr: "invalid DEFAULT_TIMEZONE",
+		},
+		{
+			name:    "negative EDGE_FIELD_LIMIT_FACTOR",
+			env:     map[string]string{"EDGE_FIELD_LIMIT_FACTOR": "-2"},
+			wantErr: "invalid EDGE_FIELD_LIMIT_FACTOR",
+		},
+		{
+			name:    "REQUEST_ID_HEADER with invalid characters",
+			env:     map[string]string{"REQUEST_ID_HEADER": "X Request: ID"},
+			wantErr: "invalid REQUEST_ID_HEADER",
+		},
+		{
+			name:    "TLS_CERT_FILE without TLS_KEY_FILE",
+			env:     map[string]string{"TLS_CERT_FILE": "cert.pem"},
+			wantErr: "must be set together",
+		},
+		{
+			name:    "REQUIRED_HEADER_NAME without REQUIRED_HEADER_VALUE",
+			env:     map[string]string{"REQUIRED_HEADER_NAME": "X-Gateway-Auth"},
+			wantErr: "invalid REQUIRED_HEADER_NAME",
+		},
+		{
+			name:    "non-boolean ROUTE_OPTIONS",
+			env:     map[string]string{"ROUTE_OPTIONS": "all"},
+			wantErr: "invalid ROUTE_OPTIONS",
+		},
+		{
+			name:    "non-boolean SINGLEFLIGHT_READS",
+			env:     map[string]string{"SINGLEFLIGHT_READS": "often"},
+			wantErr: "invalid SINGLEFLIGHT_READS",
+		},
+		{
+			name:    "non-boolean UPDATE_NOT_MODIFIED",
+			env:     map[string]string{"UPDATE_NOT_MODIFIED": "maybe"},
+			wantErr: "invalid UPDATE_NOT_MODIFIED",
+		},
+		{
+			name:    "invalid JSON_ESCAPE_HTML",
+			env:     map[string]string{"JSON_ESCAPE_HTML": "sometimes"},
+			wantErr: "invalid JSON_ESCAPE_HTML",
+		},
+		{
+			name:    "negative RATE_LIMIT_LOG_INTERVAL",
+			env:     map[string]string{"RATE_LIMIT_LOG_INTERVAL": "-1s"},
+			wantErr: "invalid RATE_LIMIT_LOG_INTERVAL",
+		},
+		{
+			name:    "invalid WARN_DUPLICATE_TITLES",
+			env:     map[string]string{"WARN_DUPLICATE_TITLES": "maybe"},
+			wantErr: "invalid WARN_DUPLICATE_TITLES",
+		},
+		{
+			name:    "negative MAX_AUTHOR_CONTENT_BYTES",
+			env:     map[string]string{"MAX_AUTHOR_CONTENT_BYTES": "-1"},
+			wantErr: "invalid MAX_AUTHOR_CONTENT_BYTES",
+		},
+		{
+			name:    "unknown ID_STRATEGY",
+			env:     map[string]string{"ID_STRATEGY": "snowflake"},
+			wantErr: "invalid ID_STRATEGY",
+		},
+		{
+			name:    "invalid SERVER_TIMING",
+			env:     map[string]string{"SERVER_TIMING": "sometimes"},
+			wantErr: "invalid SERVER_TIMING",
+		},
+		{
+			name:    "unsupported TLS_MIN_VERSION",
+			env:     map[string]string{"TLS_MIN_VERSION": "1.0"},
+			wantErr: "invalid TLS_MIN_VERSION",
+		},
+		{
+			name:    "unknown TLS_CIPHER_SUITES",
+			env:     map[string]string{"TLS_CIPHER_SUITES": "TLS_MADE_UP_SUITE"},
+			wantErr: "invalid TLS_CIPHER_SUITES",
+		},
+		{
+			name:    "insecure TLS_CIPHER_SUITES",
+			env:     map[string]string{"TLS_CIPHER_SUITES": "TLS_RSA_WITH_RC4_128_SHA"},
+			wantErr: "invalid TLS_CIPHER_SUITES",
+		},
+		{
+			name:    "AUTHOR_DEFAULT_TAGS without tags",
+			env:     map[string]string{"AUTHOR_DEFAULT_TAGS": "alice"},
+			wantErr: "invalid AUTHOR_DEFAULT_TAGS",
+		},
+		{
+			name:    "AUTHOR_DEFAULT_TAGS with duplicate author",
+			env:     map[string]string{"AUTHOR_DEFAULT_TAGS": "alice=go;alice=api"},
+			wantErr: "invalid AUTHOR_DEFAULT_TAGS",
+		},
+		{
+			name:    "invalid PERSIST_INTERVAL",
+			env:     map[string]string{"PERSIST_INTERVAL": "often"},
+			wantErr: "invalid PERSIST_INTERVAL",
+		},
+		{
+			name:    "negative PERSIST_INTERVAL",
+			env:     map[string]string{"PERSIST_INTERVAL": "-1s"},
+			wantErr: "invalid PERSIST_INTERVAL",
+		},
+		{
+			name:    "zero MAX_TAGS_PER_QUERY",
+			env:     map[string]string{"MAX_TAGS_PER_QUERY": "0"},
+			wantErr: "invalid MAX_TAGS_PER_QUERY",
+		},
+		{
+			name:    "negative MAX_REVISIONS",
+			env:     map[string]string{"MAX_REVISIONS": "-1"},
+			wantErr: "invalid MAX_REVISIONS",
+		},
+		{
+			name:    "unknown STORE_BACKEND",
+			env:     map[string]string{"STORE_BACKEND": "mongo"},
+			wantErr: `invalid STORE_BACKEND "mongo"`,
+		},
+		{
+			name:    "unknown TAG_MATCH",
+			env:     map[string]string{"TAG_MATCH": "some"},
+			wantErr: "invalid TAG_MATCH",
+		},
+		{
+			name:    "invalid HSTS_MAX_AGE",
+			env:     map[string]string{"HSTS_MAX_AGE": "1 year"},
+			wantErr: "invalid HSTS_MAX_AGE",
+		},
+		{
+			name:    "non-boolean HSTS_INCLUDE_SUBDOMAINS",
+			env:     map[string]string{"HSTS_INCLUDE_SUBDOMAINS": "all"},
+			wantErr: "invalid HSTS_INCLUDE_SUBDOMAINS",
+		},
+		{
+			name:    "non-boolean HTTPS_REDIRECT",
+			env:     map[string]string{"HTTPS_REDIRECT": "always"},
+			wantErr: "invalid HTTPS_REDIRECT",
+		},
+		{
+			name:    "non-boolean STRICT_QUERY_PARAMS",
+			env:     map[string]string{"STRICT_QUERY_PARAMS": "yes please"},
+			wantErr: "invalid STRICT_QUERY_PARAMS",
+		},
+		{
+			name:    "non-boolean STRICT_ACCEPT",
+			env:     map[string]string{"STRICT_ACCEPT": "maybe"},
+			wantErr: "invalid STRICT_ACCEPT",
+		},
+		{
+			name:    "non-boolean LOG_SOURCE",
+			env:     map[string]string{"LOG_SOURCE": "verbose"},
+			wantErr: "invalid LOG_SOURCE",
+		},
+		{
+			name:    "ENCRYPTION_KEY of the wrong length",
+			env:     map[string]string{"ENCRYPTION_KEY": "c2hvcnQ="},
+			wantErr: "invalid ENCRYPTION_KEY",
+		},
+		{
+			name:    "ENCRYPT_AUTHOR without ENCRYPTION_KEY",
+			env:     map[string]string{"ENCRYPT_AUTHOR": "true"},
+			wantErr: "requires ENCRYPTION_KEY",
+		},
+		{
+			name:    "negative MEMORY_STORE_CAPACITY",
+			env:     map[string]string{"MEMORY_STORE_CAPACITY": "-1"},
+			wantErr: "invalid MEMORY_STORE_CAPACITY",
+		},
+		{
+			name:    "non-boolean NORMALIZE_AUTHOR",
+			env:     map[string]string{"NORMALIZE_AUTHOR": "sometimes"},
+			wantErr: "invalid NORMALIZE_AUTHOR",
+		},
+		{
+			name:    "unknown DISABLED_METHODS entry",
+			env:     map[string]string{"DISABLED_METHODS": "DELETE,PURGE"},
+			wantErr: "invalid DISABLED_METHODS",
+		},
+		{
+			name:    "negative MAX_QUERY_PARAMS",
+			env:     map[string]string{"MAX_QUERY_PARAMS": "-1"},
+			wantErr: "invalid MAX_QUERY_PARAMS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(envGetter(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
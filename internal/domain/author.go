@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AuthorNormalization describes how author names are canonicalized
+// "John   Doe" と " john doe" を同じ作者として扱うため、
+// 保存時と作者での検索時の両方に同じ正規化を適用する
+type AuthorNormalization struct {
+	// CollapseSpace replaces internal runs of whitespace with a single space
+	CollapseSpace bool
+	// TitleCase upper-cases the first letter of each word and lower-cases the rest
+	TitleCase bool
+}
+
+// Apply returns the canonical form of author
+// 前後の空白は設定に関わらず常に除去する
+func (n AuthorNormalization) Apply(author string) string {
+	author = strings.TrimSpace(author)
+	if n.CollapseSpace {
+		author = strings.Join(strings.Fields(author), " ")
+	}
+	if n.TitleCase {
+		author = titleCase(author)
+	}
+	return author
+}
+
+// titleCase upper-cases the first letter of each space-separated word
+func titleCase(s string) string {
+	words := strings.Split(s, " ")
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		if size == 0 {
+			continue
+		}
+		words[i] = string(unicode.ToUpper(first)) + strings.ToLower(word[size:])
+	}
+	return strings.Join(words, " ")
+}
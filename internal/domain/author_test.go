@@ -0,0 +1,59 @@
+package domain
+
+import "testing"
+
+func TestAuthorNormalization_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		norm     AuthorNormalization
+		author   string
+		expected string
+	}{
+		{
+			name:     "disabled only trims",
+			norm:     AuthorNormalization{},
+			author:   "  John   Doe ",
+			expected: "John   Doe",
+		},
+		{
+			name:     "collapse internal whitespace",
+			norm:     AuthorNormalization{CollapseSpace: true},
+			author:   " John \t  Doe ",
+			expected: "John Doe",
+		},
+		{
+			name:     "title case",
+			norm:     AuthorNormalization{CollapseSpace: true, TitleCase: true},
+			author:   " john   DOE",
+			expected: "John Doe",
+		},
+		{
+			name:     "non-ASCII names are left intact",
+			norm:     AuthorNormalization{CollapseSpace: true, TitleCase: true},
+			author:   "山田　太郎",
+			expected: "山田 太郎",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.norm.Apply(tt.author); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewBlog_AuthorNormalization(t *testing.T) {
+	req := CreateBlogRequest{Title: "Title", Content: "Content", Author: "  john   doe "}
+
+	blog := NewBlog(req)
+	if blog.Author != "john   doe" {
+		t.Errorf("expected only trimming by default, got %q", blog.Author)
+	}
+
+	blog = NewBlog(req, WithAuthorNormalization(AuthorNormalization{CollapseSpace: true, TitleCase: true}))
+	if blog.Author != "John Doe" {
+		t.Errorf("expected normalized author 'John Doe', got %q", blog.Author)
+	}
+}
@@ -2,10 +2,14 @@ package domain
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
-
-	"github.com/google/uuid"
+	"unicode"
 )
 
 // Blog represents a blog post
@@ -14,19 +18,66 @@ import (
 type Blog struct {
 	ID        string    `json:"id"`
 	Title     string    `json:"title"`
+	Slug      string    `json:"slug,omitempty"`
 	Content   string    `json:"content"`
 	Author    string    `json:"author"`
+	Category  string    `json:"category,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// AuthorID is the author's stable identity; Author is only the display
+	// name and may change when the author renames
+	AuthorID string `json:"author_id,omitempty"`
+	// ContentHash identifies duplicate posts and is kept out of API responses
+	// (ストアは永続化の際に別途保存する)
+	ContentHash string `json:"-"`
+	// ExpiresAt is when the blog stops being served; nil means it never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Revisions is the change history, oldest first, capped by WithMaxRevisions
+	// (レスポンスには含めず、/revisionsでのみ返す。ストアは永続化の際に別途保存する)
+	Revisions []BlogRevision `json:"-"`
+}
+
+// Expired reports whether the blog has passed its ExpiresAt at now
+func (b *Blog) Expired(now time.Time) bool {
+	return b.ExpiresAt != nil && !now.Before(*b.ExpiresAt)
+}
+
+// BlogRevision records which fields an update changed, when, and by whom
+type BlogRevision struct {
+	ChangedFields []string  `json:"changed_fields"`
+	ChangedAt     time.Time `json:"changed_at"`
+	Actor         string    `json:"actor,omitempty"`
+}
+
+// BlogStats represents aggregate statistics across all blogs
+// 管理ダッシュボード向けの集計値
+// 投稿が存在しない場合、最新/最古の投稿日時はnilとなる
+type BlogStats struct {
+	TotalBlogs           int            `json:"total_blogs"`
+	BlogsPerAuthor       map[string]int `json:"blogs_per_author"`
+	AverageContentLength float64        `json:"average_content_length"`
+	NewestPostAt         *time.Time     `json:"newest_post_at,omitempty"`
+	OldestPostAt         *time.Time     `json:"oldest_post_at,omitempty"`
 }
 
 // CreateBlogRequest represents a request to create a new blog
 // Mat Ryerのパターン: リクエスト/レスポンス型をハンドラー内で定義する場合もあるが、
 // 複数のハンドラーで共有する場合はmodelsパッケージに配置
 type CreateBlogRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Author  string `json:"author"`
+	// ID is an optional client-managed ID; a UUID is generated when empty
+	ID       string `json:"id,omitempty"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Author   string `json:"author"`
+	Category string `json:"category,omitempty"`
+	// AuthorID is the author's optional stable identity, replaced by the
+	// authenticated subject when the server knows it
+	AuthorID string `json:"author_id,omitempty"`
+	// Tags are optional; see SetTagsRequest for the rules
+	Tags []string `json:"tags,omitempty"`
+	// ExpiresAt optionally sets when the blog expires, overriding BLOG_TTL
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // Valid implements the Validator interface
@@ -35,32 +86,59 @@ type CreateBlogRequest struct {
 // データベースチェックなど重い処理はここでは行わず、基本的な形式チェックのみ
 func (r CreateBlogRequest) Valid(ctx context.Context) map[string]string {
 	problems := make(map[string]string)
+	cfg := validationConfigFromContext(ctx)
 
-	// タイトルのバリデーション
-	if strings.TrimSpace(r.Title) == "" {
-		problems["title"] = "title is required"
+	// クライアント指定IDはURLパスにそのまま使える形式に限る
+	if r.ID != "" && !validClientID(r.ID) {
+		problems["id"] = fmt.Sprintf("id must be 1-%d letters, digits, '-' or '_'", maxClientIDLen)
 	}
 
-	if len(r.Title) > 100 {
-		problems["title"] = "title must be less than 100 characters"
+	// 長さは保存時と同じく前後の空白を除いた値で判定する
+	// 空白だけの値が「必須」と「長さOK」を同時に満たすような不整合を避ける
+	// 空の場合は「必須」のみを報告し、長さなど値に対する規則は値がある場合だけ検査する
+
+	// タイトルのバリデーション
+	if title := strings.TrimSpace(r.Title); title == "" {
+		problems["title"] = "title is required"
+	} else if len(title) > cfg.MaxTitleLen {
+		problems["title"] = fmt.Sprintf("title must be less than %d characters", cfg.MaxTitleLen)
 	}
 
 	// コンテンツのバリデーション
-	if strings.TrimSpace(r.Content) == "" {
+	if content := strings.TrimSpace(r.Content); content == "" {
 		problems["content"] = "content is required"
-	}
-
-	if len(r.Content) > 5000 {
-		problems["content"] = "content must be less than 5000 characters"
+	} else if len(content) > cfg.MaxContentLen {
+		problems["content"] = fmt.Sprintf("content must be less than %d characters", cfg.MaxContentLen)
 	}
 
 	// 作者のバリデーション
-	if strings.TrimSpace(r.Author) == "" {
+	if author := strings.TrimSpace(r.Author); author == "" {
 		problems["author"] = "author is required"
+	} else if len(author) > cfg.MaxAuthorLen {
+		problems["author"] = fmt.Sprintf("author must be less than %d characters", cfg.MaxAuthorLen)
 	}
 
-	if len(r.Author) > 50 {
-		problems["author"] = "author must be less than 50 characters"
+	if r.AuthorID != "" && !validAuthorID(r.AuthorID) {
+		problems["author_id"] = fmt.Sprintf("author_id must be 1-%d characters without spaces", maxAuthorIDLen)
+	}
+
+	// 作者の許可リスト/拒否リスト（保存時と同じ正規化後の名前で照合）
+	// 長さの問題とは独立した規則なので、両方に違反する場合は両方を報告する
+	if author := cfg.AuthorNormalization.Apply(r.Author); author != "" {
+		addProblem(problems, "author", cfg.authorProblem(author))
+	}
+
+	// カテゴリーのバリデーション（許可リストと必須設定）
+	if problem := cfg.categoryProblem(strings.TrimSpace(r.Category)); problem != "" {
+		problems["category"] = problem
+	}
+
+	// タグのバリデーション（任意項目）
+	addProblem(problems, "tags", tagsProblem(r.Tags))
+
+	// 作成した時点で期限切れになる投稿は受け付けない
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		problems["expires_at"] = "expires_at must be in the future"
 	}
 
 	return problems
@@ -69,33 +147,81 @@ func (r CreateBlogRequest) Valid(ctx context.Context) map[string]string {
 // UpdateBlogRequest represents a request to update a blog
 // ポインタ型を使用することで、フィールドが指定されたかどうかを判別可能
 // nilの場合は更新対象外、値がある場合は更新対象として扱う
+// 明示的なnullは省略と区別できないためエラーとする（UnmarshalJSONで検出）
 type UpdateBlogRequest struct {
-	Title   *string `json:"title,omitempty"`
-	Content *string `json:"content,omitempty"`
+	Title    *string `json:"title,omitempty"`
+	Content  *string `json:"content,omitempty"`
+	Category *string `json:"category,omitempty"`
+
+	// nullFieldsは明示的にnullが送られたフィールド名
+	nullFields []string
+}
+
+// UnmarshalJSON decodes the request while recording fields sent as explicit null
+// ポインタへのデコードではnullと省略がどちらもnilになり、更新が黙って無視されてしまう
+func (r *UpdateBlogRequest) UnmarshalJSON(data []byte) error {
+	type plain UpdateBlogRequest
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var nullFields []string
+	for key, value := range raw {
+		if string(value) != "null" {
+			continue
+		}
+		// encoding/jsonと同じく、キーは大文字小文字を区別せずに照合する
+		for _, field := range []string{"title", "content", "category"} {
+			if strings.EqualFold(key, field) {
+				nullFields = append(nullFields, field)
+			}
+		}
+	}
+
+	*r = UpdateBlogRequest(decoded)
+	r.nullFields = nullFields
+	return nil
 }
 
 // Valid implements the Validator interface
 // 更新リクエストのバリデーション - 指定されたフィールドのみチェック
 func (r UpdateBlogRequest) Valid(ctx context.Context) map[string]string {
 	problems := make(map[string]string)
+	cfg := validationConfigFromContext(ctx)
+
+	// 明示的なnullは「変更しない」とも「空にする」とも解釈できるため拒否する
+	// フィールドを変更しない場合は省略し、カテゴリーを外す場合は "" を送る
+	for _, field := range r.nullFields {
+		problems[field] = field + " cannot be null"
+	}
 
-	// タイトルが指定されている場合のみバリデーション
+	// タイトルが指定されている場合のみバリデーション（長さは前後の空白を除いて判定）
 	if r.Title != nil {
-		if len(*r.Title) > 100 {
-			problems["title"] = "title must be less than 100 characters"
-		}
-		if strings.TrimSpace(*r.Title) == "" {
+		if title := strings.TrimSpace(*r.Title); title == "" {
 			problems["title"] = "title cannot be empty"
+		} else if len(title) > cfg.MaxTitleLen {
+			problems["title"] = fmt.Sprintf("title must be less than %d characters", cfg.MaxTitleLen)
 		}
 	}
 
 	// コンテンツが指定されている場合のみバリデーション
 	if r.Content != nil {
-		if len(*r.Content) > 5000 {
-			problems["content"] = "content must be less than 5000 characters"
-		}
-		if strings.TrimSpace(*r.Content) == "" {
+		if content := strings.TrimSpace(*r.Content); content == "" {
 			problems["content"] = "content cannot be empty"
+		} else if len(content) > cfg.MaxContentLen {
+			problems["content"] = fmt.Sprintf("content must be less than %d characters", cfg.MaxContentLen)
+		}
+	}
+
+	// カテゴリーが指定されている場合のみバリデーション
+	if r.Category != nil {
+		if problem := cfg.categoryProblem(strings.TrimSpace(*r.Category)); problem != "" {
+			problems["category"] = problem
 		}
 	}
 
@@ -105,29 +231,174 @@ func (r UpdateBlogRequest) Valid(ctx context.Context) map[string]string {
 // NewBlog creates a new blog from a create request
 // Mat Ryerのパターン: ファクトリー関数でドメインオブジェクトを生成
 // IDの生成、タイムスタンプの設定、データの正規化などを一箇所で処理
-func NewBlog(req CreateBlogRequest) *Blog {
+// optsで設定に応じた正規化ポリシーを指定できる
+func NewBlog(req CreateBlogRequest, opts ...Option) *Blog {
+	o := newOptions(opts)
 	now := time.Now().UTC() // UTCで統一してタイムゾーンの問題を回避
-	return &Blog{
-		ID:        uuid.New().String(),            // 一意なIDを自動生成
-		Title:     strings.TrimSpace(req.Title),   // 前後の空白を除去
-		Content:   strings.TrimSpace(req.Content), // 前後の空白を除去
-		Author:    strings.TrimSpace(req.Author),  // 前後の空白を除去
+	blog := &Blog{
+		ID:        o.newID(),                    // 一意なIDを自動生成（設定によりUUIDかULID）
+		Title:     strings.TrimSpace(req.Title), // 前後の空白を除去
+		Content:   o.cleanContent(req.Content),  // 前後の空白を除去（設定により行単位で正規化、除去しないことも可能）
+		Author:    o.author.Apply(req.Author),   // 前後の空白を除去（設定により空白の圧縮なども）
+		AuthorID:  req.AuthorID,
+		Category:  strings.TrimSpace(req.Category),
+		Tags:      NormalizeTags(req.Tags),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	if req.ID != "" {
+		blog.ID = req.ID // クライアント管理のID（インポートや冪等な作成用）
+	}
+	if o.authorID != "" {
+		blog.AuthorID = o.authorID // 認証済みの主体はリクエストの自己申告より優先する
+	}
+	// 期限はリクエストでの指定を優先し、なければ設定のTTLから決める
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		blog.ExpiresAt = &expiresAt
+	} else if o.ttl > 0 {
+		expiresAt := now.Add(o.ttl)
+		blog.ExpiresAt = &expiresAt
+	}
+	// スラッグはタイトルから生成する。他の投稿との衝突はハンドラーで解決する
+	blog.Slug = Slugify(blog.Title)
+	blog.ContentHash = ContentHash(blog.Title, blog.Content, blog.Author)
+	return blog
+}
+
+// maxClientIDLen caps the length of client-specified IDs
+const maxClientIDLen = 64
+
+// maxAuthorIDLen caps the length of author IDs
+const maxAuthorIDLen = 128
+
+// validAuthorID reports whether id can be used as an author ID
+// 認証基盤の主体（"auth0|123" やメールアドレスなど）をそのまま使えるよう、文字種は空白と制御文字以外を許す
+func validAuthorID(id string) bool {
+	if id == "" || len(id) > maxAuthorIDLen {
+		return false
+	}
+	return !strings.ContainsFunc(id, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	})
+}
+
+// reservedIDs are path segments under /api/v1/blogs/ served by other routes
+var reservedIDs = []string{"recent", "export", "count"}
+
+// validClientID reports whether id is safe to use as a client-specified blog ID
+func validClientID(id string) bool {
+	if len(id) > maxClientIDLen || slices.Contains(reservedIDs, id) {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return id != ""
+}
+
+// ContentHash computes a hash identifying a post by its title, content and author
+// 重複投稿の検出に使用する。正規化済み（TrimSpace後）の値を渡すこと
+// 区切り文字を挟むことで "ab"+"c" と "a"+"bc" が同じハッシュにならないようにする
+func ContentHash(title, content, author string) string {
+	h := sha256.New()
+	h.Write([]byte(title))
+	h.Write([]byte{0})
+	h.Write([]byte(content))
+	h.Write([]byte{0})
+	h.Write([]byte(author))
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // Update updates the blog with the provided update request
 // Mat Ryerのパターン: ドメインモデルがビジネスロジックを担当
 // 更新処理をモデル自身のメソッドとして実装し、ビジネスルールを集約
-func (b *Blog) Update(req UpdateBlogRequest) {
-	// 指定されたフィールドのみ更新
+// 戻り値は実際に値が変わったフィールドがあったか（変更のない更新でも更新日時は進む）
+func (b *Blog) Update(req UpdateBlogRequest, opts ...Option) bool {
+	o := newOptions(opts)
+	now := time.Now().UTC()
+
+	// 指定されたフィールドのみ更新し、実際に値が変わったフィールドを記録
+	var changed []string
 	if req.Title != nil {
-		b.Title = strings.TrimSpace(*req.Title)
+		title := strings.TrimSpace(*req.Title)
+		if title != b.Title {
+			changed = append(changed, "title")
+		}
+		b.Title = title
 	}
 	if req.Content != nil {
-		b.Content = strings.TrimSpace(*req.Content)
+		content := o.cleanContent(*req.Content)
+		if content != b.Content {
+			changed = append(changed, "content")
+		}
+		b.Content = content
+	}
+	if req.Category != nil {
+		category := strings.TrimSpace(*req.Category)
+		if category != b.Category {
+			changed = append(changed, "category")
+		}
+		b.Category = category
 	}
+	if len(changed) > 0 {
+		b.addRevision(BlogRevision{ChangedFields: changed, ChangedAt: now, Actor: o.actor}, o.maxRevisions)
+	}
+
+	// 内容が変わった可能性があるためハッシュを再計算
+	b.ContentHash = ContentHash(b.Title, b.Content, b.Author)
 	// 更新日時は常に現在時刻に設定
-	b.UpdatedAt = time.Now().UTC()
+	b.UpdatedAt = now
+	return len(changed) > 0
+}
+
+// addRevision appends a revision, keeping only the newest max entries
+// ストアはBlogを浅いコピーで返すため、スライスの共有を避けて常に新しいスライスを作る
+func (b *Blog) addRevision(rev BlogRevision, max int) {
+	revisions := make([]BlogRevision, 0, len(b.Revisions)+1)
+	revisions = append(revisions, b.Revisions...)
+	revisions = append(revisions, rev)
+	if max > 0 && len(revisions) > max {
+		revisions = revisions[len(revisions)-max:]
+	}
+	b.Revisions = revisions
+}
+
+// cleanContent applies the configured content normalization policy
+func (o options) cleanContent(content string) string {
+	if o.normalizeContent {
+		content = normalizeContent(content)
+	}
+	if !o.trimContent {
+		return content
+	}
+	return strings.TrimSpace(content)
+}
+
+// normalizeContent trims trailing whitespace from each line and collapses
+// three or more consecutive blank lines down to two
+// 単独の空行（段落区切り）はそのまま残す
+func normalizeContent(s string) string {
+	lines := strings.Split(s, "\n")
+	normalized := make([]string, 0, len(lines))
+
+	blankRun := 0
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			blankRun++
+			if blankRun > 2 {
+				continue
+			}
+		} else {
+			blankRun = 0
+		}
+		normalized = append(normalized, line)
+	}
+
+	return strings.Join(normalized, "\n")
 }
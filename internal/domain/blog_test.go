@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -99,18 +100,18 @@ func TestCreateBlogRequest_Valid(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			problems := tt.req.Valid(context.Background())
-			
+
 			if tt.wantErrs == nil && len(problems) > 0 {
 				t.Errorf("expected no validation errors, got: %v", problems)
 				return
 			}
-			
+
 			for _, wantErr := range tt.wantErrs {
 				if _, exists := problems[wantErr]; !exists {
 					t.Errorf("expected validation error for field %q, but it was not found", wantErr)
 				}
 			}
-			
+
 			if len(problems) != len(tt.wantErrs) {
 				t.Errorf("expected %d validation errors, got %d: %v", len(tt.wantErrs), len(problems), problems)
 			}
@@ -199,18 +200,18 @@ func TestUpdateBlogRequest_Valid(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			problems := tt.req.Valid(context.Background())
-			
+
 			if tt.wantErrs == nil && len(problems) > 0 {
 				t.Errorf("expected no validation errors, got: %v", problems)
 				return
 			}
-			
+
 			for _, wantErr := range tt.wantErrs {
 				if _, exists := problems[wantErr]; !exists {
 					t.Errorf("expected validation error for field %q, but it was not found", wantErr)
 				}
 			}
-			
+
 			if len(problems) != len(tt.wantErrs) {
 				t.Errorf("expected %d validation errors, got %d: %v", len(tt.wantErrs), len(problems), problems)
 			}
@@ -218,6 +219,379 @@ func TestUpdateBlogRequest_Valid(t *testing.T) {
 	}
 }
 
+func TestValid_TrimmedLengths(t *testing.T) {
+	blank := strings.Repeat(" ", 101)
+	padded := "  Valid Title" + strings.Repeat(" ", 200)
+	paddedLong := " " + strings.Repeat("a", 101) + " "
+
+	tests := []struct {
+		name      string
+		validator interface {
+			Valid(ctx context.Context) map[string]string
+		}
+		wantTitle string
+	}{
+		{
+			name:      "create: spaces over the limit are only required",
+			validator: CreateBlogRequest{Title: blank, Content: "Content", Author: "Author"},
+			wantTitle: "title is required",
+		},
+		{
+			name:      "create: padding does not count toward the limit",
+			validator: CreateBlogRequest{Title: padded, Content: "Content", Author: "Author"},
+		},
+		{
+			name:      "create: trimmed value over the limit",
+			validator: CreateBlogRequest{Title: paddedLong, Content: "Content", Author: "Author"},
+			wantTitle: "title must be less than 100 characters",
+		},
+		{
+			name:      "update: spaces over the limit are only empty",
+			validator: UpdateBlogRequest{Title: &blank},
+			wantTitle: "title cannot be empty",
+		},
+		{
+			name:      "update: padding does not count toward the limit",
+			validator: UpdateBlogRequest{Title: &padded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := tt.validator.Valid(context.Background())
+			if got := problems["title"]; got != tt.wantTitle {
+				t.Errorf("expected title problem %q, got %q", tt.wantTitle, got)
+			}
+		})
+	}
+}
+
+func TestCreateBlogRequest_Valid_MessagePrecedence(t *testing.T) {
+	cfg := DefaultValidationConfig()
+	cfg.DeniedAuthors = []string{strings.Repeat("x", 51)}
+	ctx := ContextWithValidationConfig(context.Background(), cfg)
+
+	valid := CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"}
+
+	tests := []struct {
+		name  string
+		req   func(r CreateBlogRequest) CreateBlogRequest
+		field string
+		want  string
+	}{
+		{
+			name:  "title empty after trimming long padding",
+			req:   func(r CreateBlogRequest) CreateBlogRequest { r.Title = strings.Repeat(" ", 200); return r },
+			field: "title",
+			want:  "title is required",
+		},
+		{
+			name:  "content empty after trimming long padding",
+			req:   func(r CreateBlogRequest) CreateBlogRequest { r.Content = strings.Repeat("\n", 6000); return r },
+			field: "content",
+			want:  "content is required",
+		},
+		{
+			name:  "author empty after trimming long padding",
+			req:   func(r CreateBlogRequest) CreateBlogRequest { r.Author = strings.Repeat(" ", 60); return r },
+			field: "author",
+			want:  "author is required",
+		},
+		{
+			name:  "author too long and denied reports both",
+			req:   func(r CreateBlogRequest) CreateBlogRequest { r.Author = strings.Repeat("x", 51); return r },
+			field: "author",
+			want:  "author must be less than 50 characters; author is not allowed to post",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := tt.req(valid).Valid(ctx)
+			if got := problems[tt.field]; got != tt.want {
+				t.Errorf("expected %s problem %q, got %q", tt.field, tt.want, got)
+			}
+			if len(problems) != 1 {
+				t.Errorf("expected only the %s problem, got %v", tt.field, problems)
+			}
+		})
+	}
+}
+
+func TestCreateBlogRequest_Valid_ConfiguredLimits(t *testing.T) {
+	req := CreateBlogRequest{
+		Title:   strings.Repeat("a", 150),
+		Content: "Valid content",
+		Author:  strings.Repeat("b", 60),
+	}
+
+	// Rejected with the default limits
+	problems := req.Valid(context.Background())
+	if problems["title"] == "" || problems["author"] == "" {
+		t.Fatalf("expected title and author problems with default limits, got %v", problems)
+	}
+
+	// Accepted once the limits are raised
+	cfg := DefaultValidationConfig()
+	cfg.MaxTitleLen = 200
+	cfg.MaxAuthorLen = 80
+	ctx := ContextWithValidationConfig(context.Background(), cfg)
+	if problems := req.Valid(ctx); len(problems) != 0 {
+		t.Errorf("expected no problems with raised limits, got %v", problems)
+	}
+
+	// Lowered limits are reported in the message
+	cfg.MaxTitleLen = 10
+	ctx = ContextWithValidationConfig(context.Background(), cfg)
+	problems = req.Valid(ctx)
+	if problems["title"] != "title must be less than 10 characters" {
+		t.Errorf("expected message naming the configured limit, got %q", problems["title"])
+	}
+}
+
+func TestCreateBlogRequest_Valid_AuthorLists(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           ValidationConfig
+		author        string
+		expectProblem string
+	}{
+		{
+			name:   "author on allowlist",
+			cfg:    ValidationConfig{AllowedAuthors: []string{"alice", "bob"}},
+			author: "alice",
+		},
+		{
+			name:          "author not on allowlist",
+			cfg:           ValidationConfig{AllowedAuthors: []string{"alice", "bob"}},
+			author:        "mallory",
+			expectProblem: "author is not on the allowlist",
+		},
+		{
+			name:          "author on denylist",
+			cfg:           ValidationConfig{DeniedAuthors: []string{"mallory"}},
+			author:        "mallory",
+			expectProblem: "author is not allowed to post",
+		},
+		{
+			name:   "author not on denylist",
+			cfg:    ValidationConfig{DeniedAuthors: []string{"mallory"}},
+			author: "alice",
+		},
+		{
+			name:   "surrounding whitespace is ignored",
+			cfg:    ValidationConfig{AllowedAuthors: []string{"alice"}},
+			author: "  alice ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultValidationConfig()
+			cfg.AllowedAuthors = tt.cfg.AllowedAuthors
+			cfg.DeniedAuthors = tt.cfg.DeniedAuthors
+			ctx := ContextWithValidationConfig(context.Background(), cfg)
+
+			req := CreateBlogRequest{Title: "Title", Content: "Content", Author: tt.author}
+			problems := req.Valid(ctx)
+
+			if problems["author"] != tt.expectProblem {
+				t.Errorf("expected author problem %q, got %q", tt.expectProblem, problems["author"])
+			}
+		})
+	}
+}
+
+func TestCreateBlogRequest_Valid_ClientID(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            string
+		expectProblem bool
+	}{
+		{name: "no id", id: ""},
+		{name: "uuid", id: "3f2b8c1e-9d4a-4b7e-8f6a-1c2d3e4f5a6b"},
+		{name: "slug-like id", id: "import_2024-01"},
+		{name: "path separator", id: "a/b", expectProblem: true},
+		{name: "reserved route name", id: "recent", expectProblem: true},
+		{name: "reserved export route", id: "export", expectProblem: true},
+		{name: "reserved count route", id: "count", expectProblem: true},
+		{name: "whitespace", id: "a b", expectProblem: true},
+		{name: "too long", id: strings.Repeat("a", 65), expectProblem: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CreateBlogRequest{ID: tt.id, Title: "Title", Content: "Content", Author: "Author"}
+			problems := req.Valid(context.Background())
+
+			if _, got := problems["id"]; got != tt.expectProblem {
+				t.Errorf("expected id problem=%v, got %v", tt.expectProblem, problems)
+			}
+		})
+	}
+}
+
+func TestCreateBlogRequest_Valid_AuthorID(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorID      string
+		expectProblem bool
+	}{
+		{name: "no author id", authorID: ""},
+		{name: "opaque subject", authorID: "auth0|5f1c2d3e"},
+		{name: "email", authorID: "alice@example.com"},
+		{name: "whitespace", authorID: "alice smith", expectProblem: true},
+		{name: "control character", authorID: "alice\x00", expectProblem: true},
+		{name: "too long", authorID: strings.Repeat("a", 129), expectProblem: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author", AuthorID: tt.authorID}
+			problems := req.Valid(context.Background())
+
+			if _, got := problems["author_id"]; got != tt.expectProblem {
+				t.Errorf("expected author_id problem=%v, got %v", tt.expectProblem, problems)
+			}
+		})
+	}
+}
+
+func TestCreateBlogRequest_Valid_Category(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           ValidationConfig
+		category      string
+		expectProblem string
+	}{
+		{
+			name:     "allowed category",
+			cfg:      ValidationConfig{AllowedCategories: []string{"tech", "life"}},
+			category: "tech",
+		},
+		{
+			name:          "category not in allowed set",
+			cfg:           ValidationConfig{AllowedCategories: []string{"tech", "life"}},
+			category:      "gossip",
+			expectProblem: "category must be one of: tech, life",
+		},
+		{
+			name:     "empty category allowed by default",
+			cfg:      ValidationConfig{AllowedCategories: []string{"tech"}},
+			category: "",
+		},
+		{
+			name:          "empty category rejected when required",
+			cfg:           ValidationConfig{RequireCategory: true},
+			category:      "  ",
+			expectProblem: "category is required",
+		},
+		{
+			name:     "any category without an allowed set",
+			cfg:      ValidationConfig{},
+			category: "anything",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultValidationConfig()
+			cfg.AllowedCategories = tt.cfg.AllowedCategories
+			cfg.RequireCategory = tt.cfg.RequireCategory
+			ctx := ContextWithValidationConfig(context.Background(), cfg)
+
+			req := CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author", Category: tt.category}
+			problems := req.Valid(ctx)
+
+			if problems["category"] != tt.expectProblem {
+				t.Errorf("expected category problem %q, got %q", tt.expectProblem, problems["category"])
+			}
+		})
+	}
+}
+
+func TestBlog_Update_Category(t *testing.T) {
+	blog := NewBlog(CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author", Category: " tech "})
+	if blog.Category != "tech" {
+		t.Fatalf("expected trimmed category 'tech', got %q", blog.Category)
+	}
+
+	blog.Update(UpdateBlogRequest{Category: stringPtr("life")})
+
+	if blog.Category != "life" {
+		t.Errorf("expected category 'life', got %q", blog.Category)
+	}
+	if len(blog.Revisions) != 1 || blog.Revisions[0].ChangedFields[0] != "category" {
+		t.Errorf("expected a category revision, got %+v", blog.Revisions)
+	}
+}
+
+func TestUpdateBlogRequest_ExplicitNull(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantTitle   *string
+		wantProblem string
+	}{
+		{
+			name: "omitted title",
+			body: `{"content":"Content"}`,
+		},
+		{
+			name:        "null title",
+			body:        `{"title":null}`,
+			wantProblem: "title cannot be null",
+		},
+		{
+			name:        "null title with different key case",
+			body:        `{"Title":null}`,
+			wantProblem: "title cannot be null",
+		},
+		{
+			name:      "title value",
+			body:      `{"title":"New Title"}`,
+			wantTitle: ptr("New Title"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req UpdateBlogRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+
+			if (req.Title == nil) != (tt.wantTitle == nil) || (req.Title != nil && *req.Title != *tt.wantTitle) {
+				t.Errorf("expected title %v, got %v", tt.wantTitle, req.Title)
+			}
+			if got := req.Valid(context.Background())["title"]; got != tt.wantProblem {
+				t.Errorf("expected title problem %q, got %q", tt.wantProblem, got)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUpdateBlogRequest_Valid_ConfiguredLimits(t *testing.T) {
+	req := UpdateBlogRequest{
+		Content: stringPtr(strings.Repeat("a", 6000)),
+	}
+
+	if problems := req.Valid(context.Background()); problems["content"] == "" {
+		t.Fatal("expected content problem with default limits")
+	}
+
+	cfg := DefaultValidationConfig()
+	cfg.MaxContentLen = 10000
+	ctx := ContextWithValidationConfig(context.Background(), cfg)
+	if problems := req.Valid(ctx); len(problems) != 0 {
+		t.Errorf("expected no problems with raised limit, got %v", problems)
+	}
+}
+
 func TestNewBlog(t *testing.T) {
 	req := CreateBlogRequest{
 		Title:   "  Test Title  ",
@@ -260,6 +634,69 @@ func TestNewBlog(t *testing.T) {
 	}
 }
 
+func TestNewBlog_AuthorID(t *testing.T) {
+	req := CreateBlogRequest{Title: "Title", Content: "Content", Author: "Alice", AuthorID: "user-1"}
+
+	blog := NewBlog(req)
+	if blog.Author != "Alice" || blog.AuthorID != "user-1" {
+		t.Errorf("expected author Alice with ID user-1, got %q / %q", blog.Author, blog.AuthorID)
+	}
+
+	// 認証済みの主体はリクエストの値より優先され、空の場合はリクエストの値を使う
+	blog = NewBlog(req, WithAuthorID("subject-9"))
+	if blog.AuthorID != "subject-9" {
+		t.Errorf("expected the authenticated subject, got %q", blog.AuthorID)
+	}
+	blog = NewBlog(req, WithAuthorID(""))
+	if blog.AuthorID != "user-1" {
+		t.Errorf("expected the requested author ID, got %q", blog.AuthorID)
+	}
+}
+
+func TestNewBlog_Expiry(t *testing.T) {
+	requested := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		ttl       time.Duration
+		want      time.Duration // 作成日時からの期限（0は期限なし）
+	}{
+		{name: "no expiry", want: 0},
+		{name: "ttl applied", ttl: 24 * time.Hour, want: 24 * time.Hour},
+		{name: "request overrides ttl", expiresAt: &requested, ttl: 24 * time.Hour, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author", ExpiresAt: tt.expiresAt}
+			blog := NewBlog(req, WithTTL(tt.ttl))
+
+			if tt.want == 0 {
+				if blog.ExpiresAt != nil {
+					t.Errorf("expected no expiry, got %v", blog.ExpiresAt)
+				}
+				return
+			}
+			if blog.ExpiresAt == nil {
+				t.Fatal("expected ExpiresAt to be set")
+			}
+			if got := blog.ExpiresAt.Sub(blog.CreatedAt); got < tt.want-time.Minute || got > tt.want {
+				t.Errorf("expected expiry about %v after creation, got %v", tt.want, got)
+			}
+			if !blog.Expired(*blog.ExpiresAt) || blog.Expired(blog.CreatedAt) {
+				t.Error("expected the blog to expire exactly at ExpiresAt")
+			}
+		})
+	}
+
+	past := time.Now().Add(-time.Second)
+	req := CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author", ExpiresAt: &past}
+	if problems := req.Valid(context.Background()); problems["expires_at"] == "" {
+		t.Error("expected a problem for expires_at in the past")
+	}
+}
+
 func TestBlog_Update(t *testing.T) {
 	blog := &Blog{
 		ID:        "test-id",
@@ -274,9 +711,9 @@ func TestBlog_Update(t *testing.T) {
 	time.Sleep(time.Millisecond) // Ensure different timestamp
 
 	tests := []struct {
-		name           string
-		req            UpdateBlogRequest
-		expectedTitle  string
+		name            string
+		req             UpdateBlogRequest
+		expectedTitle   string
 		expectedContent string
 	}{
 		{
@@ -316,7 +753,7 @@ func TestBlog_Update(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a copy of the blog for this test
 			testBlog := *blog
-			
+
 			testBlog.Update(tt.req)
 
 			if testBlog.Title != tt.expectedTitle {
@@ -346,7 +783,176 @@ func TestBlog_Update(t *testing.T) {
 	}
 }
 
+func TestNormalizeContent(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "no changes needed",
+			input: "line 1\nline 2",
+			want:  "line 1\nline 2",
+		},
+		{
+			name:  "trailing spaces and tabs per line",
+			input: "line 1   \nline 2\t\nline 3",
+			want:  "line 1\nline 2\nline 3",
+		},
+		{
+			name:  "carriage returns are trimmed",
+			input: "line 1\r\nline 2\r\n",
+			want:  "line 1\nline 2\n",
+		},
+		{
+			name:  "leading indentation is kept",
+			input: "code:\n    indented  ",
+			want:  "code:\n    indented",
+		},
+		{
+			name:  "single blank line is kept",
+			input: "para 1\n\npara 2",
+			want:  "para 1\n\npara 2",
+		},
+		{
+			name:  "two blank lines are kept",
+			input: "para 1\n\n\npara 2",
+			want:  "para 1\n\n\npara 2",
+		},
+		{
+			name:  "excessive blank lines are collapsed to two",
+			input: "para 1\n\n\n\n\n\npara 2",
+			want:  "para 1\n\n\npara 2",
+		},
+		{
+			name:  "whitespace-only lines count as blank",
+			input: "para 1\n  \n\t\n \n  \npara 2",
+			want:  "para 1\n\n\npara 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeContent(tt.input); got != tt.want {
+				t.Errorf("normalizeContent(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBlog_ContentNormalization(t *testing.T) {
+	req := CreateBlogRequest{
+		Title:   "Title",
+		Content: "para 1   \n\n\n\n\npara 2  \n\n\n",
+		Author:  "Author",
+	}
+
+	normalized := NewBlog(req, WithContentNormalization(true))
+	if normalized.Content != "para 1\n\n\npara 2" {
+		t.Errorf("expected normalized content, got %q", normalized.Content)
+	}
+
+	plain := NewBlog(req, WithContentNormalization(false))
+	if plain.Content != "para 1   \n\n\n\n\npara 2" {
+		t.Errorf("expected only outer whitespace trimmed, got %q", plain.Content)
+	}
+
+	blog := NewBlog(req)
+	blog.Update(UpdateBlogRequest{Content: stringPtr("a  \n\n\n\nb")}, WithContentNormalization(true))
+	if blog.Content != "a\n\n\nb" {
+		t.Errorf("expected Update to normalize content, got %q", blog.Content)
+	}
+}
+
+func TestNewBlog_ContentTrimming(t *testing.T) {
+	req := CreateBlogRequest{
+		Title:   "  Title  ",
+		Content: "    func main() {}\n",
+		Author:  "  Author  ",
+	}
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantContent string
+		wantUpdated string
+	}{
+		{name: "trimmed by default", wantContent: "func main() {}", wantUpdated: "indented"},
+		{name: "trimmed when enabled", opts: []Option{WithContentTrimming(true)}, wantContent: "func main() {}", wantUpdated: "indented"},
+		{name: "kept when disabled", opts: []Option{WithContentTrimming(false)}, wantContent: "    func main() {}\n", wantUpdated: "\tindented\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blog := NewBlog(req, tt.opts...)
+			if blog.Content != tt.wantContent {
+				t.Errorf("expected content %q, got %q", tt.wantContent, blog.Content)
+			}
+			// タイトルと作者は設定に関わらず除去される
+			if blog.Title != "Title" || blog.Author != "Author" {
+				t.Errorf("expected title and author trimmed, got %q %q", blog.Title, blog.Author)
+			}
+
+			blog.Update(UpdateBlogRequest{Content: stringPtr("\tindented\n")}, tt.opts...)
+			if blog.Content != tt.wantUpdated {
+				t.Errorf("expected updated content %q, got %q", tt.wantUpdated, blog.Content)
+			}
+		})
+	}
+}
+
+func TestBlog_Update_Revisions(t *testing.T) {
+	blog := NewBlog(CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+
+	blog.Update(UpdateBlogRequest{Title: stringPtr("New Title")}, WithActor("editor"))
+
+	if len(blog.Revisions) != 1 {
+		t.Fatalf("expected 1 revision, got %d", len(blog.Revisions))
+	}
+	rev := blog.Revisions[0]
+	if len(rev.ChangedFields) != 1 || rev.ChangedFields[0] != "title" {
+		t.Errorf("expected changed fields [title], got %v", rev.ChangedFields)
+	}
+	if rev.Actor != "editor" {
+		t.Errorf("expected actor 'editor', got %q", rev.Actor)
+	}
+	if !rev.ChangedAt.Equal(blog.UpdatedAt) {
+		t.Errorf("expected revision timestamp %v, got %v", blog.UpdatedAt, rev.ChangedAt)
+	}
+
+	// Setting a field to its current value is not a change
+	blog.Update(UpdateBlogRequest{Title: stringPtr("New Title"), Content: stringPtr("New Content")})
+	if len(blog.Revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(blog.Revisions))
+	}
+	if fields := blog.Revisions[1].ChangedFields; len(fields) != 1 || fields[0] != "content" {
+		t.Errorf("expected changed fields [content], got %v", fields)
+	}
+
+	// No-op updates don't record a revision
+	blog.Update(UpdateBlogRequest{})
+	if len(blog.Revisions) != 2 {
+		t.Errorf("expected no revision for no-op update, got %d", len(blog.Revisions))
+	}
+}
+
+func TestBlog_Update_MaxRevisions(t *testing.T) {
+	blog := NewBlog(CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+
+	for i := 0; i < 5; i++ {
+		blog.Update(UpdateBlogRequest{Title: stringPtr(strings.Repeat("a", i+1))}, WithMaxRevisions(3))
+	}
+
+	if len(blog.Revisions) != 3 {
+		t.Fatalf("expected revisions capped at 3, got %d", len(blog.Revisions))
+	}
+	// Oldest revisions are dropped first
+	if !blog.Revisions[2].ChangedAt.Equal(blog.UpdatedAt) {
+		t.Error("expected newest revision to be kept")
+	}
+}
+
 // Helper function to create a string pointer
 func stringPtr(s string) *string {
 	return &s
-}
\ No newline at end of file
+}
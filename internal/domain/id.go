@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator returns a new unique blog ID
+type IDGenerator func() string
+
+// NewUUID returns a random UUID (version 4), the default blog ID
+func NewUUID() string {
+	return uuid.New().String()
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidState keeps the last ULID so IDs generated within the same millisecond stay sorted
+var ulidState struct {
+	mu      sync.Mutex
+	ms      uint64
+	entropy [10]byte
+}
+
+// NewULID returns a ULID: 26 Crockford base32 characters whose first 10 encode
+// the creation time in milliseconds, so IDs sort in creation order
+// 同じミリ秒内では乱数部を1ずつ増やし、生成順に並ぶようにする（ULIDのmonotonic方式）
+func NewULID() string {
+	ms := uint64(time.Now().UnixMilli())
+
+	ulidState.mu.Lock()
+	if ms <= ulidState.ms {
+		// 時計が戻った場合も前回の時刻を使い続け、順序を保つ
+		ms = ulidState.ms
+		incrementEntropy(&ulidState.entropy)
+	} else {
+		ulidState.ms = ms
+		rand.Read(ulidState.entropy[:])
+	}
+	entropy := ulidState.entropy
+	ulidState.mu.Unlock()
+
+	return encodeULID(ms, entropy)
+}
+
+// incrementEntropy adds one to the 80-bit big-endian entropy
+// 桁あふれは2^80回に1回しか起きないため、ゼロに戻るだけで扱わない
+func incrementEntropy(entropy *[10]byte) {
+	for i := len(entropy) - 1; i >= 0; i-- {
+		entropy[i]++
+		if entropy[i] != 0 {
+			return
+		}
+	}
+}
+
+// encodeULID encodes the 48-bit timestamp and 80-bit entropy as 26 base32 characters
+func encodeULID(ms uint64, entropy [10]byte) string {
+	var id [26]byte
+	// 時刻部: 48ビットを10文字（50ビット）で表す
+	for i := 9; i >= 0; i-- {
+		id[i] = crockford[ms&0x1f]
+		ms >>= 5
+	}
+	// 乱数部: 80ビットを16文字で表す（5バイトごとに8文字）
+	for chunk := 0; chunk < 2; chunk++ {
+		var v uint64
+		for _, b := range entropy[chunk*5 : chunk*5+5] {
+			v = v<<8 | uint64(b)
+		}
+		for i := 7; i >= 0; i-- {
+			id[10+chunk*8+i] = crockford[v&0x1f]
+			v >>= 5
+		}
+	}
+	return string(id[:])
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestIDGenerators(t *testing.T) {
+	tests := []struct {
+		name   string
+		gen    IDGenerator
+		format *regexp.Regexp
+		sorted bool
+	}{
+		{
+			name:   "uuid",
+			gen:    NewUUID,
+			format: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		},
+		{
+			name:   "ulid",
+			gen:    NewULID,
+			format: regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+			sorted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const n = 1000
+			ids := make([]string, 0, n)
+			seen := make(map[string]bool, n)
+			for range n {
+				id := tt.gen()
+				if !tt.format.MatchString(id) {
+					t.Fatalf("unexpected ID format: %q", id)
+				}
+				if !validClientID(id) {
+					t.Fatalf("expected generated ID %q to be a valid client ID", id)
+				}
+				if seen[id] {
+					t.Fatalf("duplicate ID: %q", id)
+				}
+				seen[id] = true
+				ids = append(ids, id)
+			}
+			// 同じミリ秒内に生成されたものも含め、生成順に並ぶこと
+			if tt.sorted && !slices.IsSorted(ids) {
+				t.Error("expected IDs to sort in generation order")
+			}
+		})
+	}
+}
+
+func TestEncodeULID(t *testing.T) {
+	tests := []struct {
+		name    string
+		ms      uint64
+		entropy [10]byte
+		want    string
+	}{
+		{name: "zero", want: "00000000000000000000000000"},
+		{
+			name:    "maximum",
+			ms:      1<<48 - 1,
+			entropy: [10]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			want:    "7ZZZZZZZZZZZZZZZZZZZZZZZZZ",
+		},
+		{name: "timestamp", ms: 1469918176385, want: "01ARYZ6S410000000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encodeULID(tt.ms, tt.entropy); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewBlog_IDGenerator(t *testing.T) {
+	req := CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"}
+
+	blog := NewBlog(req, WithIDGenerator(func() string { return "generated" }))
+	if blog.ID != "generated" {
+		t.Errorf("expected generated ID, got %q", blog.ID)
+	}
+
+	// クライアント指定のIDが優先される
+	req.ID = "client-id"
+	blog = NewBlog(req, WithIDGenerator(NewULID))
+	if blog.ID != "client-id" {
+		t.Errorf("expected client ID, got %q", blog.ID)
+	}
+}
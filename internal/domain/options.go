@@ -0,0 +1,104 @@
+package domain
+
+import "time"
+
+// Option configures how NewBlog and Blog.Update build and normalize a blog
+// 設定値（config）に応じた正規化ポリシーなどを、ドメイン層がconfigパッケージに
+// 依存することなく受け取るためのfunctional optionパターン
+type Option func(*options)
+
+// options holds the policies applied when creating or updating a blog
+type options struct {
+	normalizeContent bool
+	trimContent      bool
+	author           AuthorNormalization
+	actor            string
+	authorID         string
+	maxRevisions     int
+	ttl              time.Duration
+	newID            IDGenerator
+}
+
+// DefaultMaxRevisions is the number of revisions kept per blog by default
+const DefaultMaxRevisions = 20
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		maxRevisions: DefaultMaxRevisions,
+		trimContent:  true,
+		newID:        NewUUID,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithContentNormalization trims trailing whitespace from each line of the
+// content and collapses runs of blank lines to at most two
+func WithContentNormalization(enabled bool) Option {
+	return func(o *options) {
+		o.normalizeContent = enabled
+	}
+}
+
+// WithContentTrimming controls whether leading and trailing whitespace is
+// removed from the content (enabled by default)
+// コードスニペットのように先頭のインデントに意味がある本文のために無効化できる
+// タイトルや作者は設定に関わらず常に前後の空白を除去する
+func WithContentTrimming(enabled bool) Option {
+	return func(o *options) {
+		o.trimContent = enabled
+	}
+}
+
+// WithActor records who performed an update in the revision history
+func WithActor(actor string) Option {
+	return func(o *options) {
+		o.actor = actor
+	}
+}
+
+// WithMaxRevisions caps the number of revisions kept per blog
+// 0以下の場合は上限なし
+func WithMaxRevisions(n int) Option {
+	return func(o *options) {
+		o.maxRevisions = n
+	}
+}
+
+// WithAuthorNormalization canonicalizes the author name of new blogs
+func WithAuthorNormalization(n AuthorNormalization) Option {
+	return func(o *options) {
+		o.author = n
+	}
+}
+
+// WithTTL makes new blogs expire ttl after creation unless the request sets ExpiresAt
+// 0以下の場合は期限なし
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithIDGenerator sets how new blogs get their ID (UUIDs by default)
+// クライアントがIDを指定した場合はそちらを優先する
+func WithIDGenerator(gen IDGenerator) Option {
+	return func(o *options) {
+		if gen != nil {
+			o.newID = gen
+		}
+	}
+}
+
+// WithAuthorID sets the author ID of new blogs from the authenticated subject,
+// overriding any author_id in the request (an empty id is ignored)
+func WithAuthorID(id string) Option {
+	return func(o *options) {
+		if id != "" {
+			o.authorID = id
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultSlug is used when a title contains no letters or digits
+const defaultSlug = "post"
+
+// maxSlugLen caps slug length so URLs stay readable
+const maxSlugLen = 80
+
+// Slugify derives a URL-friendly slug from a title
+// 英数字（日本語などUnicodeの文字を含む）は小文字化して残し、それ以外は
+// ハイフン1つにまとめる。一意性はストア側で確認し、衝突時は呼び出し側で
+// 連番のサフィックスを付ける
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > maxSlugLen {
+		// マルチバイト文字の途中で切らないようルーンの境界で切り詰める
+		cut := 0
+		for i := range slug {
+			if i > maxSlugLen {
+				break
+			}
+			cut = i
+		}
+		slug = strings.TrimSuffix(slug[:cut], "-")
+	}
+	if slug == "" {
+		return defaultSlug
+	}
+	return slug
+}
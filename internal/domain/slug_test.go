@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		expected string
+	}{
+		{
+			name:     "simple title",
+			title:    "Hello World",
+			expected: "hello-world",
+		},
+		{
+			name:     "punctuation collapses to one hyphen",
+			title:    "  Go 1.22: What's New?!  ",
+			expected: "go-1-22-what-s-new",
+		},
+		{
+			name:     "non-ASCII letters are kept",
+			title:    "Goで始めるAPI開発",
+			expected: "goで始めるapi開発",
+		},
+		{
+			name:     "no letters or digits",
+			title:    "!!!",
+			expected: "post",
+		},
+		{
+			name:     "long title is truncated",
+			title:    strings.Repeat("a", 100),
+			expected: strings.Repeat("a", 80),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
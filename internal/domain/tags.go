@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTags is the most tags a blog may have
+	MaxTags = 10
+	// MaxTagLen is the longest a single tag may be, in characters
+	MaxTagLen = 32
+)
+
+// NormalizeTags trims and lower-cases tags, dropping duplicates while keeping the first occurrence's order
+// 大文字小文字の違いだけのタグ（"Go" と "go"）は同じタグとして扱う
+// 常に新しいスライスを返すため、ストアが返したBlogとスライスを共有しない
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// MergeTags adds extra to tags, normalizing and dropping duplicates
+// MaxTagsを超える分はextra側から切り捨て、明示的に指定されたタグを優先する
+func MergeTags(tags, extra []string) []string {
+	merged := NormalizeTags(append(slices.Clone(tags), extra...))
+	if len(merged) > MaxTags {
+		merged = merged[:max(MaxTags, len(NormalizeTags(tags)))]
+	}
+	return merged
+}
+
+// tagsProblem returns why tags are not acceptable, or "" if they are
+// タグは文字（日本語を含む）、数字、'-'、'_' のみで構成する
+func tagsProblem(tags []string) string {
+	tags = NormalizeTags(tags)
+	if len(tags) > MaxTags {
+		return fmt.Sprintf("at most %d tags are allowed", MaxTags)
+	}
+	for _, tag := range tags {
+		if tag == "" {
+			return "tags must not be empty"
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLen {
+			return fmt.Sprintf("tag %q must be at most %d characters", tag, MaxTagLen)
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+				return fmt.Sprintf("tag %q may only contain letters, digits, '-' or '_'", tag)
+			}
+		}
+	}
+	return ""
+}
+
+// SetTagsRequest represents a request to replace a blog's tags
+// 空配列を送るとタグを全て外す。tagsの省略は誤りとして扱う
+type SetTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// Valid implements the Validator interface
+func (r SetTagsRequest) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if r.Tags == nil {
+		problems["tags"] = "tags is required"
+		return problems
+	}
+	addProblem(problems, "tags", tagsProblem(r.Tags))
+	return problems
+}
+
+// SetTags replaces the blog's tags, leaving the other fields untouched
+// タグが変わった場合のみ履歴に記録するが、更新日時は常に現在時刻に設定する
+// 戻り値はタグが変わったか
+func (b *Blog) SetTags(tags []string, opts ...Option) bool {
+	o := newOptions(opts)
+	now := time.Now().UTC()
+
+	tags = NormalizeTags(tags)
+	changed := !slices.Equal(tags, b.Tags)
+	if changed {
+		b.addRevision(BlogRevision{ChangedFields: []string{"tags"}, ChangedAt: now, Actor: o.actor}, o.maxRevisions)
+	}
+	b.Tags = tags
+	b.UpdatedAt = now
+	return changed
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "nil stays nil", tags: nil, want: nil},
+		{name: "trimmed and lower-cased", tags: []string{" Go ", "HTTP"}, want: []string{"go", "http"}},
+		{name: "duplicates dropped in order", tags: []string{"go", "api", "Go"}, want: []string{"go", "api"}},
+		{name: "non-ASCII letters kept", tags: []string{"日本語"}, want: []string{"日本語"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTags(tt.tags); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		tags  []string
+		extra []string
+		want  []string
+	}{
+		{name: "nothing to merge", tags: nil, extra: nil, want: nil},
+		{name: "extra appended", tags: []string{"go"}, extra: []string{"api"}, want: []string{"go", "api"}},
+		{name: "duplicates dropped", tags: []string{"Go"}, extra: []string{"go", "web"}, want: []string{"go", "web"}},
+		{
+			name:  "extra dropped beyond the limit",
+			tags:  strings.Split("a,b,c,d,e,f,g,h,i", ","),
+			extra: []string{"x", "y"},
+			want:  strings.Split("a,b,c,d,e,f,g,h,i,x", ","),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeTags(tt.tags, tt.extra); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetTagsRequest_Valid(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		wantErr bool
+	}{
+		{name: "valid tags", tags: []string{"go", "web-api", "日本語"}},
+		{name: "empty list clears tags", tags: []string{}},
+		{name: "missing tags", tags: nil, wantErr: true},
+		{name: "blank tag", tags: []string{"go", "  "}, wantErr: true},
+		{name: "tag with space", tags: []string{"web api"}, wantErr: true},
+		{name: "tag too long", tags: []string{strings.Repeat("a", MaxTagLen+1)}, wantErr: true},
+		{name: "too many tags", tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ","), wantErr: true},
+		{name: "duplicates do not count toward the limit", tags: strings.Split("a,b,c,d,e,f,g,h,i,j,A", ",")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := SetTagsRequest{Tags: tt.tags}.Valid(context.Background())
+			if _, got := problems["tags"]; got != tt.wantErr {
+				t.Errorf("expected tags problem %v, got %v", tt.wantErr, problems)
+			}
+		})
+	}
+}
+
+func TestBlog_SetTags(t *testing.T) {
+	blog := NewBlog(CreateBlogRequest{Title: "Title", Content: "Content", Author: "Author"})
+	blog.UpdatedAt = blog.UpdatedAt.Add(-time.Hour)
+	before := blog.UpdatedAt
+
+	blog.SetTags([]string{"Go", "api"}, WithActor("editor"))
+
+	if !slices.Equal(blog.Tags, []string{"go", "api"}) {
+		t.Errorf("expected normalized tags, got %v", blog.Tags)
+	}
+	if blog.Title != "Title" || blog.Content != "Content" {
+		t.Errorf("expected title and content unchanged, got %q %q", blog.Title, blog.Content)
+	}
+	if !blog.UpdatedAt.After(before) {
+		t.Error("expected UpdatedAt to advance")
+	}
+	if len(blog.Revisions) != 1 || !slices.Equal(blog.Revisions[0].ChangedFields, []string{"tags"}) {
+		t.Errorf("expected a tags revision, got %+v", blog.Revisions)
+	}
+
+	// 同じタグを設定しても履歴は増えない
+	blog.SetTags([]string{"go", "API"})
+	if len(blog.Revisions) != 1 {
+		t.Errorf("expected no revision for unchanged tags, got %d", len(blog.Revisions))
+	}
+}
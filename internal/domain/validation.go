@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ValidationConfig holds the operator-tunable rules used by the Valid methods
+// Validatorインターフェースはctxしか受け取らないため、コンテキスト経由で渡す
+// コンテキストに設定がない場合はDefaultValidationConfigが使われる
+type ValidationConfig struct {
+	MaxTitleLen   int
+	MaxContentLen int
+	MaxAuthorLen  int
+	// AllowedAuthors, when non-empty, is the only set of authors who may post
+	AllowedAuthors []string
+	// DeniedAuthors are never allowed to post
+	DeniedAuthors []string
+	// AllowedCategories, when non-empty, is the set of valid categories
+	AllowedCategories []string
+	// RequireCategory rejects posts without a category
+	RequireCategory bool
+	// AuthorNormalization is applied before matching authors against the lists
+	AuthorNormalization AuthorNormalization
+	// MaxProblems caps the problems kept by TruncateProblems (0 = unlimited)
+	MaxProblems int
+}
+
+// DefaultValidationConfig returns the built-in validation rules
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		MaxTitleLen:   100,
+		MaxContentLen: 5000,
+		MaxAuthorLen:  50,
+	}
+}
+
+type validationConfigKey struct{}
+
+// ContextWithValidationConfig returns a copy of ctx carrying the validation rules
+func ContextWithValidationConfig(ctx context.Context, cfg ValidationConfig) context.Context {
+	return context.WithValue(ctx, validationConfigKey{}, cfg)
+}
+
+// validationConfigFromContext returns the validation rules carried by ctx, or the defaults
+func validationConfigFromContext(ctx context.Context) ValidationConfig {
+	if cfg, ok := ctx.Value(validationConfigKey{}).(ValidationConfig); ok {
+		return cfg
+	}
+	return DefaultValidationConfig()
+}
+
+// TruncatedProblemsKey is the problems key noting how many problems TruncateProblems dropped
+// フィールド名と衝突しないよう、先頭にアンダースコアを付けている
+const TruncatedProblemsKey = "_truncated"
+
+// TruncateProblems keeps at most MaxProblems of the problems, in field name order,
+// and notes how many were dropped under TruncatedProblemsKey
+// フィールドが増えても、一つのリクエストに対するエラー応答が際限なく大きくならないようにする
+func TruncateProblems(ctx context.Context, problems map[string]string) map[string]string {
+	limit := validationConfigFromContext(ctx).MaxProblems
+	if limit <= 0 || len(problems) <= limit {
+		return problems
+	}
+	fields := slices.Sorted(maps.Keys(problems))
+	truncated := make(map[string]string, limit+1)
+	for _, field := range fields[:limit] {
+		truncated[field] = problems[field]
+	}
+	truncated[TruncatedProblemsKey] = fmt.Sprintf("%d more problems omitted", len(fields)-limit)
+	return truncated
+}
+
+// addProblem records problem for field, keeping any problem already recorded
+// 同じキーへの代入で先のメッセージが黙って上書きされないよう、独立した規則の違反は "; " で連結する
+func addProblem(problems map[string]string, field, problem string) {
+	if problem == "" {
+		return
+	}
+	if existing, ok := problems[field]; ok {
+		problems[field] = existing + "; " + problem
+		return
+	}
+	problems[field] = problem
+}
+
+// authorProblem returns why author may not post, or "" if it may
+func (c ValidationConfig) authorProblem(author string) string {
+	if len(c.AllowedAuthors) > 0 && !slices.Contains(c.AllowedAuthors, author) {
+		return "author is not on the allowlist"
+	}
+	if slices.Contains(c.DeniedAuthors, author) {
+		return "author is not allowed to post"
+	}
+	return ""
+}
+
+// categoryProblem returns why category is not acceptable, or "" if it is
+func (c ValidationConfig) categoryProblem(category string) string {
+	if category == "" {
+		if c.RequireCategory {
+			return "category is required"
+		}
+		return ""
+	}
+	if len(c.AllowedCategories) > 0 && !slices.Contains(c.AllowedCategories, category) {
+		return "category must be one of: " + strings.Join(c.AllowedCategories, ", ")
+	}
+	return ""
+}
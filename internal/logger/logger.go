@@ -6,6 +6,8 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"time"
 )
 
 // Logger wraps slog.Logger to provide a consistent logging interface
@@ -14,17 +16,84 @@ type Logger struct {
 	*slog.Logger
 }
 
+// Option configures optional Logger behaviour
+type Option func(*options)
+
+type options struct {
+	fallback  io.Writer
+	addSource bool
+}
+
+// WithFallback sets a secondary writer used when writing to the primary output fails
+// ディスクフルなどで出力先が書き込めなくなっても、重要なログを失わないようにする
+func WithFallback(w io.Writer) Option {
+	return func(o *options) {
+		o.fallback = w
+	}
+}
+
+// WithSource adds the caller's file and line to every log line under the "source" key
+// 本番ではスタック取得のコストを避けるため通常は無効にする
+func WithSource(enabled bool) Option {
+	return func(o *options) {
+		o.addSource = enabled
+	}
+}
+
 // New creates a new Logger with the specified output and level
-func New(output io.Writer, level slog.Level) *Logger {
-	opts := &slog.HandlerOptions{
-		Level: level,
+func New(output io.Writer, level slog.Level, opts ...Option) *Logger {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.fallback != nil {
+		output = &fallbackWriter{primary: output, fallback: o.fallback}
+	}
+
+	// レベル判定はlevelHandlerで行うため、JSONHandler自体は全レベルを出力する
+	handlerOpts := &slog.HandlerOptions{
+		Level:     slog.LevelDebug,
+		AddSource: o.addSource,
+	}
+	handler := &levelHandler{
+		Handler: slog.NewJSONHandler(output, handlerOpts),
+		level:   level,
 	}
-	handler := slog.NewJSONHandler(output, opts)
 	return &Logger{
 		Logger: slog.New(handler),
 	}
 }
 
+// levelKey is the context key for a per-request level override
+type levelKey struct{}
+
+// WithLevel returns a context whose log records use level instead of the Logger's own
+// 本番で特定のリクエストだけdebugログを出すために使う
+func WithLevel(ctx context.Context, level slog.Level) context.Context {
+	return context.WithValue(ctx, levelKey{}, level)
+}
+
+// levelHandler filters records by the Logger's level, or by the override from WithLevel
+type levelHandler struct {
+	slog.Handler
+	level slog.Level
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if override, ok := ctx.Value(levelKey{}).(slog.Level); ok {
+		return level >= override
+	}
+	return level >= h.level
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
+
 // NewDefault creates a new Logger with sensible defaults
 func NewDefault() *Logger {
 	return New(os.Stdout, slog.LevelInfo)
@@ -32,22 +101,36 @@ func NewDefault() *Logger {
 
 // Info logs an info message with key-value pairs
 func (l *Logger) Info(ctx context.Context, msg string, keysAndValues ...any) {
-	l.Logger.InfoContext(ctx, msg, keysAndValues...)
+	l.log(ctx, slog.LevelInfo, msg, keysAndValues...)
 }
 
 // Error logs an error message with key-value pairs
 func (l *Logger) Error(ctx context.Context, msg string, keysAndValues ...any) {
-	l.Logger.ErrorContext(ctx, msg, keysAndValues...)
+	l.log(ctx, slog.LevelError, msg, keysAndValues...)
 }
 
 // Debug logs a debug message with key-value pairs
 func (l *Logger) Debug(ctx context.Context, msg string, keysAndValues ...any) {
-	l.Logger.DebugContext(ctx, msg, keysAndValues...)
+	l.log(ctx, slog.LevelDebug, msg, keysAndValues...)
 }
 
 // Warn logs a warning message with key-value pairs
 func (l *Logger) Warn(ctx context.Context, msg string, keysAndValues ...any) {
-	l.Logger.WarnContext(ctx, msg, keysAndValues...)
+	l.log(ctx, slog.LevelWarn, msg, keysAndValues...)
+}
+
+// log emits a record attributed to the caller of Info/Error/Debug/Warn
+// slog.Logger経由だとsourceがこのラッパー自身を指してしまうため、
+// 呼び出し元のPCを取得してレコードを直接組み立てる
+func (l *Logger) log(ctx context.Context, level slog.Level, msg string, keysAndValues ...any) {
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // runtime.Callers, log, Info などのラッパーをスキップ
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(keysAndValues...)
+	_ = l.Handler().Handle(ctx, r)
 }
 
 // WithError adds an error to the logger context
@@ -78,4 +161,21 @@ func ParseLevel(level string) (slog.Level, error) {
 	default:
 		return slog.LevelInfo, fmt.Errorf("unknown level: %s", level)
 	}
-}
\ No newline at end of file
+}
+
+// fallbackWriter writes to primary and retries on fallback when primary fails
+// slogは書き込みエラーを呼び出し元に返さず行を破棄するため、io.Writerの層で検知する
+// primaryが途中までしか書き込めなかった場合も行全体をfallbackに書き込む
+// （残りだけを書くとどちらにも解析できる行が残らないため、断片の重複は許容する）
+type fallbackWriter struct {
+	primary  io.Writer
+	fallback io.Writer
+}
+
+func (w *fallbackWriter) Write(p []byte) (int, error) {
+	n, err := w.primary.Write(p)
+	if err == nil && n == len(p) {
+		return n, nil
+	}
+	return w.fallback.Write(p)
+}
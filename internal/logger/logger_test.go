@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// failingWriter always fails, simulating e.g. a full disk
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+func TestNew_WithFallback(t *testing.T) {
+	var fallback bytes.Buffer
+	log := New(failingWriter{}, slog.LevelInfo, WithFallback(&fallback))
+
+	log.Error(context.Background(), "critical failure", "component", "store")
+
+	output := fallback.String()
+	if !strings.Contains(output, "critical failure") {
+		t.Errorf("expected fallback output to contain log message, got %q", output)
+	}
+	if !strings.Contains(output, "store") {
+		t.Errorf("expected fallback output to contain attributes, got %q", output)
+	}
+}
+
+func TestNew_WithFallback_PrimaryHealthy(t *testing.T) {
+	var primary, fallback bytes.Buffer
+	log := New(&primary, slog.LevelInfo, WithFallback(&fallback))
+
+	log.Info(context.Background(), "all good")
+
+	if !strings.Contains(primary.String(), "all good") {
+		t.Errorf("expected primary output to contain log message, got %q", primary.String())
+	}
+	if fallback.Len() != 0 {
+		t.Errorf("expected no fallback output, got %q", fallback.String())
+	}
+}
+
+// partialWriter accepts at most limit bytes per write before failing
+type partialWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *partialWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.limit {
+		return w.buf.Write(p)
+	}
+	n, _ := w.buf.Write(p[:w.limit])
+	return n, errors.New("short write")
+}
+
+func TestFallbackWriter_PartialWrite(t *testing.T) {
+	primary := &partialWriter{limit: 4}
+	var fallback bytes.Buffer
+	w := &fallbackWriter{primary: primary, fallback: &fallback}
+
+	n, err := w.Write([]byte("hello world\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != len("hello world\n") {
+		t.Errorf("expected %d bytes written, got %d", len("hello world\n"), n)
+	}
+	// 途中までしか書き込めなかった場合も、fallbackには行全体が書き込まれる
+	if fallback.String() != "hello world\n" {
+		t.Errorf("expected the complete line on fallback, got %q", fallback.String())
+	}
+}
+
+func TestNew_WithSource(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantSource bool
+	}{
+		{name: "enabled", enabled: true, wantSource: true},
+		{name: "disabled", enabled: false, wantSource: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := New(&buf, slog.LevelInfo, WithSource(tt.enabled))
+
+			log.Info(context.Background(), "hello")
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("failed to parse log line: %v", err)
+			}
+			source, ok := entry[slog.SourceKey].(map[string]any)
+			if ok != tt.wantSource {
+				t.Fatalf("expected source present=%v, got %q", tt.wantSource, buf.String())
+			}
+			// sourceはラッパーではなく呼び出し元を指すこと
+			if ok {
+				if file, _ := source["file"].(string); !strings.HasSuffix(file, "logger_test.go") {
+					t.Errorf("expected source file to be the caller, got %q", file)
+				}
+			}
+		})
+	}
+}
+
+func TestWithLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, slog.LevelInfo).WithFields("component", "api")
+
+	log.Debug(context.Background(), "hidden")
+	log.Debug(WithLevel(context.Background(), slog.LevelDebug), "shown")
+	// レベルを上げる方向の上書きも効く
+	log.Info(WithLevel(context.Background(), slog.LevelError), "suppressed")
+
+	output := buf.String()
+	if strings.Contains(output, "hidden") || strings.Contains(output, "suppressed") {
+		t.Errorf("expected records below the level to be dropped, got %q", output)
+	}
+	if !strings.Contains(output, "shown") {
+		t.Errorf("expected debug record with the override, got %q", output)
+	}
+}
@@ -0,0 +1,298 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// ciphertextPrefix marks field values encrypted by EncryptedBlogStore
+// 暗号化導入前に保存された平文の値はプレフィックスがないため、そのまま読み出せる
+const ciphertextPrefix = "enc:v1:"
+
+// EncryptedBlogStore encrypts Content (and optionally Author) with AES-GCM before
+// delegating to the inner store, and decrypts them on read
+//
+// 制限事項:
+//   - 暗号文はランダムなnonceを含むため、作者の暗号化を有効にすると内側のストアで
+//     作者による検索ができない。GetByAuthorは全件を復号して絞り込む
+//   - Statsも全件を復号して集計する
+//   - ContentHashは平文のハッシュのまま保存されるため、保存先を読める者は同一本文の有無を推測できる
+//     （APIのレスポンスには含まれない）
+type EncryptedBlogStore struct {
+	inner         BlogStore
+	aead          cipher.AEAD
+	encryptAuthor bool
+}
+
+// NewEncryptedBlogStore wraps inner so that selected fields are encrypted at rest
+// keyは16/24/32バイト（AES-128/192/256）
+func NewEncryptedBlogStore(inner BlogStore, key []byte, encryptAuthor bool) (*EncryptedBlogStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create GCM: %w", err)
+	}
+	return &EncryptedBlogStore{
+		inner:         inner,
+		aead:          aead,
+		encryptAuthor: encryptAuthor,
+	}, nil
+}
+
+// Create encrypts the blog's fields and stores it
+// 呼び出し元のblogはレスポンスに使われるため、暗号化はコピーに対して行う
+func (s *EncryptedBlogStore) Create(ctx context.Context, blog *domain.Blog) error {
+	sealed, err := s.seal(blog)
+	if err != nil {
+		return err
+	}
+	return s.inner.Create(ctx, sealed)
+}
+
+// GetByID retrieves and decrypts a blog by its ID
+func (s *EncryptedBlogStore) GetByID(ctx context.Context, id string) (*domain.Blog, error) {
+	blog, err := s.inner.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(blog)
+}
+
+// GetAll retrieves and decrypts all blogs
+func (s *EncryptedBlogStore) GetAll(ctx context.Context) ([]*domain.Blog, error) {
+	blogs, err := s.inner.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.openAll(blogs)
+}
+
+// GetByAuthor retrieves all blogs by a specific author
+// 作者を暗号化している場合は全件を復号してから絞り込む
+func (s *EncryptedBlogStore) GetByAuthor(ctx context.Context, author string) ([]*domain.Blog, error) {
+	if !s.encryptAuthor {
+		blogs, err := s.inner.GetByAuthor(ctx, author)
+		if err != nil {
+			return nil, err
+		}
+		return s.openAll(blogs)
+	}
+
+	blogs, err := s.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(blogs, func(blog *domain.Blog) bool {
+		return blog.Author != author
+	}), nil
+}
+
+// GetByCategory retrieves and decrypts all blogs in a specific category
+func (s *EncryptedBlogStore) GetByCategory(ctx context.Context, category string) ([]*domain.Blog, error) {
+	blogs, err := s.inner.GetByCategory(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	return s.openAll(blogs)
+}
+
+// GetBySlug retrieves and decrypts a blog by its slug
+func (s *EncryptedBlogStore) GetBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	blog, err := s.inner.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(blog)
+}
+
+// SetSlug changes the slug of an existing blog
+func (s *EncryptedBlogStore) SetSlug(ctx context.Context, id, slug string) error {
+	return s.inner.SetSlug(ctx, id, slug)
+}
+
+// Recent retrieves and decrypts the n most recently created blogs
+func (s *EncryptedBlogStore) Recent(ctx context.Context, n int) ([]*domain.Blog, error) {
+	blogs, err := s.inner.Recent(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	return s.openAll(blogs)
+}
+
+// Update encrypts the blog's fields and updates it
+func (s *EncryptedBlogStore) Update(ctx context.Context, id string, blog *domain.Blog) error {
+	sealed, err := s.seal(blog)
+	if err != nil {
+		return err
+	}
+	return s.inner.Update(ctx, id, sealed)
+}
+
+// Delete removes a blog by its ID
+func (s *EncryptedBlogStore) Delete(ctx context.Context, id string) error {
+	return s.inner.Delete(ctx, id)
+}
+
+// Stats computes statistics over the decrypted blogs
+// 内側のストアの集計は暗号文の長さや暗号化された作者名に基づくため使えない
+func (s *EncryptedBlogStore) Stats(ctx context.Context) (domain.BlogStats, error) {
+	blogs, err := s.GetAll(ctx)
+	if err != nil {
+		return domain.BlogStats{}, err
+	}
+	return computeStats(slices.Values(blogs)), nil
+}
+
+// Each decrypts and visits every blog, streaming from the inner store if it is an Iterator
+func (s *EncryptedBlogStore) Each(ctx context.Context, fn func(blog *domain.Blog) error) error {
+	return each(ctx, s.inner, func(blog *domain.Blog) error {
+		opened, err := s.open(blog)
+		if err != nil {
+			return err
+		}
+		return fn(opened)
+	})
+}
+
+// DeleteIf removes a blog only if cond reports true for its decrypted state
+// 内側のストアが条件付き削除に対応していない場合はerrors.ErrUnsupportedを返す
+func (s *EncryptedBlogStore) DeleteIf(ctx context.Context, id string, cond func(blog *domain.Blog) bool) error {
+	deleter, ok := s.inner.(ConditionalDeleter)
+	if !ok {
+		return fmt.Errorf("conditional delete: %w", errors.ErrUnsupported)
+	}
+	var openErr error
+	err := deleter.DeleteIf(ctx, id, func(blog *domain.Blog) bool {
+		opened := *blog
+		if _, openErr = s.open(&opened); openErr != nil {
+			return false
+		}
+		return cond(&opened)
+	})
+	if openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+// DeleteExpired removes expired blogs from the inner store (ExpiresAt is not encrypted)
+func (s *EncryptedBlogStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	sweeper, ok := s.inner.(ExpirySweeper)
+	if !ok {
+		return 0, fmt.Errorf("delete expired blogs: %w", errors.ErrUnsupported)
+	}
+	return sweeper.DeleteExpired(ctx, now)
+}
+
+// Snapshot returns the inner store's snapshot
+// 本文（と作者）は暗号化されたまま含まれるため、同じ鍵のストアにのみ復元できる
+func (s *EncryptedBlogStore) Snapshot(ctx context.Context) ([]byte, error) {
+	snapshotter, ok := s.inner.(Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("snapshot: %w", errors.ErrUnsupported)
+	}
+	return snapshotter.Snapshot(ctx)
+}
+
+// Restore replaces the inner store's dataset with a snapshot taken by Snapshot
+func (s *EncryptedBlogStore) Restore(ctx context.Context, data []byte) error {
+	snapshotter, ok := s.inner.(Snapshotter)
+	if !ok {
+		return fmt.Errorf("restore: %w", errors.ErrUnsupported)
+	}
+	return snapshotter.Restore(ctx, data)
+}
+
+// Ping checks the inner store if it supports pinging
+func (s *EncryptedBlogStore) Ping(ctx context.Context) error {
+	if pinger, ok := s.inner.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// seal returns a copy of blog with the protected fields encrypted
+func (s *EncryptedBlogStore) seal(blog *domain.Blog) (*domain.Blog, error) {
+	sealed := *blog
+	content, err := s.encrypt(blog.Content)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt content: %w", err)
+	}
+	sealed.Content = content
+	if s.encryptAuthor {
+		author, err := s.encrypt(blog.Author)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt author: %w", err)
+		}
+		sealed.Author = author
+	}
+	return &sealed, nil
+}
+
+// open decrypts the protected fields of blog in place
+func (s *EncryptedBlogStore) open(blog *domain.Blog) (*domain.Blog, error) {
+	content, err := s.decrypt(blog.Content)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt content of blog %s: %w", blog.ID, err)
+	}
+	author, err := s.decrypt(blog.Author)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt author of blog %s: %w", blog.ID, err)
+	}
+	blog.Content = content
+	blog.Author = author
+	return blog, nil
+}
+
+func (s *EncryptedBlogStore) openAll(blogs []*domain.Blog) ([]*domain.Blog, error) {
+	for _, blog := range blogs {
+		if _, err := s.open(blog); err != nil {
+			return nil, err
+		}
+	}
+	return blogs, nil
+}
+
+// encrypt returns prefix + base64(nonce || ciphertext)
+func (s *EncryptedBlogStore) encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return ciphertextPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt reverses encrypt; values without the prefix are returned unchanged
+func (s *EncryptedBlogStore) decrypt(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, ciphertextPrefix)
+	if !ok {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+	plaintext, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, 32)
+
+func TestEncryptedBlogStore_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		encryptAuthor bool
+	}{
+		{name: "content only", encryptAuthor: false},
+		{name: "content and author", encryptAuthor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := NewMemoryBlogStore()
+			encrypted, err := NewEncryptedBlogStore(inner, testKey, tt.encryptAuthor)
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			ctx := context.Background()
+
+			blog := &domain.Blog{ID: "1", Title: "Title", Content: "secret content", Author: "Alice", CreatedAt: time.Now()}
+			if err := encrypted.Create(ctx, blog); err != nil {
+				t.Fatalf("failed to create: %v", err)
+			}
+			if blog.Content != "secret content" {
+				t.Errorf("expected caller's blog to stay plaintext, got %q", blog.Content)
+			}
+
+			// 内側のストアには暗号文が保存されていること
+			stored, _ := inner.GetByID(ctx, "1")
+			if !strings.HasPrefix(stored.Content, ciphertextPrefix) || strings.Contains(stored.Content, "secret") {
+				t.Errorf("expected ciphertext in inner store, got %q", stored.Content)
+			}
+			if got := strings.HasPrefix(stored.Author, ciphertextPrefix); got != tt.encryptAuthor {
+				t.Errorf("expected author encrypted=%v, got %q", tt.encryptAuthor, stored.Author)
+			}
+
+			got, err := encrypted.GetByID(ctx, "1")
+			if err != nil {
+				t.Fatalf("failed to get: %v", err)
+			}
+			if got.Content != "secret content" || got.Author != "Alice" {
+				t.Errorf("expected plaintext round trip, got content %q author %q", got.Content, got.Author)
+			}
+
+			byAuthor, err := encrypted.GetByAuthor(ctx, "Alice")
+			if err != nil {
+				t.Fatalf("failed to get by author: %v", err)
+			}
+			if len(byAuthor) != 1 || byAuthor[0].Content != "secret content" {
+				t.Errorf("expected 1 decrypted blog by author, got %v", byAuthor)
+			}
+
+			stats, err := encrypted.Stats(ctx)
+			if err != nil {
+				t.Fatalf("failed to get stats: %v", err)
+			}
+			if stats.BlogsPerAuthor["Alice"] != 1 || stats.AverageContentLength != float64(len("secret content")) {
+				t.Errorf("expected stats over plaintext, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestEncryptedBlogStore_PlaintextPassthrough(t *testing.T) {
+	inner := NewMemoryBlogStore()
+	ctx := context.Background()
+	// 暗号化導入前に保存された値
+	inner.Create(ctx, &domain.Blog{ID: "legacy", Content: "plain", Author: "Bob"})
+
+	encrypted, err := NewEncryptedBlogStore(inner, testKey, true)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	got, err := encrypted.GetByID(ctx, "legacy")
+	if err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	if got.Content != "plain" || got.Author != "Bob" {
+		t.Errorf("expected plaintext values to pass through, got %+v", got)
+	}
+}
+
+func TestEncryptedBlogStore_WrongKey(t *testing.T) {
+	inner := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	writer, _ := NewEncryptedBlogStore(inner, testKey, false)
+	writer.Create(ctx, &domain.Blog{ID: "1", Content: "secret"})
+
+	reader, _ := NewEncryptedBlogStore(inner, bytes.Repeat([]byte{0x24}, 32), false)
+	if _, err := reader.GetByID(ctx, "1"); err == nil {
+		t.Error("expected decryption with the wrong key to fail")
+	}
+}
+
+func TestNewEncryptedBlogStore_InvalidKey(t *testing.T) {
+	if _, err := NewEncryptedBlogStore(NewMemoryBlogStore(), []byte("short"), false); err == nil {
+		t.Error("expected error for invalid key length")
+	}
+}
+
+// basicStore hides every optional interface of the wrapped store
+type basicStore struct {
+	BlogStore
+}
+
+func TestEncryptedBlogStore_OptionalInterfaces(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemoryBlogStore()
+	encrypted, err := NewEncryptedBlogStore(inner, testKey, true)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	encrypted.Create(ctx, &domain.Blog{ID: "live", Content: "secret", Author: "Alice", CreatedAt: time.Now()})
+	encrypted.Create(ctx, &domain.Blog{ID: "expired", Content: "old", Author: "Alice", CreatedAt: past, ExpiresAt: &past})
+
+	// 期限切れの削除は内側のストアに転送される
+	if removed, err := encrypted.DeleteExpired(ctx, time.Now()); err != nil || removed != 1 {
+		t.Errorf("expected 1 expired blog removed, got %d, %v", removed, err)
+	}
+
+	var contents []string
+	encrypted.Each(ctx, func(blog *domain.Blog) error {
+		contents = append(contents, blog.Content+"/"+blog.Author)
+		return nil
+	})
+	if len(contents) != 1 || contents[0] != "secret/Alice" {
+		t.Errorf("expected Each to visit decrypted blogs, got %v", contents)
+	}
+
+	snapshot, err := encrypted.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("failed to snapshot: %v", err)
+	}
+	if bytes.Contains(snapshot, []byte("secret")) {
+		t.Errorf("expected the snapshot to keep content encrypted, got %s", snapshot)
+	}
+
+	// 条件には復号した状態が渡される
+	err = encrypted.DeleteIf(ctx, "live", func(blog *domain.Blog) bool { return blog.Content == "other" })
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if err := encrypted.DeleteIf(ctx, "live", func(blog *domain.Blog) bool { return blog.Content == "secret" }); err != nil {
+		t.Fatalf("expected conditional delete to succeed, got %v", err)
+	}
+
+	if err := encrypted.Restore(ctx, snapshot); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if got, err := encrypted.GetByID(ctx, "live"); err != nil || got.Content != "secret" {
+		t.Errorf("expected the blog restored and decrypted, got %v, %v", got, err)
+	}
+
+	t.Run("inner store without support", func(t *testing.T) {
+		encrypted, _ := NewEncryptedBlogStore(basicStore{NewMemoryBlogStore()}, testKey, false)
+		if _, err := encrypted.DeleteExpired(ctx, time.Now()); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("expected ErrUnsupported from DeleteExpired, got %v", err)
+		}
+		if err := encrypted.DeleteIf(ctx, "1", func(*domain.Blog) bool { return true }); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("expected ErrUnsupported from DeleteIf, got %v", err)
+		}
+		if _, err := encrypted.Snapshot(ctx); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("expected ErrUnsupported from Snapshot, got %v", err)
+		}
+		// Eachは全件の取得で代用する
+		if err := encrypted.Each(ctx, func(*domain.Blog) error { return nil }); err != nil {
+			t.Errorf("expected Each to fall back to GetAll, got %v", err)
+		}
+	})
+}
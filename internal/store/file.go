@@ -0,0 +1,416 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// blogFileExt is the extension of the per-blog files in a FileBlogStore directory
+const blogFileExt = ".json"
+
+// FileBlogStore keeps blogs in memory and writes every mutation through to
+// one JSON file per blog, reloading the directory when opened
+// 外部依存なしで再起動後もデータを残すための簡易的な永続化
+// スナップショットからの復元はディスクのファイルと食い違うため対応しない（Snapshotterを実装しない）
+//
+//   - 読み取りは内部のMemoryBlogStoreで処理し、ディスクは読まない
+//   - 書き込みはwriteMuで直列化し、メモリとファイルの更新を一つの操作として扱う
+//     ファイルへの書き込みに失敗した場合はメモリ側の変更を元に戻す
+//   - ファイルは一時ファイルへの書き込みとrenameで置き換えるため、途中で落ちても壊れたJSONは残らない
+//   - 同じディレクトリを複数のプロセスが開かないよう、ロックファイルを排他ロックする
+//   - 書き込みをまとめる設定（NewFileBlogStoreWithInterval）では、変更されたIDだけを記録し
+//     バックグラウンドで一定間隔ごとにまとめてファイルへ書き出す
+type FileBlogStore struct {
+	mem     *MemoryBlogStore
+	dir     string
+	writeMu sync.Mutex
+	lock    *os.File
+
+	// 以下は書き込みをまとめる場合のみ使用する（writeMuで保護）
+	interval time.Duration
+	// pendingは前回の書き出し以降に変更・削除されたブログのID
+	pending   map[string]struct{}
+	flushes   int
+	flushErr  error
+	stopFlush chan struct{}
+	flushDone chan struct{}
+}
+
+// NewFileBlogStore opens (creating if needed) dir and loads the blogs stored in it
+// 使い終わったらCloseでディレクトリのロックを解放すること
+func NewFileBlogStore(dir string) (*FileBlogStore, error) {
+	return NewFileBlogStoreWithInterval(dir, 0)
+}
+
+// NewFileBlogStoreWithInterval is like NewFileBlogStore but writes changes to
+// disk at most once per interval instead of on every mutation
+// 連続した書き込みでディスクを酷使しないためのもの。interval が0以下なら毎回書き込む
+// 書き出し前にプロセスが落ちると直近の変更は失われる。Closeで残りを書き出すこと
+func NewFileBlogStoreWithInterval(dir string, interval time.Duration) (*FileBlogStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create store directory: %w", err)
+	}
+	lock, err := lockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileBlogStore{
+		mem:  NewMemoryBlogStore(),
+		dir:  dir,
+		lock: lock,
+	}
+	if err := s.load(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	if interval > 0 {
+		s.interval = interval
+		s.pending = make(map[string]struct{})
+		s.stopFlush = make(chan struct{})
+		s.flushDone = make(chan struct{})
+		go s.runFlusher()
+	}
+	return s, nil
+}
+
+// load reads every blog file in the directory into memory
+func (s *FileBlogStore) load() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+blogFileExt))
+	if err != nil {
+		return fmt.Errorf("list blog files: %w", err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		stored := storedBlog{Blog: &domain.Blog{}}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		if err := s.mem.Create(context.Background(), stored.blog()); err != nil {
+			return fmt.Errorf("load %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Close writes out any pending changes and releases the directory lock
+func (s *FileBlogStore) Close() error {
+	var flushErr error
+	if s.stopFlush != nil {
+		close(s.stopFlush)
+		<-s.flushDone
+		s.stopFlush = nil
+		flushErr = s.flush()
+	}
+	if s.lock == nil {
+		return flushErr
+	}
+	err := unlockDir(s.lock)
+	s.lock = nil
+	return errors.Join(flushErr, err)
+}
+
+// runFlusher writes out pending changes every interval until Close
+func (s *FileBlogStore) runFlusher() {
+	defer close(s.flushDone)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopFlush:
+			return
+		case <-ticker.C:
+			// 失敗したIDはpendingに残り、次回再試行する。エラーはPingで報告される
+			s.flush()
+		}
+	}
+}
+
+// flush writes every pending blog to disk, removing the files of deleted ones
+func (s *FileBlogStore) flush() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+	var errs []error
+	for id := range s.pending {
+		blog, err := s.mem.GetByID(context.Background(), id)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			err = s.removeBlog(id)
+		case err == nil:
+			err = s.writeBlog(blog)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(s.pending, id)
+	}
+	s.flushes++
+	s.flushErr = errors.Join(errs...)
+	return s.flushErr
+}
+
+// persist writes blog to disk now, or marks it for the next flush when coalescing
+// 呼び出し側でwriteMuを保持していること
+func (s *FileBlogStore) persist(blog *domain.Blog) error {
+	if s.pending != nil {
+		s.pending[blog.ID] = struct{}{}
+		return nil
+	}
+	return s.writeBlog(blog)
+}
+
+// Create stores a new blog and writes it to disk
+func (s *FileBlogStore) Create(ctx context.Context, blog *domain.Blog) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	// 書き出しを後回しにする場合でも、ファイル名にできないIDはここで弾く
+	if _, err := s.blogPath(blog.ID); err != nil {
+		return err
+	}
+	if err := s.mem.Create(ctx, blog); err != nil {
+		return err
+	}
+	if err := s.persist(blog); err != nil {
+		s.mem.Delete(ctx, blog.ID)
+		return err
+	}
+	return nil
+}
+
+// SetUniqueSlugs makes Create and Update reject a slug already used by another blog
+// 読み込み済みのファイルは検査しないため、既存の重複があっても開くことはできる
+func (s *FileBlogStore) SetUniqueSlugs(enabled bool) {
+	s.mem.SetUniqueSlugs(enabled)
+}
+
+// SetDeduplicateContent makes Create reject a blog whose ContentHash matches an existing blog
+func (s *FileBlogStore) SetDeduplicateContent(enabled bool) {
+	s.mem.SetDeduplicateContent(enabled)
+}
+
+// SetSlug changes the slug of an existing blog and writes it to disk
+func (s *FileBlogStore) SetSlug(ctx context.Context, id, slug string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	previous, err := s.mem.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.mem.SetSlug(ctx, id, slug); err != nil {
+		return err
+	}
+	updated, err := s.mem.GetByID(ctx, id)
+	if err == nil {
+		err = s.persist(updated)
+	}
+	if err != nil {
+		s.mem.Update(ctx, id, previous)
+		return err
+	}
+	return nil
+}
+
+// Update updates an existing blog and writes it to disk
+func (s *FileBlogStore) Update(ctx context.Context, id string, blog *domain.Blog) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	previous, err := s.mem.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.mem.Update(ctx, id, blog); err != nil {
+		return err
+	}
+	if err := s.persist(blog); err != nil {
+		s.mem.Update(ctx, id, previous)
+		return err
+	}
+	return nil
+}
+
+// Delete removes a blog and its file
+func (s *FileBlogStore) Delete(ctx context.Context, id string) error {
+	return s.delete(ctx, id, func() error { return s.mem.Delete(ctx, id) })
+}
+
+// DeleteIf removes a blog and its file only if cond reports true for its current state
+func (s *FileBlogStore) DeleteIf(ctx context.Context, id string, cond func(blog *domain.Blog) bool) error {
+	return s.delete(ctx, id, func() error { return s.mem.DeleteIf(ctx, id, cond) })
+}
+
+// DeleteExpired removes every blog that has expired at now, with its file
+// ファイルの削除に失敗した投稿は次回の起動時に読み込まれるが、期限切れのため読み取りからは除外され、次の削除で再び対象になる
+func (s *FileBlogStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	removed := s.mem.deleteExpired(now)
+	var errs []error
+	for _, id := range removed {
+		if s.pending != nil {
+			s.pending[id] = struct{}{}
+			continue
+		}
+		if err := s.removeBlog(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(removed), errors.Join(errs...)
+}
+
+// delete removes the blog from memory with del, then removes its file
+func (s *FileBlogStore) delete(ctx context.Context, id string, del func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	previous, err := s.mem.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := del(); err != nil {
+		return err
+	}
+	if s.pending != nil {
+		s.pending[id] = struct{}{}
+		return nil
+	}
+	if err := s.removeBlog(id); err != nil {
+		s.mem.Create(ctx, previous)
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a blog by its ID
+func (s *FileBlogStore) GetByID(ctx context.Context, id string) (*domain.Blog, error) {
+	return s.mem.GetByID(ctx, id)
+}
+
+// GetAll retrieves all blogs
+func (s *FileBlogStore) GetAll(ctx context.Context) ([]*domain.Blog, error) {
+	return s.mem.GetAll(ctx)
+}
+
+// Each calls fn with a copy of every blog, in no particular order
+func (s *FileBlogStore) Each(ctx context.Context, fn func(blog *domain.Blog) error) error {
+	return s.mem.Each(ctx, fn)
+}
+
+// GetByAuthor retrieves all blogs by a specific author
+func (s *FileBlogStore) GetByAuthor(ctx context.Context, author string) ([]*domain.Blog, error) {
+	return s.mem.GetByAuthor(ctx, author)
+}
+
+// AuthorContentBytes returns the total content length, in bytes, of the author's blogs
+func (s *FileBlogStore) AuthorContentBytes(ctx context.Context, author string) (int, error) {
+	return s.mem.AuthorContentBytes(ctx, author)
+}
+
+// GetByCategory retrieves all blogs in a specific category
+func (s *FileBlogStore) GetByCategory(ctx context.Context, category string) ([]*domain.Blog, error) {
+	return s.mem.GetByCategory(ctx, category)
+}
+
+// GetBySlug retrieves a blog by its slug
+func (s *FileBlogStore) GetBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	return s.mem.GetBySlug(ctx, slug)
+}
+
+// Recent retrieves the n most recently created blogs, newest first
+func (s *FileBlogStore) Recent(ctx context.Context, n int) ([]*domain.Blog, error) {
+	return s.mem.Recent(ctx, n)
+}
+
+// Stats computes aggregate statistics over all blogs
+func (s *FileBlogStore) Stats(ctx context.Context) (domain.BlogStats, error) {
+	return s.mem.Stats(ctx)
+}
+
+// Ping checks that the store directory is still accessible and that the last flush succeeded
+func (s *FileBlogStore) Ping(ctx context.Context) error {
+	if _, err := os.Stat(s.dir); err != nil {
+		return fmt.Errorf("store directory: %w", err)
+	}
+	s.writeMu.Lock()
+	flushErr := s.flushErr
+	s.writeMu.Unlock()
+	if flushErr != nil {
+		return fmt.Errorf("flush pending changes: %w", flushErr)
+	}
+	return ctx.Err()
+}
+
+// writeBlog atomically replaces the blog's file
+func (s *FileBlogStore) writeBlog(blog *domain.Blog) error {
+	path, err := s.blogPath(blog.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(newStoredBlog(blog), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal blog: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write blog file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync blog file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close blog file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace blog file: %w", err)
+	}
+	return nil
+}
+
+// removeBlog removes the blog's file; a file that is already gone is not an error
+func (s *FileBlogStore) removeBlog(id string) error {
+	path, err := s.blogPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove blog file: %w", err)
+	}
+	return nil
+}
+
+// blogPath returns the file holding the blog with id
+// IDはクライアントが指定できるため、ディレクトリ外を指すパスにならないことを確認する
+func (s *FileBlogStore) blogPath(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("blog ID %q cannot be used as a file name", id)
+	}
+	return filepath.Join(s.dir, id+blogFileExt), nil
+}
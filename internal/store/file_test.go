@@ -0,0 +1,225 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+func TestFileBlogStore_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	s, err := NewFileBlogStore(dir)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, id := range []string{"kept", "updated", "deleted"} {
+		blog := &domain.Blog{ID: id, Title: id, Content: "Content", Author: "Author", Tags: []string{"go"}, ContentHash: "hash-" + id, CreatedAt: now, UpdatedAt: now,
+			Revisions: []domain.BlogRevision{{ChangedFields: []string{"title"}, ChangedAt: now, Actor: "editor"}}}
+		if err := s.Create(ctx, blog); err != nil {
+			t.Fatalf("failed to create %s: %v", id, err)
+		}
+	}
+	if err := s.Update(ctx, "updated", &domain.Blog{ID: "updated", Title: "Updated", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if err := s.SetSlug(ctx, "kept", "kept-slug"); err != nil {
+		t.Fatalf("failed to set slug: %v", err)
+	}
+	if err := s.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	// 同じディレクトリを開き直すと、変更が全て反映されていること
+	reopened, err := NewFileBlogStore(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	blogs, _ := reopened.GetAll(ctx)
+	ids := make([]string, 0, len(blogs))
+	for _, blog := range blogs {
+		ids = append(ids, blog.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"kept", "updated"}) {
+		t.Fatalf("expected kept and updated blogs after reopen, got %v", ids)
+	}
+
+	kept, _ := reopened.GetByID(ctx, "kept")
+	if kept.Slug != "kept-slug" || !slices.Equal(kept.Tags, []string{"go"}) || !kept.CreatedAt.Equal(now) {
+		t.Errorf("expected kept blog to round-trip, got %+v", kept)
+	}
+	// ContentHashはAPIのJSONには出ないが、ファイルには保存される
+	if kept.ContentHash != "hash-kept" {
+		t.Errorf("expected the content hash to survive a reopen, got %q", kept.ContentHash)
+	}
+	if len(kept.Revisions) != 1 || kept.Revisions[0].Actor != "editor" {
+		t.Errorf("expected the revisions to survive a reopen, got %+v", kept.Revisions)
+	}
+	updated, _ := reopened.GetByID(ctx, "updated")
+	if updated.Title != "Updated" {
+		t.Errorf("expected updated title, got %q", updated.Title)
+	}
+}
+
+func TestFileBlogStore_InvalidID(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileBlogStore(dir)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	// ファイルに書けないIDはメモリにも残さない
+	if err := s.Create(ctx, &domain.Blog{ID: "../escape"}); err == nil {
+		t.Fatal("expected error for an ID that is not a file name")
+	}
+	if _, err := s.GetByID(ctx, "../escape"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected failed create to be rolled back, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no file outside the store directory, got %v", err)
+	}
+}
+
+func TestFileBlogStore_Locked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory locking is not supported on this platform")
+	}
+	dir := t.TempDir()
+	s, err := NewFileBlogStore(dir)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := NewFileBlogStore(dir); err == nil {
+		t.Error("expected second open of a locked directory to fail")
+	}
+}
+
+func TestFileBlogStore_CoalescedWrites(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// 間隔を長くとり、テスト中にバックグラウンドの書き出しが走らないようにする
+	s, err := NewFileBlogStoreWithInterval(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	now := time.Now().UTC()
+	blog := &domain.Blog{ID: "1", Title: "Title 0", CreatedAt: now, UpdatedAt: now}
+	if err := s.Create(ctx, blog); err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	for i := 1; i <= 100; i++ {
+		updated := *blog
+		updated.Title = fmt.Sprintf("Title %d", i)
+		if err := s.Update(ctx, "1", &updated); err != nil {
+			t.Fatalf("failed to update: %v", err)
+		}
+	}
+	s.Create(ctx, &domain.Blog{ID: "short-lived", CreatedAt: now})
+	s.Delete(ctx, "short-lived")
+
+	// 読み取りはメモリから返るが、ディスクにはまだ何も書かれていない
+	if got, _ := s.GetByID(ctx, "1"); got.Title != "Title 100" {
+		t.Errorf("expected latest title from memory, got %q", got.Title)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*"+blogFileExt)); len(files) != 0 {
+		t.Errorf("expected no files before the flush, got %v", files)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	if s.flushes != 1 {
+		t.Errorf("expected a single flush on close, got %d", s.flushes)
+	}
+
+	reopened, err := NewFileBlogStore(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+	blogs, _ := reopened.GetAll(ctx)
+	if len(blogs) != 1 || blogs[0].Title != "Title 100" {
+		t.Errorf("expected only the final state to be persisted, got %+v", blogs)
+	}
+}
+
+func TestFileBlogStore_BackgroundFlush(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileBlogStoreWithInterval(dir, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Create(context.Background(), &domain.Blog{ID: "1", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	path := filepath.Join(dir, "1"+blogFileExt)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the background flusher to write the blog")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileBlogStore_DeleteExpired(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	s, err := NewFileBlogStore(dir)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	s.Create(ctx, &domain.Blog{ID: "expired", CreatedAt: past, ExpiresAt: &past})
+	s.Create(ctx, &domain.Blog{ID: "live", CreatedAt: time.Now()})
+
+	removed, err := s.DeleteExpired(ctx, time.Now())
+	if err != nil || removed != 1 {
+		t.Fatalf("expected 1 expired blog removed, got %d, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "expired"+blogFileExt)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the expired blog's file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "live"+blogFileExt)); err != nil {
+		t.Errorf("expected the live blog's file to remain, got %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	// 開き直しても削除した投稿は戻らない
+	reopened, err := NewFileBlogStore(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+	if removed, _ := reopened.DeleteExpired(ctx, time.Now()); removed != 0 {
+		t.Errorf("expected nothing left to remove after reopen, got %d", removed)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockDir opens dir's lock file without locking it
+// flockのないプラットフォームでは、同じディレクトリを複数のプロセスで開かないよう運用で担保する
+func lockDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, ".lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file: %w", err)
+	}
+	return f, nil
+}
+
+// unlockDir closes a file opened by lockDir
+func unlockDir(f *os.File) error {
+	return f.Close()
+}
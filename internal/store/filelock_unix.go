@@ -0,0 +1,37 @@
+//go:build unix
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockDir takes an exclusive, non-blocking lock on dir's lock file
+// プロセスが落ちるとOSがロックを解放するため、古いロックファイルが残っても問題ない
+func lockDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, ".lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("store directory %s is in use by another process", dir)
+		}
+		return nil, fmt.Errorf("lock store directory: %w", err)
+	}
+	return f, nil
+}
+
+// unlockDir releases a lock taken by lockDir
+func unlockDir(f *os.File) error {
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
+		f.Close()
+		return fmt.Errorf("unlock store directory: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,311 @@
+package store
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// HTTPBlogStore is a BlogStore that proxies to another blog-api-server through its public API
+// あるサーバーを別のサーバーの前段に置く構成（フェデレーションや結合テスト）のためのもの
+// 接続先は既定のレスポンス形式（JSON_FIELD_CASE=snake、RESPONSE_ENVELOPE=false）で動かすこと
+//
+// 一覧系の読み取りは、ページングや作者名の正規化がかからない /api/v1/blogs/export から
+// 全件を取得して手元で絞り込む。件数の多い接続先では遅くなる点に注意
+// 本文の重複投稿の拒否は接続先のDEDUPLICATE_CONTENTに従う
+type HTTPBlogStore struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPBlogStore creates a store proxying to the server at baseURL (e.g. http://blog.internal:8080)
+// clientがnilの場合はhttp.DefaultClientを使う。タイムアウトはclient側で設定すること
+func NewHTTPBlogStore(baseURL string, client *http.Client) *HTTPBlogStore {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPBlogStore{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+// remoteError is the error body returned by the remote server
+type remoteError struct {
+	Error string `json:"error"`
+}
+
+// do sends a request with body encoded as JSON (if non-nil) and returns the response
+// 2xx以外のステータスはstatusErrorで対応するストアのエラーに変換する。成功時は呼び出し側がBodyを閉じること
+func (s *HTTPBlogStore) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, statusError(method, path, resp)
+	}
+	return resp, nil
+}
+
+// statusError maps an unsuccessful response to the matching store error
+// 409は作成時のID重複、スラッグの衝突、本文の重複に使われるため、エラーメッセージで区別する
+func statusError(method, path string, resp *http.Response) error {
+	var body remoteError
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		switch body.Error {
+		case "Blog already exists":
+			return ErrAlreadyExists
+		case "Slug is in use, please retry":
+			return ErrSlugConflict
+		case "Duplicate blog post":
+			return ErrDuplicateContent
+		}
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case http.StatusInsufficientStorage:
+		return ErrCapacityExceeded
+	}
+	if body.Error != "" {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, body.Error)
+	}
+	return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+}
+
+// decodeResponse decodes the JSON body of resp into v and closes it
+func decodeResponse(resp *http.Response, v any) error {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// remoteBlogPath returns the path of the blog with id on the remote server
+func remoteBlogPath(id string) string {
+	return "/api/v1/blogs/" + url.PathEscape(id)
+}
+
+// Create creates the blog on the remote server under the same ID
+// スラッグやタイムスタンプは接続先が決めるため、作成後のBlogでblogを置き換える
+func (s *HTTPBlogStore) Create(ctx context.Context, blog *domain.Blog) error {
+	req := domain.CreateBlogRequest{
+		ID:        blog.ID,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		Author:    blog.Author,
+		AuthorID:  blog.AuthorID,
+		Category:  blog.Category,
+		Tags:      blog.Tags,
+		ExpiresAt: blog.ExpiresAt,
+	}
+	resp, err := s.do(ctx, http.MethodPost, "/api/v1/blogs", req)
+	if err != nil {
+		return err
+	}
+	var created domain.Blog
+	if err := decodeResponse(resp, &created); err != nil {
+		return err
+	}
+	*blog = created
+	return nil
+}
+
+// GetByID retrieves a blog by its ID
+// 接続先はIDで見つからない場合にスラッグでも検索するため、IDが一致しない結果は見つからなかったものとする
+func (s *HTTPBlogStore) GetByID(ctx context.Context, id string) (*domain.Blog, error) {
+	resp, err := s.do(ctx, http.MethodGet, remoteBlogPath(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var blog domain.Blog
+	if err := decodeResponse(resp, &blog); err != nil {
+		return nil, err
+	}
+	if blog.ID != id {
+		return nil, ErrNotFound
+	}
+	return &blog, nil
+}
+
+// Each calls fn with every blog streamed from the remote export, in ID order
+func (s *HTTPBlogStore) Each(ctx context.Context, fn func(blog *domain.Blog) error) error {
+	resp, err := s.do(ctx, http.MethodGet, "/api/v1/blogs/export", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var blog domain.Blog
+		if err := dec.Decode(&blog); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("decode export: %w", err)
+		}
+		if err := fn(&blog); err != nil {
+			return err
+		}
+	}
+}
+
+// collect returns the blogs for which keep reports true
+func (s *HTTPBlogStore) collect(ctx context.Context, keep func(blog *domain.Blog) bool) ([]*domain.Blog, error) {
+	var blogs []*domain.Blog
+	err := s.Each(ctx, func(blog *domain.Blog) error {
+		if keep(blog) {
+			blogs = append(blogs, blog)
+		}
+		return nil
+	})
+	return blogs, err
+}
+
+// GetAll retrieves all blogs
+func (s *HTTPBlogStore) GetAll(ctx context.Context) ([]*domain.Blog, error) {
+	return s.collect(ctx, func(*domain.Blog) bool { return true })
+}
+
+// GetByAuthor retrieves all blogs by a specific author
+func (s *HTTPBlogStore) GetByAuthor(ctx context.Context, author string) ([]*domain.Blog, error) {
+	return s.collect(ctx, func(blog *domain.Blog) bool { return blog.Author == author })
+}
+
+// GetByCategory retrieves all blogs in a specific category
+func (s *HTTPBlogStore) GetByCategory(ctx context.Context, category string) ([]*domain.Blog, error) {
+	return s.collect(ctx, func(blog *domain.Blog) bool { return blog.Category == category })
+}
+
+// GetBySlug retrieves a blog by its slug
+func (s *HTTPBlogStore) GetBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	blogs, err := s.collect(ctx, func(blog *domain.Blog) bool { return blog.Slug == slug })
+	if err != nil {
+		return nil, err
+	}
+	if len(blogs) == 0 {
+		return nil, ErrNotFound
+	}
+	return blogs[0], nil
+}
+
+// SetSlug is not supported: the remote API can only regenerate a slug from the title
+func (s *HTTPBlogStore) SetSlug(ctx context.Context, id, slug string) error {
+	return fmt.Errorf("set slug on remote store: %w", errors.ErrUnsupported)
+}
+
+// Recent retrieves the n most recently created blogs, newest first
+func (s *HTTPBlogStore) Recent(ctx context.Context, n int) ([]*domain.Blog, error) {
+	blogs, err := s.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(blogs, func(a, b *domain.Blog) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if n < len(blogs) {
+		blogs = blogs[:max(n, 0)]
+	}
+	return blogs, nil
+}
+
+// updateRequest is the body of PUT /api/v1/blogs/{id}
+type updateRequest struct {
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Category string `json:"category"`
+}
+
+// Update sends the blog's title, content and category, then its tags if they differ
+// 接続先のAPIは作者を変更できず、タグは別のエンドポイントで置き換えるため、2回に分けて送る（アトミックではない）
+// 更新日時と履歴は接続先が記録する
+func (s *HTTPBlogStore) Update(ctx context.Context, id string, blog *domain.Blog) error {
+	resp, err := s.do(ctx, http.MethodPut, remoteBlogPath(id), updateRequest{Title: blog.Title, Content: blog.Content, Category: blog.Category})
+	if err != nil {
+		return err
+	}
+	var updated domain.Blog
+	if err := decodeResponse(resp, &updated); err != nil {
+		return err
+	}
+	if slices.Equal(updated.Tags, blog.Tags) {
+		return nil
+	}
+
+	tags := blog.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	resp, err = s.do(ctx, http.MethodPut, remoteBlogPath(id)+"/tags", domain.SetTagsRequest{Tags: tags})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete removes a blog by its ID
+func (s *HTTPBlogStore) Delete(ctx context.Context, id string) error {
+	resp, err := s.do(ctx, http.MethodDelete, remoteBlogPath(id), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Stats returns the remote server's statistics
+func (s *HTTPBlogStore) Stats(ctx context.Context) (domain.BlogStats, error) {
+	resp, err := s.do(ctx, http.MethodGet, "/api/v1/stats", nil)
+	if err != nil {
+		return domain.BlogStats{}, err
+	}
+	var stats domain.BlogStats
+	err = decodeResponse(resp, &stats)
+	return stats, err
+}
+
+// Ping checks that the remote server answers its health check
+func (s *HTTPBlogStore) Ping(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodGet, "/healthz", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// ReplicatedBlogStore sends writes to Primary and serves reads from Replica
+// 読み取り負荷をレプリカに逃がすための構成
+// レプリカがエラーを返した場合はPrimaryから読み直す
+// ErrNotFoundもレプリケーション遅延で作成直後のブログが見えないだけの可能性があるため、同様にPrimaryを確認する
+type ReplicatedBlogStore struct {
+	Primary BlogStore
+	Replica BlogStore
+}
+
+// read runs query against the replica, retrying on the primary if it fails
+func read[T any](ctx context.Context, s *ReplicatedBlogStore, query func(BlogStore) (T, error)) (T, error) {
+	v, err := query(s.Replica)
+	if err == nil || ctx.Err() != nil {
+		return v, err
+	}
+	return query(s.Primary)
+}
+
+// Create stores a new blog in the primary
+func (s *ReplicatedBlogStore) Create(ctx context.Context, blog *domain.Blog) error {
+	return s.Primary.Create(ctx, blog)
+}
+
+// GetByID retrieves a blog by its ID
+func (s *ReplicatedBlogStore) GetByID(ctx context.Context, id string) (*domain.Blog, error) {
+	return read(ctx, s, func(bs BlogStore) (*domain.Blog, error) { return bs.GetByID(ctx, id) })
+}
+
+// GetAll retrieves all blogs
+func (s *ReplicatedBlogStore) GetAll(ctx context.Context) ([]*domain.Blog, error) {
+	return read(ctx, s, func(bs BlogStore) ([]*domain.Blog, error) { return bs.GetAll(ctx) })
+}
+
+// GetByAuthor retrieves all blogs by a specific author
+func (s *ReplicatedBlogStore) GetByAuthor(ctx context.Context, author string) ([]*domain.Blog, error) {
+	return read(ctx, s, func(bs BlogStore) ([]*domain.Blog, error) { return bs.GetByAuthor(ctx, author) })
+}
+
+// GetByCategory retrieves all blogs in a specific category
+func (s *ReplicatedBlogStore) GetByCategory(ctx context.Context, category string) ([]*domain.Blog, error) {
+	return read(ctx, s, func(bs BlogStore) ([]*domain.Blog, error) { return bs.GetByCategory(ctx, category) })
+}
+
+// GetBySlug retrieves a blog by its slug
+func (s *ReplicatedBlogStore) GetBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	return read(ctx, s, func(bs BlogStore) (*domain.Blog, error) { return bs.GetBySlug(ctx, slug) })
+}
+
+// SetSlug changes the slug of an existing blog in the primary
+func (s *ReplicatedBlogStore) SetSlug(ctx context.Context, id, slug string) error {
+	return s.Primary.SetSlug(ctx, id, slug)
+}
+
+// Recent retrieves the n most recently created blogs
+func (s *ReplicatedBlogStore) Recent(ctx context.Context, n int) ([]*domain.Blog, error) {
+	return read(ctx, s, func(bs BlogStore) ([]*domain.Blog, error) { return bs.Recent(ctx, n) })
+}
+
+// Update updates an existing blog in the primary
+func (s *ReplicatedBlogStore) Update(ctx context.Context, id string, blog *domain.Blog) error {
+	return s.Primary.Update(ctx, id, blog)
+}
+
+// Delete removes a blog from the primary
+func (s *ReplicatedBlogStore) Delete(ctx context.Context, id string) error {
+	return s.Primary.Delete(ctx, id)
+}
+
+// Stats computes statistics from the replica
+func (s *ReplicatedBlogStore) Stats(ctx context.Context) (domain.BlogStats, error) {
+	return read(ctx, s, func(bs BlogStore) (domain.BlogStats, error) { return bs.Stats(ctx) })
+}
+
+// Each visits every blog of the replica, retrying on the primary if it fails before visiting any
+// fnを一度でも呼んだ後に失敗した場合は、重複して呼ばないようそのままエラーを返す
+func (s *ReplicatedBlogStore) Each(ctx context.Context, fn func(blog *domain.Blog) error) error {
+	visited := false
+	err := each(ctx, s.Replica, func(blog *domain.Blog) error {
+		visited = true
+		return fn(blog)
+	})
+	if err == nil || visited || ctx.Err() != nil {
+		return err
+	}
+	return each(ctx, s.Primary, fn)
+}
+
+// DeleteIf removes a blog from the primary only if cond reports true for its current state
+// Primaryが条件付き削除に対応していない場合はerrors.ErrUnsupportedを返す
+func (s *ReplicatedBlogStore) DeleteIf(ctx context.Context, id string, cond func(blog *domain.Blog) bool) error {
+	deleter, ok := s.Primary.(ConditionalDeleter)
+	if !ok {
+		return fmt.Errorf("conditional delete: %w", errors.ErrUnsupported)
+	}
+	return deleter.DeleteIf(ctx, id, cond)
+}
+
+// DeleteExpired removes expired blogs from the primary
+func (s *ReplicatedBlogStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	sweeper, ok := s.Primary.(ExpirySweeper)
+	if !ok {
+		return 0, fmt.Errorf("delete expired blogs: %w", errors.ErrUnsupported)
+	}
+	return sweeper.DeleteExpired(ctx, now)
+}
+
+// Snapshot returns the primary's snapshot
+func (s *ReplicatedBlogStore) Snapshot(ctx context.Context) ([]byte, error) {
+	snapshotter, ok := s.Primary.(Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("snapshot: %w", errors.ErrUnsupported)
+	}
+	return snapshotter.Snapshot(ctx)
+}
+
+// Restore replaces the primary's dataset with a snapshot
+// レプリカへの反映はレプリケーションに任せる
+func (s *ReplicatedBlogStore) Restore(ctx context.Context, data []byte) error {
+	snapshotter, ok := s.Primary.(Snapshotter)
+	if !ok {
+		return fmt.Errorf("restore: %w", errors.ErrUnsupported)
+	}
+	return snapshotter.Restore(ctx, data)
+}
+
+// Ping checks both stores that support pinging
+func (s *ReplicatedBlogStore) Ping(ctx context.Context) error {
+	var errs []error
+	for _, bs := range []BlogStore{s.Primary, s.Replica} {
+		if pinger, ok := bs.(Pinger); ok {
+			errs = append(errs, pinger.Ping(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
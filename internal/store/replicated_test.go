@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moko-poi/blog-api-server/internal/domain"
+)
+
+// unavailableStore fails every read, standing in for an unreachable replica
+type unavailableStore struct {
+	BlogStore
+	err error
+}
+
+func (s *unavailableStore) GetByID(ctx context.Context, id string) (*domain.Blog, error) {
+	return nil, s.err
+}
+
+func (s *unavailableStore) GetAll(ctx context.Context) ([]*domain.Blog, error) {
+	return nil, s.err
+}
+
+func TestReplicatedBlogStore_Routing(t *testing.T) {
+	ctx := context.Background()
+	primary := NewMemoryBlogStore()
+	replica := NewMemoryBlogStore()
+	replicated := &ReplicatedBlogStore{Primary: primary, Replica: replica}
+
+	// レプリカにだけ存在するデータで、読み取りがレプリカに向かうことを確認する
+	replica.Create(ctx, &domain.Blog{ID: "replica-only", Title: "Replica", CreatedAt: time.Now()})
+
+	blogs, err := replicated.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all: %v", err)
+	}
+	if len(blogs) != 1 || blogs[0].ID != "replica-only" {
+		t.Errorf("expected reads from the replica, got %v", blogs)
+	}
+
+	blog := &domain.Blog{ID: "1", Title: "Title", Content: "Content", Author: "Author", CreatedAt: time.Now()}
+	if err := replicated.Create(ctx, blog); err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	if _, err := primary.GetByID(ctx, "1"); err != nil {
+		t.Errorf("expected create to reach the primary, got %v", err)
+	}
+	if _, err := replica.GetByID(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected create not to reach the replica, got %v", err)
+	}
+
+	updated := *blog
+	updated.Title = "Updated"
+	if err := replicated.Update(ctx, "1", &updated); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if got, _ := primary.GetByID(ctx, "1"); got.Title != "Updated" {
+		t.Errorf("expected update on the primary, got %q", got.Title)
+	}
+
+	if err := replicated.Delete(ctx, "replica-only"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected delete to go to the primary, got %v", err)
+	}
+}
+
+func TestReplicatedBlogStore_Fallback(t *testing.T) {
+	ctx := context.Background()
+	primary := NewMemoryBlogStore()
+	primary.Create(ctx, &domain.Blog{ID: "1", Title: "Title", CreatedAt: time.Now()})
+
+	tests := []struct {
+		name    string
+		replica BlogStore
+	}{
+		{
+			name:    "replica error",
+			replica: &unavailableStore{BlogStore: NewMemoryBlogStore(), err: errors.New("connection refused")},
+		},
+		{
+			// 作成直後でレプリカに未反映のケース
+			name:    "replica lagging",
+			replica: NewMemoryBlogStore(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replicated := &ReplicatedBlogStore{Primary: primary, Replica: tt.replica}
+
+			blog, err := replicated.GetByID(ctx, "1")
+			if err != nil {
+				t.Fatalf("expected fallback to the primary, got %v", err)
+			}
+			if blog.ID != "1" {
+				t.Errorf("expected blog 1, got %s", blog.ID)
+			}
+
+			if _, err := replicated.GetByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound when neither store has the blog, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReplicatedBlogStore_OptionalInterfaces(t *testing.T) {
+	ctx := context.Background()
+	primary := NewMemoryBlogStore()
+	replica := NewMemoryBlogStore()
+	replicated := &ReplicatedBlogStore{Primary: primary, Replica: replica}
+
+	past := time.Now().Add(-time.Hour)
+	primary.Create(ctx, &domain.Blog{ID: "expired", CreatedAt: past, ExpiresAt: &past})
+	primary.Create(ctx, &domain.Blog{ID: "live", Title: "Title", CreatedAt: time.Now()})
+	replica.Create(ctx, &domain.Blog{ID: "replica-only", CreatedAt: time.Now()})
+
+	// 書き込みに当たる操作はPrimaryに向かう
+	if removed, err := replicated.DeleteExpired(ctx, time.Now()); err != nil || removed != 1 {
+		t.Errorf("expected 1 expired blog removed from the primary, got %d, %v", removed, err)
+	}
+	if err := replicated.DeleteIf(ctx, "replica-only", func(*domain.Blog) bool { return true }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected conditional delete on the primary, got %v", err)
+	}
+	snapshot, err := replicated.Snapshot(ctx)
+	if err != nil || !strings.Contains(string(snapshot), `"live"`) || strings.Contains(string(snapshot), "replica-only") {
+		t.Errorf("expected the primary's snapshot, got %s, %v", snapshot, err)
+	}
+
+	// Eachはレプリカを走査し、失敗した場合はPrimaryで走査し直す
+	var ids []string
+	replicated.Each(ctx, func(blog *domain.Blog) error {
+		ids = append(ids, blog.ID)
+		return nil
+	})
+	if len(ids) != 1 || ids[0] != "replica-only" {
+		t.Errorf("expected Each to visit the replica, got %v", ids)
+	}
+	failing := &ReplicatedBlogStore{Primary: primary, Replica: &unavailableStore{BlogStore: replica, err: errors.New("replica down")}}
+	ids = nil
+	if err := failing.Each(ctx, func(blog *domain.Blog) error {
+		ids = append(ids, blog.ID)
+		return nil
+	}); err != nil || len(ids) != 1 || ids[0] != "live" {
+		t.Errorf("expected Each to fall back to the primary, got %v, %v", ids, err)
+	}
+
+	unsupported := &ReplicatedBlogStore{Primary: basicStore{primary}, Replica: replica}
+	if _, err := unsupported.DeleteExpired(ctx, time.Now()); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported from DeleteExpired, got %v", err)
+	}
+	if err := unsupported.Restore(ctx, snapshot); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported from Restore, got %v", err)
+	}
+}
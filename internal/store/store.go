@@ -1,9 +1,16 @@
 package store
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/moko-poi/blog-api-server/internal/domain"
 )
@@ -11,6 +18,17 @@ import (
 var (
 	// ErrNotFound is returned when a blog is not found
 	ErrNotFound = errors.New("blog not found")
+	// ErrAlreadyExists is returned when creating a blog whose ID is already taken
+	ErrAlreadyExists = errors.New("blog already exists")
+	// ErrSlugConflict is returned when a slug is already used by another blog
+	ErrSlugConflict = errors.New("slug already in use")
+	// ErrCapacityExceeded is returned when the store cannot hold any more blogs
+	ErrCapacityExceeded = errors.New("store capacity exceeded")
+	// ErrPreconditionFailed is returned by DeleteIf when the blog does not satisfy the condition
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrDuplicateContent is returned by Create when deduplication is enabled and
+	// a blog with the same content hash already exists
+	ErrDuplicateContent = errors.New("duplicate blog content")
 )
 
 // BlogStore defines the interface for blog storage operations
@@ -19,9 +37,71 @@ type BlogStore interface {
 	Create(ctx context.Context, blog *domain.Blog) error
 	GetByID(ctx context.Context, id string) (*domain.Blog, error)
 	GetAll(ctx context.Context) ([]*domain.Blog, error)
+	// GetByAuthor matches author against the display name only, never the author ID
 	GetByAuthor(ctx context.Context, author string) ([]*domain.Blog, error)
+	GetByCategory(ctx context.Context, category string) ([]*domain.Blog, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.Blog, error)
+	SetSlug(ctx context.Context, id, slug string) error
+	Recent(ctx context.Context, n int) ([]*domain.Blog, error)
 	Update(ctx context.Context, id string, blog *domain.Blog) error
 	Delete(ctx context.Context, id string) error
+	Stats(ctx context.Context) (domain.BlogStats, error)
+}
+
+// Snapshotter is implemented by stores that can export and replace their whole dataset
+// テストの初期データ投入やクラッシュからの復旧デモに使う
+type Snapshotter interface {
+	Snapshot(ctx context.Context) ([]byte, error)
+	Restore(ctx context.Context, data []byte) error
+}
+
+// Pinger is implemented by stores that can check their backing connection
+// 起動時のセルフチェック（Server.Preflight）で依存先の疎通を確認するために使う
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ConditionalDeleter is implemented by stores that can check a blog and delete it atomically
+// If-Matchによる条件付き削除で、確認から削除までの間に他の更新が割り込まないようにする
+type ConditionalDeleter interface {
+	DeleteIf(ctx context.Context, id string, cond func(blog *domain.Blog) bool) error
+}
+
+// Iterator is implemented by stores that can visit every blog without building a slice of all of them
+// 一覧のページ取得で、全件のコピーを作らずに必要な分だけを保持するために使う
+// fnがエラーを返すと走査を中断し、そのエラーを返す
+type Iterator interface {
+	Each(ctx context.Context, fn func(blog *domain.Blog) error) error
+}
+
+// each calls fn with every blog of bs, through Each if bs is an Iterator
+// ストアをラップする型が、内側のストアの対応状況にかかわらずIteratorを実装するために使う
+func each(ctx context.Context, bs BlogStore, fn func(blog *domain.Blog) error) error {
+	if it, ok := bs.(Iterator); ok {
+		return it.Each(ctx, fn)
+	}
+	blogs, err := bs.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, blog := range blogs {
+		if err := fn(blog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpirySweeper is implemented by stores that can delete expired blogs
+// 期限切れの投稿は読み取りからすぐ除外されるが、削除するまでは保存領域を占有し続ける
+type ExpirySweeper interface {
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// AuthorContentSizer is implemented by stores that can total the content size of an author's blogs
+// 作者ごとの本文サイズ上限（MAX_AUTHOR_CONTENT_BYTES）の確認で、投稿のコピーを作らずに集計するために使う
+type AuthorContentSizer interface {
+	AuthorContentBytes(ctx context.Context, author string) (int, error)
 }
 
 // MemoryBlogStore is an in-memory implementation of BlogStore
@@ -29,20 +109,98 @@ type BlogStore interface {
 type MemoryBlogStore struct {
 	mu    sync.RWMutex
 	blogs map[string]*domain.Blog
+	// capacityは保存できる最大件数（0は無制限）
+	capacity int
+	// uniqueSlugsが有効な場合、CreateとUpdateでもスラッグの重複をErrSlugConflictとする
+	uniqueSlugs bool
+	// deduplicateContentが有効な場合、CreateでContentHashの重複をErrDuplicateContentとする
+	deduplicateContent bool
 }
 
 // NewMemoryBlogStore creates a new in-memory blog store
 func NewMemoryBlogStore() *MemoryBlogStore {
+	return NewMemoryBlogStoreWithCapacity(0)
+}
+
+// NewMemoryBlogStoreWithCapacity creates an in-memory blog store holding at most n blogs
+// デモや容量上限のシミュレーション用。n が0以下の場合は無制限
+func NewMemoryBlogStoreWithCapacity(n int) *MemoryBlogStore {
 	return &MemoryBlogStore{
-		blogs: make(map[string]*domain.Blog),
+		blogs:    make(map[string]*domain.Blog),
+		capacity: max(n, 0),
+	}
+}
+
+// SetUniqueSlugs makes Create and Update reject a slug already used by another blog
+// ハンドラーでの重複確認から保存までの間に、同時に作成された投稿が同じスラッグを取ることを防ぐ
+// SetSlugは設定にかかわらず常に重複を拒否する
+func (s *MemoryBlogStore) SetUniqueSlugs(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uniqueSlugs = enabled
+}
+
+// SetDeduplicateContent makes Create reject a blog whose ContentHash matches an existing blog
+// 確認と保存を同じロックの中で行うため、同時に送られた同一の投稿（ダブルクリックなど）も一件だけになる
+func (s *MemoryBlogStore) SetDeduplicateContent(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deduplicateContent = enabled
+}
+
+// contentHashTaken reports whether a live blog has hash; callers must hold s.mu
+func (s *MemoryBlogStore) contentHashTaken(hash string) bool {
+	for blog := range s.live(time.Now()) {
+		if blog.ContentHash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// slugTaken reports whether a blog other than id uses slug; callers must hold s.mu
+func (s *MemoryBlogStore) slugTaken(slug, id string) bool {
+	for otherID, other := range s.blogs {
+		if otherID != id && other.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// live yields the blogs that have not expired at now; callers must hold s.mu
+func (s *MemoryBlogStore) live(now time.Time) iter.Seq[*domain.Blog] {
+	return func(yield func(*domain.Blog) bool) {
+		for _, blog := range s.blogs {
+			if blog.Expired(now) {
+				continue
+			}
+			if !yield(blog) {
+				return
+			}
+		}
 	}
 }
 
 // Create stores a new blog
+// 既存のIDを上書きしないよう、重複時はErrAlreadyExistsを返す
 func (s *MemoryBlogStore) Create(ctx context.Context, blog *domain.Blog) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, exists := s.blogs[blog.ID]; exists {
+		return ErrAlreadyExists
+	}
+	if s.capacity > 0 && len(s.blogs) >= s.capacity {
+		return ErrCapacityExceeded
+	}
+	if s.uniqueSlugs && blog.Slug != "" && s.slugTaken(blog.Slug, blog.ID) {
+		return ErrSlugConflict
+	}
+	if s.deduplicateContent && blog.ContentHash != "" && s.contentHashTaken(blog.ContentHash) {
+		return ErrDuplicateContent
+	}
+
 	s.blogs[blog.ID] = blog
 	return nil
 }
@@ -53,7 +211,7 @@ func (s *MemoryBlogStore) GetByID(ctx context.Context, id string) (*domain.Blog,
 	defer s.mu.RUnlock()
 
 	blog, exists := s.blogs[id]
-	if !exists {
+	if !exists || blog.Expired(time.Now()) {
 		return nil, ErrNotFound
 	}
 
@@ -68,7 +226,7 @@ func (s *MemoryBlogStore) GetAll(ctx context.Context) ([]*domain.Blog, error) {
 	defer s.mu.RUnlock()
 
 	blogs := make([]*domain.Blog, 0, len(s.blogs))
-	for _, blog := range s.blogs {
+	for blog := range s.live(time.Now()) {
 		// Return copies to prevent modification
 		blogCopy := *blog
 		blogs = append(blogs, &blogCopy)
@@ -77,14 +235,65 @@ func (s *MemoryBlogStore) GetAll(ctx context.Context) ([]*domain.Blog, error) {
 	return blogs, nil
 }
 
+// Each calls fn with a copy of every blog, in no particular order
+// ロックはポインタの一覧を取る間だけ保持し、fnの実行中（レスポンスの書き込みなど）は書き込みを妨げない
+// 保存済みのBlogはその場で書き換えず差し替えるため、取得時点のスナップショットを返すことになる
+func (s *MemoryBlogStore) Each(ctx context.Context, fn func(blog *domain.Blog) error) error {
+	s.mu.RLock()
+	blogs := slices.Collect(s.live(time.Now()))
+	s.mu.RUnlock()
+
+	for _, blog := range blogs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blogCopy := *blog
+		if err := fn(&blogCopy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetByAuthor retrieves all blogs by a specific author
 func (s *MemoryBlogStore) GetByAuthor(ctx context.Context, author string) ([]*domain.Blog, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var blogs []*domain.Blog
-	for _, blog := range s.blogs {
+	for blog := range s.live(time.Now()) {
+		if blog.Author == author {
+			// Return a copy to prevent modification
+			blogCopy := *blog
+			blogs = append(blogs, &blogCopy)
+		}
+	}
+
+	return blogs, nil
+}
+
+// AuthorContentBytes returns the total content length, in bytes, of the author's blogs
+func (s *MemoryBlogStore) AuthorContentBytes(ctx context.Context, author string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for blog := range s.live(time.Now()) {
 		if blog.Author == author {
+			total += len(blog.Content)
+		}
+	}
+	return total, nil
+}
+
+// GetByCategory retrieves all blogs in a specific category
+func (s *MemoryBlogStore) GetByCategory(ctx context.Context, category string) ([]*domain.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var blogs []*domain.Blog
+	for blog := range s.live(time.Now()) {
+		if blog.Category == category {
 			// Return a copy to prevent modification
 			blogCopy := *blog
 			blogs = append(blogs, &blogCopy)
@@ -94,14 +303,85 @@ func (s *MemoryBlogStore) GetByAuthor(ctx context.Context, author string) ([]*do
 	return blogs, nil
 }
 
+// GetBySlug retrieves a blog by its slug
+func (s *MemoryBlogStore) GetBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for blog := range s.live(time.Now()) {
+		if blog.Slug == slug {
+			// Return a copy to prevent modification
+			blogCopy := *blog
+			return &blogCopy, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// SetSlug changes the slug of an existing blog
+// Returns ErrSlugConflict if another blog already uses the slug
+func (s *MemoryBlogStore) SetSlug(ctx context.Context, id, slug string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blog, exists := s.blogs[id]
+	if !exists {
+		return ErrNotFound
+	}
+	if s.slugTaken(slug, id) {
+		return ErrSlugConflict
+	}
+
+	// 他のゴルーチンが保持しているコピーに影響しないよう差し替える
+	blogCopy := *blog
+	blogCopy.Slug = slug
+	s.blogs[id] = &blogCopy
+	return nil
+}
+
+// Recent retrieves the n most recently created blogs, newest first
+// 作成日時が同じ場合はIDで順序を固定する
+func (s *MemoryBlogStore) Recent(ctx context.Context, n int) ([]*domain.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blogs := make([]*domain.Blog, 0, len(s.blogs))
+	for blog := range s.live(time.Now()) {
+		blogs = append(blogs, blog)
+	}
+	slices.SortFunc(blogs, func(a, b *domain.Blog) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if n < len(blogs) {
+		blogs = blogs[:max(n, 0)]
+	}
+
+	// Return copies to prevent modification
+	recent := make([]*domain.Blog, len(blogs))
+	for i, blog := range blogs {
+		blogCopy := *blog
+		recent[i] = &blogCopy
+	}
+	return recent, nil
+}
+
 // Update updates an existing blog
 func (s *MemoryBlogStore) Update(ctx context.Context, id string, blog *domain.Blog) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.blogs[id]; !exists {
+	current, exists := s.blogs[id]
+	if !exists {
 		return ErrNotFound
 	}
+	// 既存データに重複がある場合でも他のフィールドは更新できるよう、スラッグが変わるときだけ確認する
+	if s.uniqueSlugs && blog.Slug != current.Slug && blog.Slug != "" && s.slugTaken(blog.Slug, id) {
+		return ErrSlugConflict
+	}
 
 	s.blogs[id] = blog
 	return nil
@@ -119,3 +399,157 @@ func (s *MemoryBlogStore) Delete(ctx context.Context, id string) error {
 	delete(s.blogs, id)
 	return nil
 }
+
+// DeleteIf removes a blog only if cond reports true for its current state
+// condにはコピーを渡し、判定と削除の間は書き込みロックを保持する
+func (s *MemoryBlogStore) DeleteIf(ctx context.Context, id string, cond func(blog *domain.Blog) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blog, exists := s.blogs[id]
+	if !exists {
+		return ErrNotFound
+	}
+	blogCopy := *blog
+	if !cond(&blogCopy) {
+		return ErrPreconditionFailed
+	}
+
+	delete(s.blogs, id)
+	return nil
+}
+
+// DeleteExpired removes every blog that has expired at now and reports how many were removed
+func (s *MemoryBlogStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	return len(s.deleteExpired(now)), nil
+}
+
+// deleteExpired removes every blog that has expired at now and returns their IDs
+func (s *MemoryBlogStore) deleteExpired(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []string
+	for id, blog := range s.blogs {
+		if blog.Expired(now) {
+			delete(s.blogs, id)
+			removed = append(removed, id)
+		}
+	}
+	return removed
+}
+
+// Stats computes aggregate statistics over all blogs in a single pass
+func (s *MemoryBlogStore) Stats(ctx context.Context) (domain.BlogStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return computeStats(s.live(time.Now())), nil
+}
+
+// computeStats aggregates blogs into BlogStats
+// ストア実装間で集計ロジックを共有するため関数として切り出している
+func computeStats(blogs iter.Seq[*domain.Blog]) domain.BlogStats {
+	stats := domain.BlogStats{
+		BlogsPerAuthor: make(map[string]int),
+	}
+
+	var totalContentLength int
+	for blog := range blogs {
+		stats.TotalBlogs++
+		stats.BlogsPerAuthor[blog.Author]++
+		totalContentLength += len(blog.Content)
+
+		if stats.NewestPostAt == nil || blog.CreatedAt.After(*stats.NewestPostAt) {
+			createdAt := blog.CreatedAt
+			stats.NewestPostAt = &createdAt
+		}
+		if stats.OldestPostAt == nil || blog.CreatedAt.Before(*stats.OldestPostAt) {
+			createdAt := blog.CreatedAt
+			stats.OldestPostAt = &createdAt
+		}
+	}
+
+	if stats.TotalBlogs > 0 {
+		stats.AverageContentLength = float64(totalContentLength) / float64(stats.TotalBlogs)
+	}
+
+	return stats
+}
+
+// Ping always succeeds unless ctx is done, as there is no connection to check
+func (s *MemoryBlogStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// storedBlog is the JSON form of a blog persisted by a store
+// ContentHashと更新履歴はAPIのレスポンスには出さないフィールドなので、ここで明示的に保存する
+// （ContentHashは暗号化ストアでは本文から再計算できない）
+type storedBlog struct {
+	*domain.Blog
+	ContentHash string                `json:"content_hash,omitempty"`
+	Revisions   []domain.BlogRevision `json:"revisions,omitempty"`
+}
+
+// newStoredBlog returns the persisted form of blog
+func newStoredBlog(blog *domain.Blog) storedBlog {
+	return storedBlog{Blog: blog, ContentHash: blog.ContentHash, Revisions: blog.Revisions}
+}
+
+// blog returns the blog read from its persisted form
+func (b storedBlog) blog() *domain.Blog {
+	if b.Blog != nil {
+		b.Blog.ContentHash = b.ContentHash
+		b.Blog.Revisions = b.Revisions
+	}
+	return b.Blog
+}
+
+// Snapshot serializes every blog as a JSON array ordered by ID
+func (s *MemoryBlogStore) Snapshot(ctx context.Context) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blogs := slices.SortedFunc(maps.Values(s.blogs), func(a, b *domain.Blog) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	stored := make([]storedBlog, len(blogs))
+	for i, blog := range blogs {
+		stored[i] = newStoredBlog(blog)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("marshal snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// Restore replaces the whole dataset with the blogs in a Snapshot
+// 検証に失敗した場合は既存のデータを変更しない
+func (s *MemoryBlogStore) Restore(ctx context.Context, data []byte) error {
+	var stored []storedBlog
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("unmarshal snapshot: %w", err)
+	}
+
+	restored := make(map[string]*domain.Blog, len(stored))
+	for _, entry := range stored {
+		blog := entry.blog()
+		if blog == nil || blog.ID == "" {
+			return errors.New("snapshot contains a blog without an ID")
+		}
+		if _, exists := restored[blog.ID]; exists {
+			return fmt.Errorf("snapshot contains duplicate ID %q: %w", blog.ID, ErrAlreadyExists)
+		}
+		restored[blog.ID] = blog
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.capacity > 0 && len(restored) > s.capacity {
+		return ErrCapacityExceeded
+	}
+	s.blogs = restored
+	return nil
+}
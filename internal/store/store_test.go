@@ -3,6 +3,9 @@ package store
 import (
 	"context"
 	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -41,6 +44,67 @@ func TestMemoryBlogStore_Create(t *testing.T) {
 	}
 }
 
+func TestMemoryBlogStore_Create_DuplicateID(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	first := &domain.Blog{
+		ID:        "test-id",
+		Title:     "First Title",
+		Content:   "First Content",
+		Author:    "Test Author",
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+	second := &domain.Blog{
+		ID:        "test-id",
+		Title:     "Second Title",
+		Content:   "Second Content",
+		Author:    "Other Author",
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if err := store.Create(ctx, first); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err := store.Create(ctx, second)
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	// The original blog must not be clobbered
+	stored, _ := store.GetByID(ctx, "test-id")
+	if stored.Title != "First Title" {
+		t.Errorf("expected original title to be kept, got %q", stored.Title)
+	}
+}
+
+func TestMemoryBlogStore_Create_Capacity(t *testing.T) {
+	store := NewMemoryBlogStoreWithCapacity(2)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b"} {
+		if err := store.Create(ctx, &domain.Blog{ID: id}); err != nil {
+			t.Fatalf("expected no error filling to capacity, got %v", err)
+		}
+	}
+
+	err := store.Create(ctx, &domain.Blog{ID: "c"})
+	if !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("expected ErrCapacityExceeded, got %v", err)
+	}
+
+	// 削除して空きができれば再び作成できる
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := store.Create(ctx, &domain.Blog{ID: "c"}); err != nil {
+		t.Errorf("expected create to succeed after delete, got %v", err)
+	}
+}
+
 func TestMemoryBlogStore_GetByID(t *testing.T) {
 	store := NewMemoryBlogStore()
 	ctx := context.Background()
@@ -145,6 +209,62 @@ func TestMemoryBlogStore_GetAll(t *testing.T) {
 	}
 }
 
+func TestMemoryBlogStore_Each(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		store.Create(ctx, &domain.Blog{ID: id, Title: "Title " + id, CreatedAt: time.Now()})
+	}
+
+	seen := make(map[string]bool)
+	err := store.Each(ctx, func(blog *domain.Blog) error {
+		seen[blog.ID] = true
+		blog.Title = "Modified"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(seen) != 3 {
+		t.Errorf("expected to visit 3 blogs, got %v", seen)
+	}
+	if got, _ := store.GetByID(ctx, "1"); got.Title != "Title 1" {
+		t.Errorf("expected Each to pass copies, got stored title %q", got.Title)
+	}
+
+	// fnがエラーを返すと走査を中断する
+	errStop := errors.New("stop")
+	visited := 0
+	err = store.Each(ctx, func(blog *domain.Blog) error {
+		visited++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || visited != 1 {
+		t.Errorf("expected to stop after the first error, got %v after %d blogs", err, visited)
+	}
+}
+
+func TestMemoryBlogStore_DeleteIf(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+	store.Create(ctx, &domain.Blog{ID: "1", Title: "Title", CreatedAt: time.Now()})
+
+	err := store.DeleteIf(ctx, "1", func(blog *domain.Blog) bool { return blog.Title == "Other" })
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if _, err := store.GetByID(ctx, "1"); err != nil {
+		t.Errorf("expected blog to remain after failed condition, got %v", err)
+	}
+
+	if err := store.DeleteIf(ctx, "1", func(blog *domain.Blog) bool { return blog.Title == "Title" }); err != nil {
+		t.Errorf("expected delete to succeed, got %v", err)
+	}
+	if err := store.DeleteIf(ctx, "1", func(*domain.Blog) bool { return true }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestMemoryBlogStore_GetByAuthor(t *testing.T) {
 	store := NewMemoryBlogStore()
 	ctx := context.Background()
@@ -221,6 +341,165 @@ func TestMemoryBlogStore_GetByAuthor(t *testing.T) {
 	}
 }
 
+func TestMemoryBlogStore_GetByAuthor_AuthorID(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	// 改名の前後で表示名が異なるが、作者IDは同じ
+	store.Create(ctx, &domain.Blog{ID: "id1", Title: "Before", Author: "Alice", AuthorID: "user-1", CreatedAt: time.Now().UTC()})
+	store.Create(ctx, &domain.Blog{ID: "id2", Title: "After", Author: "Alice Smith", AuthorID: "user-1", CreatedAt: time.Now().UTC()})
+	store.Create(ctx, &domain.Blog{ID: "id3", Title: "Other", Author: "Alice", AuthorID: "user-2", CreatedAt: time.Now().UTC()})
+
+	tests := []struct {
+		author  string
+		wantIDs []string
+	}{
+		// 作者IDは表示名とは別の名前空間で、GetByAuthorでは一致しない
+		{author: "user-1"},
+		{author: "Alice", wantIDs: []string{"id1", "id3"}},
+		{author: "Alice Smith", wantIDs: []string{"id2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.author, func(t *testing.T) {
+			blogs, err := store.GetByAuthor(ctx, tt.author)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			var ids []string
+			for _, blog := range blogs {
+				ids = append(ids, blog.ID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("expected %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestMemoryBlogStore_GetByCategory(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	store.Create(ctx, &domain.Blog{ID: "id1", Title: "Title 1", Author: "A", Category: "tech"})
+	store.Create(ctx, &domain.Blog{ID: "id2", Title: "Title 2", Author: "A", Category: "life"})
+	store.Create(ctx, &domain.Blog{ID: "id3", Title: "Title 3", Author: "B", Category: "tech"})
+	store.Create(ctx, &domain.Blog{ID: "id4", Title: "Title 4", Author: "B"})
+
+	blogs, err := store.GetByCategory(ctx, "tech")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %d", len(blogs))
+	}
+	for _, blog := range blogs {
+		if blog.Category != "tech" {
+			t.Errorf("expected category 'tech', got %q", blog.Category)
+		}
+	}
+
+	blogs, err = store.GetByCategory(ctx, "unknown")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(blogs) != 0 {
+		t.Errorf("expected 0 blogs, got %d", len(blogs))
+	}
+}
+
+func TestMemoryBlogStore_SetSlug(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	store.Create(ctx, &domain.Blog{ID: "id1", Title: "First", Slug: "first"})
+	store.Create(ctx, &domain.Blog{ID: "id2", Title: "Second", Slug: "second"})
+
+	if err := store.SetSlug(ctx, "id1", "renamed"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	blog, err := store.GetBySlug(ctx, "renamed")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if blog.ID != "id1" {
+		t.Errorf("expected id1, got %q", blog.ID)
+	}
+	if _, err := store.GetBySlug(ctx, "first"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for old slug, got %v", err)
+	}
+
+	// Setting a blog's own slug again is not a conflict
+	if err := store.SetSlug(ctx, "id2", "second"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := store.SetSlug(ctx, "id2", "renamed"); !errors.Is(err, ErrSlugConflict) {
+		t.Errorf("expected ErrSlugConflict, got %v", err)
+	}
+	if err := store.SetSlug(ctx, "missing", "anything"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemoryBlogStore_UniqueSlugs(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	// 無効時は従来どおり重複したスラッグも保存する
+	store.Create(ctx, &domain.Blog{ID: "id1", Slug: "first"})
+	if err := store.Create(ctx, &domain.Blog{ID: "id2", Slug: "first"}); err != nil {
+		t.Fatalf("expected duplicate slug to be accepted when disabled, got %v", err)
+	}
+
+	store.SetUniqueSlugs(true)
+	if err := store.Create(ctx, &domain.Blog{ID: "id3", Slug: "first"}); !errors.Is(err, ErrSlugConflict) {
+		t.Errorf("expected ErrSlugConflict on create, got %v", err)
+	}
+	if _, err := store.GetByID(ctx, "id3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected conflicting blog not to be stored, got %v", err)
+	}
+	if err := store.Create(ctx, &domain.Blog{ID: "id3", Slug: "third"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := store.Update(ctx, "id3", &domain.Blog{ID: "id3", Slug: "first"}); !errors.Is(err, ErrSlugConflict) {
+		t.Errorf("expected ErrSlugConflict on update, got %v", err)
+	}
+	// 既存の重複はスラッグを変えない更新を妨げない
+	if err := store.Update(ctx, "id2", &domain.Blog{ID: "id2", Title: "Updated", Slug: "first"}); err != nil {
+		t.Errorf("expected update keeping the slug to succeed, got %v", err)
+	}
+}
+
+func TestMemoryBlogStore_Recent(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Create(ctx, &domain.Blog{ID: "old", CreatedAt: base})
+	store.Create(ctx, &domain.Blog{ID: "newest", CreatedAt: base.Add(2 * time.Hour)})
+	store.Create(ctx, &domain.Blog{ID: "middle", CreatedAt: base.Add(time.Hour)})
+
+	blogs, err := store.Recent(ctx, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %d", len(blogs))
+	}
+	if blogs[0].ID != "newest" || blogs[1].ID != "middle" {
+		t.Errorf("expected [newest middle], got [%s %s]", blogs[0].ID, blogs[1].ID)
+	}
+
+	blogs, err = store.Recent(ctx, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(blogs) != 3 {
+		t.Errorf("expected all 3 blogs when n exceeds total, got %d", len(blogs))
+	}
+}
+
 func TestMemoryBlogStore_Update(t *testing.T) {
 	store := NewMemoryBlogStore()
 	ctx := context.Background()
@@ -317,6 +596,137 @@ func TestMemoryBlogStore_Delete(t *testing.T) {
 	}
 }
 
+func TestMemoryBlogStore_Stats(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+
+	// Test empty store
+	stats, err := store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats.TotalBlogs != 0 {
+		t.Errorf("expected 0 blogs, got %d", stats.TotalBlogs)
+	}
+	if stats.AverageContentLength != 0 {
+		t.Errorf("expected average content length 0, got %v", stats.AverageContentLength)
+	}
+	if stats.NewestPostAt != nil || stats.OldestPostAt != nil {
+		t.Error("expected no newest/oldest timestamps for empty store")
+	}
+
+	// Known dataset
+	oldest := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	middle := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	newest := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	store.Create(ctx, &domain.Blog{ID: "id1", Title: "Title 1", Content: "12345", Author: "Author A", CreatedAt: middle, UpdatedAt: middle})
+	store.Create(ctx, &domain.Blog{ID: "id2", Title: "Title 2", Content: "1234567890", Author: "Author B", CreatedAt: oldest, UpdatedAt: oldest})
+	store.Create(ctx, &domain.Blog{ID: "id3", Title: "Title 3", Content: "123456789012345", Author: "Author A", CreatedAt: newest, UpdatedAt: newest})
+
+	stats, err = store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats.TotalBlogs != 3 {
+		t.Errorf("expected 3 blogs, got %d", stats.TotalBlogs)
+	}
+	if stats.BlogsPerAuthor["Author A"] != 2 {
+		t.Errorf("expected 2 blogs by Author A, got %d", stats.BlogsPerAuthor["Author A"])
+	}
+	if stats.BlogsPerAuthor["Author B"] != 1 {
+		t.Errorf("expected 1 blog by Author B, got %d", stats.BlogsPerAuthor["Author B"])
+	}
+	if stats.AverageContentLength != 10 {
+		t.Errorf("expected average content length 10, got %v", stats.AverageContentLength)
+	}
+	if stats.NewestPostAt == nil || !stats.NewestPostAt.Equal(newest) {
+		t.Errorf("expected newest post at %v, got %v", newest, stats.NewestPostAt)
+	}
+	if stats.OldestPostAt == nil || !stats.OldestPostAt.Equal(oldest) {
+		t.Errorf("expected oldest post at %v, got %v", oldest, stats.OldestPostAt)
+	}
+}
+
+func TestMemoryBlogStore_DeduplicateContent(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+	newBlog := func(content string) *domain.Blog {
+		return domain.NewBlog(domain.CreateBlogRequest{Title: "Test Title", Content: content, Author: "Test Author"})
+	}
+
+	// 無効な場合は同じ本文でも作成できる
+	if err := store.Create(ctx, newBlog("Test Content")); err != nil {
+		t.Fatalf("expected create to succeed, got %v", err)
+	}
+	if err := store.Create(ctx, newBlog("Test Content")); err != nil {
+		t.Fatalf("expected a duplicate to be accepted while disabled, got %v", err)
+	}
+
+	store.SetDeduplicateContent(true)
+	if err := store.Create(ctx, newBlog("Test Content")); !errors.Is(err, ErrDuplicateContent) {
+		t.Errorf("expected ErrDuplicateContent, got %v", err)
+	}
+	if err := store.Create(ctx, newBlog("Other Content")); err != nil {
+		t.Errorf("expected different content to be accepted, got %v", err)
+	}
+
+	// 同時に送られた同一の投稿は一件だけが保存される
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if store.Create(ctx, newBlog("Concurrent Content")) == nil {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if created.Load() != 1 {
+		t.Errorf("expected exactly one concurrent create to succeed, got %d", created.Load())
+	}
+}
+
+func TestMemoryBlogStore_Expiry(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	store.Create(ctx, &domain.Blog{ID: "expired", Title: "Expired", Author: "Author", CreatedAt: now, ExpiresAt: &past})
+	store.Create(ctx, &domain.Blog{ID: "live", Title: "Live", Author: "Author", CreatedAt: now, ExpiresAt: &future})
+	store.Create(ctx, &domain.Blog{ID: "forever", Title: "Forever", Author: "Author", CreatedAt: now})
+
+	if _, err := store.GetByID(ctx, "expired"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an expired blog, got %v", err)
+	}
+	blogs, _ := store.GetAll(ctx)
+	if len(blogs) != 2 {
+		t.Errorf("expected 2 unexpired blogs from GetAll, got %d", len(blogs))
+	}
+	for _, blog := range blogs {
+		if blog.ID == "expired" {
+			t.Error("expected GetAll to exclude the expired blog")
+		}
+	}
+	if stats, _ := store.Stats(ctx); stats.TotalBlogs != 2 {
+		t.Errorf("expected stats to exclude the expired blog, got %d", stats.TotalBlogs)
+	}
+
+	removed, err := store.DeleteExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("failed to delete expired blogs: %v", err)
+	}
+	if removed != 1 || len(store.blogs) != 2 {
+		t.Errorf("expected only the expired blog removed, got %d removed, %d left", removed, len(store.blogs))
+	}
+	if _, exists := store.blogs["expired"]; exists {
+		t.Error("expected the expired blog to be deleted from the store")
+	}
+}
+
 func TestMemoryBlogStore_ConcurrentAccess(t *testing.T) {
 	store := NewMemoryBlogStore()
 	ctx := context.Background()
@@ -370,4 +780,79 @@ func TestMemoryBlogStore_ConcurrentAccess(t *testing.T) {
 func TestMemoryBlogStore_Interface(t *testing.T) {
 	// Verify MemoryBlogStore implements BlogStore interface
 	var _ BlogStore = (*MemoryBlogStore)(nil)
-}
\ No newline at end of file
+}
+
+func TestMemoryBlogStore_SnapshotRestore(t *testing.T) {
+	store := NewMemoryBlogStore()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	store.Create(ctx, &domain.Blog{ID: "b", Title: "Second", Author: "Bob", CreatedAt: now, UpdatedAt: now})
+	store.Create(ctx, &domain.Blog{ID: "a", Title: "First", Author: "Alice", ContentHash: "hash-a", CreatedAt: now, UpdatedAt: now,
+		Revisions: []domain.BlogRevision{{ChangedFields: []string{"title"}, ChangedAt: now}}})
+
+	snapshot, err := store.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("failed to snapshot: %v", err)
+	}
+
+	// スナップショット後にデータを変更する
+	store.Delete(ctx, "a")
+	store.Update(ctx, "b", &domain.Blog{ID: "b", Title: "Changed", Author: "Bob"})
+	store.Create(ctx, &domain.Blog{ID: "c", Title: "Third", Author: "Carol"})
+
+	if err := store.Restore(ctx, snapshot); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+
+	blogs, _ := store.GetAll(ctx)
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs after restore, got %d", len(blogs))
+	}
+	a, err := store.GetByID(ctx, "a")
+	if err != nil || a.Title != "First" || !a.CreatedAt.Equal(now) {
+		t.Errorf("expected blog a to be restored, got %+v (err %v)", a, err)
+	}
+	if a.ContentHash != "hash-a" || len(a.Revisions) != 1 {
+		t.Errorf("expected the content hash and revisions to be restored, got %q, %+v", a.ContentHash, a.Revisions)
+	}
+	b, _ := store.GetByID(ctx, "b")
+	if b.Title != "Second" {
+		t.Errorf("expected blog b to be restored to 'Second', got %q", b.Title)
+	}
+	if _, err := store.GetByID(ctx, "c"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected blog c to be gone, got %v", err)
+	}
+
+	// 同じ状態からのスナップショットは同一になる
+	again, _ := store.Snapshot(ctx)
+	if string(again) != string(snapshot) {
+		t.Errorf("expected identical snapshot after restore")
+	}
+}
+
+func TestMemoryBlogStore_RestoreInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "malformed JSON", data: `[{"id":`},
+		{name: "missing ID", data: `[{"title":"No ID"}]`},
+		{name: "duplicate ID", data: `[{"id":"a"},{"id":"a"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryBlogStore()
+			ctx := context.Background()
+			store.Create(ctx, &domain.Blog{ID: "keep"})
+
+			if err := store.Restore(ctx, []byte(tt.data)); err == nil {
+				t.Fatal("expected restore to fail")
+			}
+			if _, err := store.GetByID(ctx, "keep"); err != nil {
+				t.Errorf("expected existing data to be kept, got %v", err)
+			}
+		})
+	}
+}